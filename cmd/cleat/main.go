@@ -7,13 +7,21 @@ import (
 	"github.com/madewithfuture/cleat/internal/cmd"
 	"github.com/madewithfuture/cleat/internal/config"
 	"github.com/madewithfuture/cleat/internal/logger"
+	"github.com/madewithfuture/cleat/internal/settings"
+	"github.com/madewithfuture/cleat/internal/ui/theme"
 )
 
 func main() {
+	// Load the user's settings first, since the logger and theme read them
+	if err := settings.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring settings: %v\n", err)
+	}
+	theme.Apply(settings.Current().Theme)
+
 	// Initialize logger early to catch issues during startup.
 	// We include the project ID so logs from different projects in the same global file can be filtered.
 	projectID := config.GetProjectID()
-	if err := logger.Init("~/.cleat/cleat.log", "debug", map[string]interface{}{"project": projectID}); err != nil {
+	if err := logger.InitFromSettings(map[string]interface{}{"project": projectID}); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to initialize logger: %v\n", err)
 	}
 
@@ -23,12 +23,15 @@ var buildCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		s := strategy.GetStrategyForCommand("build", sess)
 		if s == nil {
 			return fmt.Errorf("no strategy found for build")
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("build failed: %w", err)
 		}
 		return nil
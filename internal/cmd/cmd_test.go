@@ -156,15 +156,39 @@ services:
 func TestCreateSessionAndMerge(t *testing.T) {
 	cfg := &config.Config{}
 	preCollectedInputs = map[string]string{"foo": "bar"}
-	sess := createSessionAndMerge(cfg)
+	sess, _ := createSessionAndMerge(cfg)
 	if sess.Inputs["foo"] != "bar" {
 		t.Errorf("expected input foo=bar, got %v", sess.Inputs["foo"])
 	}
 
 	// Test with nil preCollectedInputs
 	preCollectedInputs = nil
-	sess = createSessionAndMerge(cfg)
+	sess, _ = createSessionAndMerge(cfg)
 	if sess == nil {
 		t.Fatal("expected non-nil session")
 	}
 }
+
+func TestCreateSessionAndMerge_ServiceFilter(t *testing.T) {
+	defer func() { ServiceNames, ExcludeServices = nil, nil }()
+	cfg := &config.Config{Services: []config.ServiceConfig{{Name: "web"}, {Name: "api"}, {Name: "worker"}}}
+
+	ServiceNames = []string{"web", "api"}
+	ExcludeServices = []string{"api"}
+	sess, err := createSessionAndMerge(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sess.Config.Services) != 1 || sess.Config.Services[0].Name != "web" {
+		t.Errorf("expected only web to remain, got %v", sess.Config.Services)
+	}
+	if len(sess.Services) != 1 || sess.Services[0] != "web" {
+		t.Errorf("expected session services [web], got %v", sess.Services)
+	}
+
+	ServiceNames = []string{"nope"}
+	ExcludeServices = nil
+	if _, err := createSessionAndMerge(cfg); err == nil {
+		t.Error("expected error for unknown service")
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/strategy"
+	"github.com/madewithfuture/cleat/internal/task"
+	"github.com/spf13/cobra"
+)
+
+var commandCmd = &cobra.Command{
+	Use:   "command [name | service:name]",
+	Short: "Run a command defined in cleat.yaml",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var cfg *config.Config
+		var err error
+		if ConfigPath != "" {
+			cfg, err = config.LoadConfig(ConfigPath)
+		} else {
+			cfg, err = config.LoadDefaultConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
+		s := strategy.GetStrategyForCommand(task.CommandPrefix+args[0], sess)
+		if s == nil {
+			return fmt.Errorf("unknown command: %s", args[0])
+		}
+		if err := executeStrategy(s, sess); err != nil {
+			return fmt.Errorf("command %s failed: %w", args[0], err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(commandCmd)
+}
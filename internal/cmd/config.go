@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/config/schema"
+	"github.com/madewithfuture/cleat/internal/executor"
+	"github.com/madewithfuture/cleat/internal/history"
+	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/strategy"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check and inspect the configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check cleat.yaml for mistakes",
+	Long: `Check cleat.yaml, and the files it includes or extends, for mistakes.
+
+Reports unknown fields, duplicate services, unknown package managers, dirs
+that do not exist, django_service names missing from docker-compose and
+workflow steps that cannot run, each with its file:line:column. Exits with
+status 1 when anything is found, so it can run in CI. Deprecated fields are
+printed as warnings and do not fail the check.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := ConfigPath
+		if path == "" {
+			path = defaultConfigPath()
+		}
+
+		cfg, problems := config.Validate(path)
+		if cfg != nil {
+			problems = append(problems, workflowProblems(cfg)...)
+		}
+
+		out := cmd.OutOrStdout()
+		found := 0
+		for _, p := range flattenErrors(problems) {
+			var problem *config.Problem
+			if errors.As(p, &problem) {
+				problem.File = displayPath(problem.File)
+			}
+			if problem == nil || !problem.Warning {
+				found++
+			}
+			fmt.Fprintln(out, p)
+		}
+		if found > 0 {
+			return fmt.Errorf("%d problem(s) found in %s", found, displayPath(path))
+		}
+		fmt.Fprintf(out, "✓ %s is valid\n", displayPath(path))
+		return nil
+	},
+}
+
+// Flags of config schema
+var (
+	configSchemaWorkflows bool
+	configSchemaOutput    string
+)
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of cleat.yaml",
+	Long: `Print the JSON Schema of cleat.yaml, or of cleat.workflows.yaml with --workflows.
+
+Editors with a YAML language server use it to complete and check the file.
+Each release publishes both schemas, so a file can point at them:
+
+  # yaml-language-server: $schema=` + schema.SchemaURL,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		generate := schema.ConfigJSONSchema
+		if configSchemaWorkflows {
+			generate = schema.WorkflowsJSONSchema
+		}
+		data, err := generate()
+		if err != nil {
+			return fmt.Errorf("failed to generate schema: %w", err)
+		}
+		if configSchemaOutput == "" {
+			_, err := cmd.OutOrStdout().Write(data)
+			return err
+		}
+		if err := os.WriteFile(configSchemaOutput, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", configSchemaOutput, err)
+		}
+		return nil
+	},
+}
+
+// Flags of config show
+var (
+	configShowEffective bool
+	configShowFormat    string
+)
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the configuration and where each value came from",
+	Long: `Print the configuration declared in cleat.yaml and the files it includes or
+extends, with the file and line of each value.
+
+With --effective, the configuration is printed as commands see it, after
+auto-detection, and each value says whether it was declared in a file,
+detected by a detector from the file that triggered it, or defaulted.
+--format json lists every field with its origin for tooling.`,
+	Example: `  cleat config show --effective
+  cleat config show --effective --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configShowFormat != "yaml" && configShowFormat != "json" {
+			return fmt.Errorf("invalid format '%s', must be one of: yaml, json", configShowFormat)
+		}
+		path := ConfigPath
+		if path == "" {
+			path = defaultConfigPath()
+		}
+		cfg, fields, err := config.LoadWithOrigins(path, configShowEffective)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		out := cmd.OutOrStdout()
+		if configShowFormat == "json" {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(struct {
+				File   string         `json:"file"`
+				Fields []config.Field `json:"fields"`
+			}{cfg.SourcePath, fields})
+		}
+		data, err := config.RenderOrigins(cfg, fields)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	},
+}
+
+// workflowProblems checks every workflow visible to cfg, locating problems
+// at the step that has them
+func workflowProblems(cfg *config.Config) []error {
+	workflows, err := history.LoadWorkflowSources(cfg)
+	if err != nil {
+		return []error{err}
+	}
+	all := *cfg
+	all.Workflows = nil
+	for _, wf := range workflows {
+		all.Workflows = append(all.Workflows, wf.Workflow)
+	}
+
+	var problems []error
+	for _, wf := range workflows {
+		file := wf.SourcePath
+		if file == "" {
+			file = wf.Path
+		}
+		for _, name := range []string{wf.ID, wf.Name} {
+			if history.IsReservedWorkflowName(name) {
+				problems = append(problems, &config.Problem{File: file, Message: fmt.Sprintf("workflow '%s' cannot be run: '%s' is reserved for the 'cleat workflow %s' command", wf.ID, name, name)})
+				break
+			}
+		}
+		sess := session.NewSession(&all, executor.Default)
+		sess.NoInput = true
+		for _, err := range strategy.NewWorkflowStrategyFromConfig(wf.Workflow).Validate(sess) {
+			p := &config.Problem{File: file, Message: fmt.Sprintf("workflow '%s' %v", wf.ID, err)}
+			var stepErr *strategy.StepError
+			if errors.As(err, &stepErr) {
+				p.Line, p.Column = stepErr.Step.Line, stepErr.Step.Column
+			}
+			problems = append(problems, p)
+		}
+	}
+	return problems
+}
+
+// flattenErrors expands errors joined with errors.Join
+func flattenErrors(errs []error) []error {
+	var flat []error
+	for _, err := range errs {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			flat = append(flat, flattenErrors(joined.Unwrap())...)
+			continue
+		}
+		flat = append(flat, err)
+	}
+	return flat
+}
+
+// displayPath shortens path to be relative to the working directory when it is inside it
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func init() {
+	configSchemaCmd.Flags().BoolVar(&configSchemaWorkflows, "workflows", false, "print the schema of cleat.workflows.yaml instead")
+	configSchemaCmd.Flags().StringVarP(&configSchemaOutput, "output", "o", "", "file to write instead of standard output")
+	configShowCmd.Flags().BoolVar(&configShowEffective, "effective", false, "include what auto-detection adds")
+	configShowCmd.Flags().StringVarP(&configShowFormat, "format", "f", "yaml", "output format: yaml, json")
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/history"
+)
+
+func TestConfigValidateCmd(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	oldUserHomeDir := history.UserHomeDir
+	history.UserHomeDir = func() (string, error) { return tmpDir, nil }
+	defer func() { history.UserHomeDir = oldUserHomeDir }()
+
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	defer rootCmd.SetOut(nil)
+	execute := func() error {
+		out.Reset()
+		rootCmd.SetArgs([]string{"config", "validate"})
+		return rootCmd.Execute()
+	}
+
+	os.WriteFile("cleat.yaml", []byte(`terraform:
+  use_folders: true
+commands:
+  - name: hello
+    run: echo hi
+workflows:
+  - id: ship
+    name: Ship
+    commands:
+      - command:hello
+`), 0644)
+	if err := execute(); err != nil {
+		t.Fatalf("expected a valid config, got %v:\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "cleat.yaml:2:3: warning: 'use_folders' in terraform is deprecated") || !strings.Contains(out.String(), "✓ cleat.yaml is valid") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	os.WriteFile("cleat.yaml", []byte(`services:
+  - name: api
+    dir: api
+    dockerfle: Dockerfile
+workflows:
+  - id: ship
+    name: Ship
+    commands:
+      - command:hello
+      - command:missing
+  - id: list
+    name: List
+    commands:
+      - echo list
+`), 0644)
+	err := execute()
+	if err == nil || err.Error() != "5 problem(s) found in cleat.yaml" {
+		t.Fatalf("expected 5 problems, got %v:\n%s", err, out.String())
+	}
+	expected := []string{
+		"cleat.yaml:3:10: dir 'api' of service 'api' does not exist",
+		"cleat.yaml:4:5: unknown field 'dockerfle' in services[0] (did you mean 'dockerfile'?)",
+		"cleat.yaml:9:9: workflow 'ship' command step 1 'command:hello': unknown command: command:hello",
+		"cleat.yaml:10:9: workflow 'ship' command step 2 'command:missing': unknown command: command:missing",
+		"cleat.yaml: workflow 'list' cannot be run: 'list' is reserved for the 'cleat workflow list' command",
+	}
+	for _, line := range expected {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected %q in output:\n%s", line, out.String())
+		}
+	}
+	if strings.Contains(out.String(), filepath.Join(tmpDir, "cleat.yaml")) {
+		t.Errorf("expected paths relative to the working directory:\n%s", out.String())
+	}
+}
+
+func TestConfigShowCmd(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	os.WriteFile("cleat.yaml", []byte(`services:
+  - name: web
+    dir: web
+`), 0644)
+	os.MkdirAll("web", 0755)
+	os.WriteFile(filepath.Join("web", "package.json"), []byte(`{"scripts": {"dev": "vite"}}`), 0644)
+
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	defer rootCmd.SetOut(nil)
+	execute := func(args ...string) error {
+		out.Reset()
+		configShowEffective, configShowFormat = false, "yaml"
+		rootCmd.SetArgs(append([]string{"config", "show"}, args...))
+		return rootCmd.Execute()
+	}
+	defer func() { configShowEffective, configShowFormat = false, "yaml" }()
+
+	if err := execute(); err != nil {
+		t.Fatalf("config show failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "- name: web # declared in cleat.yaml:2") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "npm") {
+		t.Errorf("expected nothing detected without --effective:\n%s", out.String())
+	}
+
+	if err := execute("--effective"); err != nil {
+		t.Fatalf("config show --effective failed: %v", err)
+	}
+	want := "- dev # detected by npm from " + filepath.Join("web", "package.json")
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("expected %q in output:\n%s", want, out.String())
+	}
+
+	if err := execute("--effective", "--format", "json"); err != nil {
+		t.Fatalf("config show --format json failed: %v", err)
+	}
+	var shown struct {
+		File   string         `json:"file"`
+		Fields []config.Field `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &shown); err != nil {
+		t.Fatalf("expected JSON, got %v:\n%s", err, out.String())
+	}
+	found := false
+	for _, f := range shown.Fields {
+		if f.Path == "services[0].modules[0].npm.scripts[0]" {
+			found = f.Value == "dev" && f.Source == config.SourceDetected && f.Detector == "npm"
+		}
+	}
+	if !found {
+		t.Errorf("expected the npm script as a detected field, got %+v", shown.Fields)
+	}
+
+	if err := execute("--format", "toml"); err == nil || !strings.Contains(err.Error(), "invalid format 'toml'") {
+		t.Errorf("expected an error for an unknown format, got %v", err)
+	}
+}
@@ -22,7 +22,10 @@ var djangoCreateUserDevCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var s strategy.Strategy
 		if len(args) > 0 {
 			var targetSvc *config.ServiceConfig
@@ -39,7 +42,7 @@ var djangoCreateUserDevCmd = &cobra.Command{
 		} else {
 			s = strategy.NewDjangoCreateUserDevStrategyGlobal(cfg)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("django create-user-dev failed: %w", err)
 		}
 		return nil
@@ -55,7 +58,10 @@ var djangoCollectStaticCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var s strategy.Strategy
 		if len(args) > 0 {
 			var targetSvc *config.ServiceConfig
@@ -72,7 +78,7 @@ var djangoCollectStaticCmd = &cobra.Command{
 		} else {
 			s = strategy.NewDjangoCollectStaticStrategyGlobal(cfg)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("django collectstatic failed: %w", err)
 		}
 		return nil
@@ -88,7 +94,10 @@ var djangoMigrateCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var s strategy.Strategy
 		if len(args) > 0 {
 			var targetSvc *config.ServiceConfig
@@ -105,7 +114,7 @@ var djangoMigrateCmd = &cobra.Command{
 		} else {
 			s = strategy.NewDjangoMigrateStrategyGlobal(cfg)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("django migrate failed: %w", err)
 		}
 		return nil
@@ -121,7 +130,10 @@ var djangoMakeMigrationsCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var s strategy.Strategy
 		if len(args) > 0 {
 			var targetSvc *config.ServiceConfig
@@ -138,7 +150,7 @@ var djangoMakeMigrationsCmd = &cobra.Command{
 		} else {
 			s = strategy.NewDjangoMakeMigrationsStrategyGlobal(cfg)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("django makemigrations failed: %w", err)
 		}
 		return nil
@@ -154,7 +166,10 @@ var djangoGenRandomSecretKeyCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var s strategy.Strategy
 		if len(args) > 0 {
 			var targetSvc *config.ServiceConfig
@@ -171,7 +186,7 @@ var djangoGenRandomSecretKeyCmd = &cobra.Command{
 		} else {
 			s = strategy.NewDjangoGenRandomSecretKeyStrategyGlobal(cfg)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("django gen-random-secret-key failed: %w", err)
 		}
 		return nil
@@ -22,7 +22,10 @@ var dockerUpCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var s strategy.Strategy
 		if len(args) > 0 {
 			var targetSvc *config.ServiceConfig
@@ -39,7 +42,7 @@ var dockerUpCmd = &cobra.Command{
 		} else {
 			s = strategy.NewDockerUpStrategy(cfg)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("docker up failed: %w", err)
 		}
 		return nil
@@ -55,7 +58,10 @@ var dockerDownCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var s strategy.Strategy
 		if len(args) > 0 {
 			var targetSvc *config.ServiceConfig
@@ -72,7 +78,7 @@ var dockerDownCmd = &cobra.Command{
 		} else {
 			s = strategy.NewDockerDownStrategy(cfg)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("docker down failed: %w", err)
 		}
 		return nil
@@ -88,7 +94,10 @@ var dockerRemoveOrphansCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var s strategy.Strategy
 		if len(args) > 0 {
 			var targetSvc *config.ServiceConfig
@@ -105,7 +114,7 @@ var dockerRemoveOrphansCmd = &cobra.Command{
 		} else {
 			s = strategy.NewDockerRemoveOrphansStrategy(cfg)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("docker remove-orphans failed: %w", err)
 		}
 		return nil
@@ -121,7 +130,10 @@ var dockerRebuildCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var s strategy.Strategy
 		if len(args) > 0 {
 			var targetSvc *config.ServiceConfig
@@ -138,7 +150,7 @@ var dockerRebuildCmd = &cobra.Command{
 		} else {
 			s = strategy.NewDockerRebuildStrategy(cfg)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("docker rebuild failed: %w", err)
 		}
 		return nil
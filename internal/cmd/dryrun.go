@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/strategy"
+)
+
+// DryRun is the output format of --dry-run ("text" or "json"); empty runs commands normally
+var DryRun string
+
+// dryRunPlan is the JSON document written by --dry-run=json
+type dryRunPlan struct {
+	Strategy string                    `json:"strategy"`
+	Inputs   map[string]string         `json:"inputs,omitempty"`
+	Commands []strategy.PlannedCommand `json:"commands"`
+}
+
+// executeStrategy runs s, or prints its plan when --dry-run is set
+func executeStrategy(s strategy.Strategy, sess *session.Session) error {
+	if DryRun == "" {
+		return s.Execute(sess)
+	}
+
+	cmds, err := strategy.Plan(s, sess)
+	if err != nil {
+		return err
+	}
+	return printPlan(os.Stdout, DryRun, s.Name(), sess.PublicInputs(sess.Inputs), cmds)
+}
+
+func printPlan(w io.Writer, format string, name string, inputs map[string]string, cmds []strategy.PlannedCommand) error {
+	switch format {
+	case "json":
+		if cmds == nil {
+			cmds = []strategy.PlannedCommand{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(dryRunPlan{Strategy: name, Inputs: inputs, Commands: cmds})
+	case "text":
+		fmt.Fprintf(w, "Dry run for '%s' (nothing will be executed)\n", name)
+		if len(cmds) == 0 {
+			fmt.Fprintln(w, "\nNo commands to run based on current configuration")
+			return nil
+		}
+		lastTask := ""
+		for i, c := range cmds {
+			if c.Task != lastTask {
+				fmt.Fprintf(w, "\n%s\n", c.Task)
+				lastTask = c.Task
+			}
+			if c.Dir != "" {
+				fmt.Fprintf(w, "  %2d. (in %s) %s\n", i+1, c.Dir, c)
+			} else {
+				fmt.Fprintf(w, "  %2d. %s\n", i+1, c)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid --dry-run format '%s', must be one of: text, json", format)
+	}
+}
+
+func validateDryRun() error {
+	switch DryRun {
+	case "", "text", "json":
+		return nil
+	}
+	return fmt.Errorf("invalid --dry-run format '%s', must be one of: text, json", DryRun)
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&DryRun, "dry-run", "", "print the commands that would run without executing them (text or json)")
+	rootCmd.PersistentFlags().Lookup("dry-run").NoOptDefVal = "text"
+}
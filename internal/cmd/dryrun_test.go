@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/executor"
+	"github.com/madewithfuture/cleat/internal/strategy"
+)
+
+func TestPrintPlan(t *testing.T) {
+	cmds := []strategy.PlannedCommand{
+		{Task: "go:build", Dir: "api", Args: []string{"go", "build", "./..."}},
+		{Task: "shell:run", Args: []string{"sh", "-c", "echo hi"}},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := printPlan(&buf, "text", "workflow:ci", nil, cmds); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out := buf.String()
+		for _, want := range []string{"Dry run for 'workflow:ci'", "go:build", "1. (in api) go build ./...", "2. sh -c 'echo hi'"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected output to contain %q, got:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := printPlan(&buf, "json", "workflow:ci", map[string]string{"env": "prod"}, cmds); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var plan dryRunPlan
+		if err := json.Unmarshal(buf.Bytes(), &plan); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+		}
+		if plan.Strategy != "workflow:ci" || len(plan.Commands) != 2 || plan.Inputs["env"] != "prod" {
+			t.Errorf("unexpected plan: %+v", plan)
+		}
+		if plan.Commands[0].Dir != "api" {
+			t.Errorf("expected dir 'api', got %q", plan.Commands[0].Dir)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		if err := printPlan(io.Discard, "xml", "build", nil, cmds); err == nil {
+			t.Error("expected error for invalid format")
+		}
+	})
+}
+
+func TestDryRunFlag_DoesNotExecute(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	os.WriteFile("cleat.yaml", []byte(`
+version: 1
+workflows:
+  - name: deploy
+    commands:
+      - echo deploying
+`), 0644)
+
+	oldExec := executor.Default
+	mock := &MockExecutor{}
+	executor.Default = mock
+	defer func() {
+		executor.Default = oldExec
+		DryRun = ""
+	}()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	_, err := executeCommand(rootCmd, "--dry-run=json", "workflow", "deploy")
+	w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.RunCalled {
+		t.Error("expected no commands to be executed in dry-run mode")
+	}
+	var plan dryRunPlan
+	if err := json.Unmarshal(out, &plan); err != nil {
+		t.Fatalf("expected JSON plan, got %v:\n%s", err, out)
+	}
+	if len(plan.Commands) != 1 || plan.Commands[0].String() != "sh -c 'echo deploying'" {
+		t.Errorf("unexpected commands: %+v", plan.Commands)
+	}
+}
+
+func TestDryRunFlag_InvalidFormat(t *testing.T) {
+	defer func() { DryRun = "" }()
+	if _, err := executeCommand(rootCmd, "--dry-run=yaml", "version"); err == nil {
+		t.Error("expected error for invalid --dry-run format")
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/export"
+	"github.com/madewithfuture/cleat/internal/history"
+	"github.com/madewithfuture/cleat/internal/logger"
+	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/strategy"
+	"github.com/madewithfuture/cleat/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// exportedStrategies are the standardized commands that are exported
+var exportedStrategies = []string{"build", "test", "install", "lint", "fmt", "run"}
+
+// Flags of export
+var (
+	exportFormat string
+	exportOutput string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the commands and workflows as a Makefile, justfile or CI configuration",
+	Long: `Write the commands and workflows as a Makefile, justfile or CI configuration.
+
+Every standardized command (build, test, install, lint, fmt, run), command
+from cleat.yaml and workflow is resolved to the commands it would run. Inputs
+given with --input are written into the commands; the others are read from
+CLEAT_INPUT_<KEY> environment variables when the exported file runs, except
+for workflow steps whose input decides what cleat runs, such as the env of
+terraform plan:${env}: those workflows need the input given with --input.
+
+Exported commands run one after another and stop at the first failure, so
+workflows with if conditions, continue_on_error or finally steps are left
+out with a warning.`,
+	Example: `  cleat export --format makefile -o Makefile
+  cleat export --format github-actions -o .github/workflows/cleat.yaml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !isExportFormat(exportFormat) {
+			return fmt.Errorf("invalid export format '%s', must be one of: %s", exportFormat, strings.Join(export.Formats, ", "))
+		}
+
+		var cfg *config.Config
+		var err error
+		if ConfigPath != "" {
+			cfg, err = config.LoadConfig(ConfigPath)
+		} else {
+			cfg, err = config.LoadDefaultConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if workflows, err := history.LoadWorkflows(cfg); err == nil {
+			cfg.Workflows = workflows
+		}
+
+		sess, err := newSession(cfg)
+		if err != nil {
+			return err
+		}
+		if err := strategy.InterpolateConfigForExport(sess); err != nil {
+			return err
+		}
+		targets := exportTargets(sess, cmd.ErrOrStderr())
+
+		var out io.Writer = cmd.OutOrStdout()
+		if exportOutput != "" {
+			if err := os.MkdirAll(filepath.Dir(exportOutput), 0755); err != nil {
+				return fmt.Errorf("failed to create directory for %s: %w", exportOutput, err)
+			}
+			f, err := os.Create(exportOutput)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", exportOutput, err)
+			}
+			defer f.Close()
+			out = f
+		}
+		if err := export.Render(out, exportFormat, targets); err != nil {
+			return err
+		}
+		if exportOutput != "" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d target(s) to %s\n", len(targets), exportOutput)
+		}
+		return nil
+	},
+}
+
+// exportTargets plans a target for each standardized command, user command
+// and workflow that runs anything. Targets that cannot be planned are
+// reported to warn and left out.
+func exportTargets(sess *session.Session, warn io.Writer) []export.Target {
+	cfg := sess.Config
+	root := config.FindProjectRoot()
+	if cfg.SourcePath != "" {
+		root = filepath.Dir(cfg.SourcePath)
+	}
+
+	var targets []export.Target
+	used := make(map[string]bool)
+	add := func(command, name, prefix, description string) {
+		s := strategy.GetStrategyForCommand(command, sess)
+		if s == nil {
+			return
+		}
+		cmds, err := strategy.PlanExport(s, sess)
+		if err != nil {
+			logger.Warn("skipping export target", map[string]interface{}{"command": command, "error": err.Error()})
+			fmt.Fprintf(warn, "Skipping %s: %v\n", command, err)
+			return
+		}
+		if len(cmds) == 0 {
+			return
+		}
+		for i, c := range cmds {
+			// Exported files run from the project root
+			if filepath.IsAbs(c.Dir) {
+				if rel, err := filepath.Rel(root, c.Dir); err == nil && !strings.HasPrefix(rel, "..") {
+					cmds[i].Dir = rel
+				}
+			}
+			if cmds[i].Dir == "." {
+				cmds[i].Dir = ""
+			}
+		}
+
+		name = export.TargetName(name)
+		if used[name] {
+			name = prefix + name
+		}
+		used[name] = true
+		targets = append(targets, export.Target{Name: name, Description: description, Commands: cmds})
+	}
+
+	for _, name := range exportedStrategies {
+		add(name, name, "", "")
+	}
+	for i := range cfg.Commands {
+		c := &cfg.Commands[i]
+		add(task.CommandName(nil, c), c.Name, "command-", c.Description)
+	}
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		for j := range svc.Commands {
+			c := &svc.Commands[j]
+			add(task.CommandName(svc, c), svc.Name+"-"+c.Name, "command-", c.Description)
+		}
+	}
+	for _, wf := range cfg.Workflows {
+		add("workflow:"+wf.ID, wf.ID, "workflow-", "Workflow "+wf.Name)
+	}
+	return targets
+}
+
+func isExportFormat(format string) bool {
+	for _, f := range export.Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "output format: "+strings.Join(export.Formats, ", "))
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file to write instead of standard output")
+	exportCmd.MarkFlagRequired("format")
+	rootCmd.AddCommand(exportCmd)
+}
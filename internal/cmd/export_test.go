@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/executor"
+	"github.com/madewithfuture/cleat/internal/history"
+)
+
+func TestExportCmd(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	oldUserHomeDir := history.UserHomeDir
+	history.UserHomeDir = func() (string, error) { return tmpDir, nil }
+	defer func() { history.UserHomeDir = oldUserHomeDir }()
+
+	os.WriteFile("cleat.yaml", []byte(`
+services:
+  - name: api
+    dir: api
+    modules:
+      - go: {}
+commands:
+  - name: build
+    run: make all
+  - name: greet
+    run: echo hello
+    inputs:
+      - key: who
+workflows:
+  - name: release
+    commands:
+      - command:build
+      - echo released
+  - name: deploy
+    params:
+      - name: env
+    commands:
+      - run: echo deploying
+        if: inputs.env == 'prod'
+    finally:
+      - echo cleanup
+`), 0644)
+	os.MkdirAll("api", 0755)
+	os.WriteFile(filepath.Join("api", ".golangci.yml"), []byte("linters: {}\n"), 0644)
+
+	mock := &MockExecutor{}
+	oldDefault := executor.Default
+	executor.Default = mock
+	defer func() { executor.Default = oldDefault }()
+	defer func() { exportFormat, exportOutput = "", "" }()
+
+	var stderr strings.Builder
+	rootCmd.SetErr(&stderr)
+	defer rootCmd.SetErr(nil)
+
+	out := filepath.Join("ci", "Makefile")
+	rootCmd.SetArgs([]string{"export", "--format", "makefile", "-o", out})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if want := "Skipping workflow:deploy: workflow 'deploy' step 'echo deploying' has an if condition, which cannot be exported"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("expected a warning about deploy, got:\n%s", stderr.String())
+	}
+	if mock.RunCalled {
+		t.Error("expected export not to run anything")
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("expected %s to be written: %v", out, err)
+	}
+	for _, want := range []string{
+		"build:\n\tcd api && go build ./...\n",
+		// Linters report without fixing
+		"lint:\n\tcd api && golangci-lint run ./...\n",
+		// The user command named build does not replace the standard target
+		"command-build:\n\tsh -c 'make all'\n",
+		"greet:\n\tsh -c 'echo hello'\n",
+		"release:\n\tsh -c 'make all'\n\tsh -c 'echo released'\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected Makefile to contain %q, got:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "deploying") {
+		t.Errorf("expected deploy left out of the Makefile, got:\n%s", data)
+	}
+
+	rootCmd.SetArgs([]string{"export", "--format", "ant"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid export format") {
+		t.Errorf("expected invalid format error, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/strategy"
+	"github.com/spf13/cobra"
+)
+
+var fmtCheck bool
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Run the formatters of every service",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var cfg *config.Config
+		var err error
+		if ConfigPath != "" {
+			cfg, err = config.LoadConfig(ConfigPath)
+		} else {
+			cfg, err = config.LoadDefaultConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
+		sess.CheckOnly = fmtCheck
+		s := strategy.GetStrategyForCommand("fmt", sess)
+		if s == nil {
+			return fmt.Errorf("no strategy found for fmt")
+		}
+		if err := executeStrategy(s, sess); err != nil {
+			return fmt.Errorf("fmt failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "report unformatted files without rewriting them; fails if any are found")
+	rootCmd.AddCommand(fmtCmd)
+}
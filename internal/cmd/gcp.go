@@ -26,9 +26,12 @@ var gcpActivateCmd = &cobra.Command{
 			return fmt.Errorf("google_cloud_platform.project_name is not configured")
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		s := strategy.NewGCPActivateStrategy()
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("gcp activate failed: %w", err)
 		}
 		return nil
@@ -48,9 +51,12 @@ var gcpInitCmd = &cobra.Command{
 			return fmt.Errorf("google_cloud_platform.project_name is not configured")
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		s := strategy.NewGCPInitStrategy()
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("gcp init failed: %w", err)
 		}
 		return nil
@@ -70,9 +76,12 @@ var gcpSetConfigCmd = &cobra.Command{
 			return fmt.Errorf("google_cloud_platform.project_name is not configured")
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		s := strategy.NewGCPSetConfigStrategy()
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("gcp set-config failed: %w", err)
 		}
 		return nil
@@ -92,9 +101,12 @@ var gcpADCLoginCmd = &cobra.Command{
 			return fmt.Errorf("google_cloud_platform.project_name is not configured")
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		s := strategy.NewGCPADCLoginStrategy()
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("gcp adc-login failed: %w", err)
 		}
 		return nil
@@ -114,9 +126,12 @@ var gcpADCImpersonateLoginCmd = &cobra.Command{
 			return fmt.Errorf("google_cloud_platform.project_name is not configured")
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		s := strategy.NewGCPADCImpersonateLoginStrategy()
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("gcp adc-impersonate-login failed: %w", err)
 		}
 		return nil
@@ -160,9 +175,12 @@ var gcpAppEngineDeployCmd = &cobra.Command{
 			}
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		s := strategy.NewGCPAppEngineDeployStrategy(appYaml)
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("gcp app-engine deploy failed: %w", err)
 		}
 		return nil
@@ -187,9 +205,12 @@ var gcpAppEnginePromoteCmd = &cobra.Command{
 			service = args[0]
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		s := strategy.NewGCPAppEnginePromoteStrategy(service)
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("gcp app-engine promote failed: %w", err)
 		}
 		return nil
@@ -209,9 +230,12 @@ var gcpConsoleCmd = &cobra.Command{
 			return fmt.Errorf("google_cloud_platform.project_name is not configured")
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		s := strategy.NewGCPConsoleStrategy()
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("gcp console failed: %w", err)
 		}
 		return nil
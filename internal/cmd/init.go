@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/executor"
+	"github.com/madewithfuture/cleat/internal/settings"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Flags of init
+var (
+	initForce   bool
+	initMinimal bool
+	initStdout  bool
+	initYes     bool
+)
+
+// initInteractive reports whether init can ask questions
+var initInteractive = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write the detected configuration to cleat.yaml",
+	Long: `Write the detected configuration to cleat.yaml.
+
+Runs auto-detection on the current directory and writes what it finds as a
+commented cleat.yaml that loads back into the same configuration. Each
+detected service and module is confirmed first, unless confirm.init is off
+in ~/.cleat/config.yaml; a declined module is written with enabled: false,
+and a declined service has docker and all of its modules turned off, since
+detection would find it again.
+
+Commands from a Makefile, justfile or Taskfile are not written, because they
+are read again whenever the file is loaded.`,
+	Example: `  cleat init
+  cleat init --minimal --yes
+  cleat init --stdout > cleat.yaml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := ConfigPath
+		if path == "" {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+			path = filepath.Join(cwd, "cleat.yaml")
+		}
+		if !initStdout && !initForce {
+			for _, existing := range []string{path, filepath.Join(filepath.Dir(path), "cleat.yml")} {
+				if _, err := os.Stat(existing); err == nil {
+					return fmt.Errorf("%s already exists, use --force to overwrite it", displayPath(existing))
+				}
+			}
+		}
+
+		dir := filepath.Dir(path)
+		detected, err := config.Detect(dir)
+		if err != nil {
+			return fmt.Errorf("failed to detect the configuration: %w", err)
+		}
+		cfg, err := config.Detect(dir)
+		if err != nil {
+			return fmt.Errorf("failed to detect the configuration: %w", err)
+		}
+		// Questions go to standard output, so they would end up in --stdout's file
+		if !initStdout && !initYes && !NoInput && settings.Current().Confirm.Init && initInteractive() {
+			if err := confirmDetected(cfg); err != nil {
+				return err
+			}
+		}
+
+		data, err := config.Render(cfg, detected, initMinimal)
+		if err != nil {
+			return err
+		}
+		if initStdout {
+			_, err := cmd.OutOrStdout().Write(data)
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ Wrote %s with %d service(s)\n", displayPath(path), len(cfg.Services))
+		return nil
+	},
+}
+
+// confirmDetected asks whether to keep each detected service and module,
+// turning off the ones that are declined
+func confirmDetected(cfg *config.Config) error {
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		keep, err := initConfirm(fmt.Sprintf("Keep service '%s' (%s)?", svc.Name, describeService(svc)))
+		if err != nil {
+			return err
+		}
+		if !keep {
+			if svc.IsDocker() {
+				off := false
+				svc.Docker = &off
+			}
+			for j := range svc.Modules {
+				svc.Modules[j] = disabledModule(svc.Modules[j])
+			}
+			continue
+		}
+		for j := range svc.Modules {
+			keep, err := initConfirm(fmt.Sprintf("Keep the %s module of '%s'?", moduleKind(svc.Modules[j]), svc.Name))
+			if err != nil {
+				return err
+			}
+			if !keep {
+				svc.Modules[j] = disabledModule(svc.Modules[j])
+			}
+		}
+	}
+	return nil
+}
+
+// initConfirm asks a yes/no question that defaults to yes
+func initConfirm(question string) (bool, error) {
+	p, ok := executor.Default.(executor.TypedPrompter)
+	if !ok {
+		return true, nil
+	}
+	answer, err := p.PromptTyped(executor.PromptSpec{Message: question, Default: "true", Confirm: true})
+	if err != nil {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	return answer == "true", nil
+}
+
+func describeService(svc *config.ServiceConfig) string {
+	dir := svc.Dir
+	if dir == "" {
+		dir = "."
+	}
+	parts := []string{"dir " + dir}
+	if svc.IsDocker() {
+		parts = append(parts, "docker")
+	}
+	for _, m := range svc.Modules {
+		parts = append(parts, moduleKind(m))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func moduleKind(m config.ModuleConfig) string {
+	switch {
+	case m.Python != nil && m.Python.Django:
+		return "Django"
+	case m.Python != nil:
+		return "Python"
+	case m.Npm != nil:
+		return "NPM"
+	case m.Go != nil:
+		return "Go"
+	case m.Ruby != nil && m.Ruby.Rails:
+		return "Rails"
+	case m.Ruby != nil:
+		return "Ruby"
+	}
+	return "unknown"
+}
+
+// disabledModule returns m turned off. Its other fields are dropped, since
+// detection fills in no defaults for a disabled module.
+func disabledModule(m config.ModuleConfig) config.ModuleConfig {
+	off := false
+	switch {
+	case m.Python != nil:
+		return config.ModuleConfig{Python: &config.PythonConfig{Enabled: &off}}
+	case m.Npm != nil:
+		return config.ModuleConfig{Npm: &config.NpmConfig{Enabled: &off}}
+	case m.Go != nil:
+		return config.ModuleConfig{Go: &config.GoConfig{Enabled: &off}}
+	case m.Ruby != nil:
+		return config.ModuleConfig{Ruby: &config.RubyConfig{Enabled: &off}}
+	}
+	return m
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite an existing cleat.yaml")
+	initCmd.Flags().BoolVar(&initMinimal, "minimal", false, "only write what detection would not find again")
+	initCmd.Flags().BoolVar(&initStdout, "stdout", false, "print the configuration instead of writing cleat.yaml, without asking")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "keep every detected service and module without asking")
+	rootCmd.AddCommand(initCmd)
+}
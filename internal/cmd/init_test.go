@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/executor"
+)
+
+// answeringExecutor answers confirmations from a list, recording the questions
+type answeringExecutor struct {
+	executor.ShellExecutor
+	answers   []string
+	questions []string
+}
+
+func (e *answeringExecutor) PromptTyped(spec executor.PromptSpec) (string, error) {
+	e.questions = append(e.questions, spec.Message)
+	if len(e.answers) == 0 {
+		return spec.Default, nil
+	}
+	answer := e.answers[0]
+	e.answers = e.answers[1:]
+	return answer, nil
+}
+
+func TestInitCmd(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	os.WriteFile("docker-compose.yaml", []byte(`services:
+  api:
+    build: .
+    command: python manage.py runserver
+  web:
+    image: node:20
+    command: npm run dev
+`), 0644)
+	os.WriteFile("manage.py", []byte(""), 0644)
+	os.WriteFile("package.json", []byte(`{"scripts": {"dev": "vite"}}`), 0644)
+
+	oldInteractive := initInteractive
+	initInteractive = func() bool { return true }
+	defer func() { initInteractive = oldInteractive }()
+	mock := &answeringExecutor{}
+	oldDefault := executor.Default
+	executor.Default = mock
+	defer func() { executor.Default = oldDefault }()
+
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	defer rootCmd.SetOut(nil)
+	execute := func(args ...string) error {
+		out.Reset()
+		initForce, initMinimal, initStdout, initYes = false, false, false, false
+		rootCmd.SetArgs(append([]string{"init"}, args...))
+		return rootCmd.Execute()
+	}
+	defer func() { initForce, initMinimal, initStdout, initYes = false, false, false, false }()
+
+	// Keep api, decline its Django module, then decline web
+	mock.answers = []string{"true", "false", "false"}
+	if err := execute(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "✓ Wrote cleat.yaml with 2 service(s)") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	expectedQuestions := []string{
+		"Keep service 'api' (dir ., docker, Django)?",
+		"Keep the Django module of 'api'?",
+		"Keep service 'web' (dir ., docker, NPM)?",
+	}
+	if strings.Join(mock.questions, "\n") != strings.Join(expectedQuestions, "\n") {
+		t.Errorf("expected questions %q, got %q", expectedQuestions, mock.questions)
+	}
+
+	cfg, err := config.LoadConfig("cleat.yaml")
+	if err != nil {
+		t.Fatalf("written config does not load: %v", err)
+	}
+	api, web := cfg.Services[0], cfg.Services[1]
+	if !api.IsDocker() || api.Modules[0].Python.IsEnabled() {
+		t.Errorf("expected api in docker with Python off, got %+v", api)
+	}
+	if web.IsDocker() || web.Modules[0].Npm.IsEnabled() {
+		t.Errorf("expected web turned off, got %+v", web)
+	}
+
+	if err := execute(); err == nil || !strings.Contains(err.Error(), "cleat.yaml already exists, use --force to overwrite it") {
+		t.Errorf("expected an error for an existing file, got %v", err)
+	}
+
+	mock.questions = nil
+	if err := execute("--force", "--yes", "--minimal"); err != nil {
+		t.Fatalf("init --force failed: %v", err)
+	}
+	if len(mock.questions) != 0 {
+		t.Errorf("expected no questions with --yes, got %q", mock.questions)
+	}
+	data, _ := os.ReadFile("cleat.yaml")
+	if strings.Contains(string(data), "services:") {
+		t.Errorf("expected a minimal config without services, got:\n%s", data)
+	}
+
+	if err := execute("--stdout"); err != nil {
+		t.Fatalf("init --stdout failed: %v", err)
+	}
+	if len(mock.questions) != 0 {
+		t.Errorf("expected no questions with --stdout, got %q", mock.questions)
+	}
+	if !strings.Contains(out.String(), "- name: web") || !strings.Contains(out.String(), "- name: api") {
+		t.Errorf("expected the services on standard output, got:\n%s", out.String())
+	}
+}
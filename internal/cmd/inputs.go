@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// InputFlags holds raw --input key=value pairs
+	InputFlags []string
+	// InputsFile is a YAML file of key: value inputs (--inputs-file)
+	InputsFile string
+	// NoInput makes missing inputs an error instead of a prompt (--no-input)
+	NoInput bool
+)
+
+// cliInputs are the inputs supplied on the command line, loaded before each command runs
+var cliInputs map[string]string
+
+// loadCLIInputs merges --inputs-file and --input values, with flags taking precedence
+func loadCLIInputs() error {
+	cliInputs = nil
+	inputs := make(map[string]string)
+
+	if InputsFile != "" {
+		data, err := os.ReadFile(InputsFile)
+		if err != nil {
+			return fmt.Errorf("failed to read inputs file: %w", err)
+		}
+		var fileInputs map[string]string
+		if err := yaml.Unmarshal(data, &fileInputs); err != nil {
+			return fmt.Errorf("failed to parse inputs file %s: %w", InputsFile, err)
+		}
+		for k, v := range fileInputs {
+			inputs[k] = v
+		}
+	}
+
+	for _, pair := range InputFlags {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --input '%s', expected key=value", pair)
+		}
+		inputs[key] = value
+	}
+
+	if len(inputs) > 0 {
+		cliInputs = inputs
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringArrayVar(&InputFlags, "input", nil, "supply an input as key=value (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&InputsFile, "inputs-file", "", "YAML file of key: value inputs")
+	rootCmd.PersistentFlags().BoolVar(&NoInput, "no-input", false, "fail instead of prompting when an input is missing")
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/executor"
+)
+
+func resetInputFlags() {
+	InputFlags = nil
+	InputsFile = ""
+	NoInput = false
+	cliInputs = nil
+}
+
+func TestLoadCLIInputs(t *testing.T) {
+	defer resetInputFlags()
+
+	t.Run("file and flags merge with flags winning", func(t *testing.T) {
+		resetInputFlags()
+		file := filepath.Join(t.TempDir(), "inputs.yaml")
+		os.WriteFile(file, []byte("install_path: /opt/bin\ngcp:version: 42\n"), 0644)
+		InputsFile = file
+		InputFlags = []string{"install_path=/usr/bin", "gcp:account=a=b@example.com"}
+
+		if err := loadCLIInputs(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := map[string]string{
+			"install_path": "/usr/bin",
+			"gcp:version":  "42",
+			"gcp:account":  "a=b@example.com",
+		}
+		for k, v := range expected {
+			if cliInputs[k] != v {
+				t.Errorf("expected %s=%q, got %q", k, v, cliInputs[k])
+			}
+		}
+	})
+
+	t.Run("invalid flag", func(t *testing.T) {
+		resetInputFlags()
+		InputFlags = []string{"novalue"}
+		if err := loadCLIInputs(); err == nil {
+			t.Error("expected error for input without '='")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		resetInputFlags()
+		InputsFile = filepath.Join(t.TempDir(), "missing.yaml")
+		if err := loadCLIInputs(); err == nil {
+			t.Error("expected error for missing inputs file")
+		}
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		resetInputFlags()
+		file := filepath.Join(t.TempDir(), "inputs.yaml")
+		os.WriteFile(file, []byte("- not\n- a map\n"), 0644)
+		InputsFile = file
+		if err := loadCLIInputs(); err == nil {
+			t.Error("expected error for non-map inputs file")
+		}
+	})
+}
+
+func TestCreateSessionAndMerge_CLIInputs(t *testing.T) {
+	defer resetInputFlags()
+	defer func() { preCollectedInputs = nil }()
+
+	preCollectedInputs = map[string]string{"env": "dev", "other": "tui"}
+	cliInputs = map[string]string{"env": "prod"}
+	NoInput = true
+
+	sess, _ := createSessionAndMerge(&config.Config{})
+	if sess.Inputs["env"] != "prod" || sess.Inputs["other"] != "tui" {
+		t.Errorf("unexpected merged inputs: %v", sess.Inputs)
+	}
+	if !sess.NoInput {
+		t.Error("expected NoInput to be set on session")
+	}
+}
+
+func TestNoInputFlag_ReportsMissingInputs(t *testing.T) {
+	defer resetInputFlags()
+
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	os.WriteFile("cleat.yaml", []byte(`
+version: 1
+google_cloud_platform:
+  project_name: test-proj
+`), 0644)
+
+	_, err := executeCommand(rootCmd, "--no-input", "gcp", "init")
+	if err == nil {
+		t.Fatal("expected error for missing inputs")
+	}
+	if !strings.Contains(err.Error(), "gcp:account") || !strings.Contains(err.Error(), "CLEAT_INPUT_GCP_ACCOUNT") {
+		t.Errorf("expected missing gcp:account to be reported, got %v", err)
+	}
+
+	resetInputFlags()
+	mock := &MockExecutor{}
+	withExecutor(t, mock)
+	if _, err := executeCommand(rootCmd, "--no-input", "--input", "gcp:account=ci@example.com", "gcp", "init"); err != nil {
+		t.Fatalf("expected supplied input to satisfy requirement, got %v", err)
+	}
+	if !mock.RunCalled {
+		t.Error("expected gcp init to run")
+	}
+}
+
+func withExecutor(t *testing.T, e executor.Executor) {
+	t.Helper()
+	old := executor.Default
+	executor.Default = e
+	t.Cleanup(func() { executor.Default = old })
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/strategy"
+	"github.com/spf13/cobra"
+)
+
+// Flags of lint
+var (
+	lintFix   bool
+	lintCheck bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Run the linters of every service",
+	Long: `Run the linters of every service. Linters only report problems and fail
+when they find any; with --fix, those that can fix problems do so.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var cfg *config.Config
+		var err error
+		if ConfigPath != "" {
+			cfg, err = config.LoadConfig(ConfigPath)
+		} else {
+			cfg, err = config.LoadDefaultConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
+		sess.Fix = lintFix
+		s := strategy.GetStrategyForCommand("lint", sess)
+		if s == nil {
+			return fmt.Errorf("no strategy found for lint")
+		}
+		if err := executeStrategy(s, sess); err != nil {
+			return fmt.Errorf("lint failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "fix the problems the linters can fix")
+	lintCmd.Flags().BoolVar(&lintCheck, "check", false, "report problems without fixing them")
+	lintCmd.Flags().MarkDeprecated("check", "linters only report problems unless --fix is given")
+	lintCmd.MarkFlagsMutuallyExclusive("fix", "check")
+	rootCmd.AddCommand(lintCmd)
+}
@@ -19,7 +19,10 @@ var npmCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var command string
 		if len(args) == 2 {
 			command = fmt.Sprintf("npm run %s:%s", args[1], args[0])
@@ -31,7 +34,7 @@ var npmCmd = &cobra.Command{
 		if s == nil {
 			return fmt.Errorf("no strategy found for %s", command)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("npm script failed: %w", err)
 		}
 		return nil
@@ -48,7 +51,10 @@ var npmInstallCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		var command string
 		if len(args) == 1 {
 			command = "npm install:" + args[0]
@@ -60,7 +66,7 @@ var npmInstallCmd = &cobra.Command{
 		if s == nil {
 			return fmt.Errorf("no strategy found for %s", command)
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("npm install failed: %w", err)
 		}
 		return nil
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,6 +16,7 @@ import (
 	"github.com/madewithfuture/cleat/internal/history"
 	"github.com/madewithfuture/cleat/internal/logger"
 	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/strategy"
 	"github.com/madewithfuture/cleat/internal/ui"
 	"github.com/madewithfuture/cleat/internal/ui/theme"
 	"github.com/spf13/cobra"
@@ -33,16 +35,10 @@ type WaitFunc func() WaitAction
 
 var (
 	ConfigPath string
-	UIStart    = func(version string) (string, map[string]string, error) {
+	UIStart    = func(version string) (string, map[string]string, map[string]bool, error) {
 		configPath := ConfigPath
 		if configPath == "" {
-			root := config.FindProjectRoot()
-			configPath = filepath.Join(root, "cleat.yaml")
-			if _, err := os.Stat(configPath); os.IsNotExist(err) {
-				if _, err := os.Stat(filepath.Join(root, "cleat.yml")); err == nil {
-					configPath = filepath.Join(root, "cleat.yml")
-				}
-			}
+			configPath = defaultConfigPath()
 		}
 		return ui.Start(version, configPath)
 	}
@@ -52,20 +48,98 @@ var (
 
 var preCollectedInputs map[string]string
 
+// preCollectedSecrets marks which of preCollectedInputs were answered as
+// secrets in the TUI
+var preCollectedSecrets map[string]bool
+
+// lastSession is the session of the most recent command, consulted when
+// recording history so secret inputs are left out
+var lastSession *session.Session
+
+// Jobs is the maximum number of tasks parallel strategies run at once (--jobs)
+var Jobs int
+
 var rootCmd = &cobra.Command{
 	Use:   "cleat",
 	Short: "Cleat is a TUI-based CLI tool",
 	Long:  `Cleat is a tool that provides both a terminal user interface and command line actions.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateDryRun(); err != nil {
+			return err
+		}
+		return loadCLIInputs()
+	},
 }
 
-func createSessionAndMerge(cfg *config.Config) *session.Session {
+// ServiceNames and ExcludeServices restrict strategies to some services (--service, --exclude-service)
+var (
+	ServiceNames    []string
+	ExcludeServices []string
+)
+
+// defaultConfigPath is the project's cleat.yaml, or its cleat.yml when only that exists
+func defaultConfigPath() string {
+	root := config.FindProjectRoot()
+	configPath := filepath.Join(root, "cleat.yaml")
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if _, err := os.Stat(filepath.Join(root, "cleat.yml")); err == nil {
+			configPath = filepath.Join(root, "cleat.yml")
+		}
+	}
+	return configPath
+}
+
+// createSessionAndMerge creates the session of a command and expands the
+// references in its config values, prompting for the inputs they need
+func createSessionAndMerge(cfg *config.Config) (*session.Session, error) {
+	sess, err := newSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := strategy.InterpolateConfig(sess); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// newSession creates the session of a command with the inputs given so far,
+// leaving its config values as they were loaded
+func newSession(cfg *config.Config) (*session.Session, error) {
+	if len(ServiceNames) > 0 || len(ExcludeServices) > 0 {
+		filtered, err := cfg.FilterServices(ServiceNames, ExcludeServices)
+		if err != nil {
+			return nil, err
+		}
+		cfg = filtered
+	}
+
 	sess := session.NewSession(cfg, executor.Default)
+	if len(ServiceNames) > 0 || len(ExcludeServices) > 0 {
+		sess.Services = []string{}
+		for _, svc := range cfg.Services {
+			sess.Services = append(sess.Services, svc.Name)
+		}
+	}
+	if ctx := rootCmd.Context(); ctx != nil {
+		sess.SetContext(ctx)
+	}
+	if Jobs > 0 {
+		sess.Jobs = Jobs
+	}
+	sess.NoInput = NoInput
 	if preCollectedInputs != nil {
 		for k, v := range preCollectedInputs {
 			sess.Inputs[k] = v
 		}
 	}
-	return sess
+	for k := range preCollectedSecrets {
+		sess.MarkSecret(k)
+	}
+	for k, v := range cliInputs {
+		sess.Inputs[k] = v
+	}
+	lastSession = sess
+	return sess, nil
 }
 
 func Execute() {
@@ -77,23 +151,26 @@ func run(args []string) {
 	var commandQueue []struct {
 		selected      string
 		inputs        map[string]string
+		secrets       map[string]bool
 		workflowRunID string
 	}
 
 	for {
 		var selected string
 		var inputs map[string]string
+		var secrets map[string]bool
 		var workflowRunID string
 		if len(commandQueue) > 0 {
 			item := commandQueue[0]
 			commandQueue = commandQueue[1:]
 			selected = item.selected
 			inputs = item.inputs
+			secrets = item.secrets
 			workflowRunID = item.workflowRunID
 			logger.Info("running command from workflow queue", map[string]interface{}{"command": selected, "run_id": workflowRunID})
 		} else if tuiMode {
 			var err error
-			selected, inputs, err = UIStart(Version)
+			selected, inputs, secrets, err = UIStart(Version)
 			if err != nil {
 				logger.Error("failed to start TUI", err, nil)
 				fmt.Printf("Error starting TUI: %v\n", err)
@@ -109,6 +186,7 @@ func run(args []string) {
 
 		if selected != "" {
 			preCollectedInputs = inputs
+			preCollectedSecrets = secrets
 			cmdArgs := mapSelectedToArgs(selected)
 
 			if len(cmdArgs) > 0 {
@@ -125,8 +203,26 @@ func run(args []string) {
 		}
 
 		for {
-			err := rootCmd.Execute()
-			if err != nil {
+			lastSession = nil
+			workflowHistoryRecorded = false
+			ctx, stop := executor.NotifyContext(context.Background())
+			err := rootCmd.ExecuteContext(ctx)
+			stop()
+			// Later Execute calls must not inherit the stopped context
+			rootCmd.SetContext(context.Background())
+			cancelled := executor.IsCancelled(err)
+			if cancelled {
+				logger.Warn("command cancelled", map[string]interface{}{"selected": selected, "reason": err.Error()})
+				fmt.Fprintln(os.Stderr, "Cancelled:", err)
+				if !tuiMode {
+					Exit(130)
+					return
+				}
+				if len(commandQueue) > 0 {
+					fmt.Println("Workflow cancelled. Stopping.")
+					commandQueue = nil
+				}
+			} else if err != nil {
 				logger.Error("command execution failed", err, map[string]interface{}{"selected": selected})
 				fmt.Fprintln(os.Stderr, err)
 				if !tuiMode {
@@ -140,14 +236,28 @@ func run(args []string) {
 			}
 
 			if tuiMode && selected != "" {
-				history.Save(history.HistoryEntry{
-					Timestamp:     time.Now(),
-					Command:       selected,
-					Inputs:        inputs,
-					Success:       err == nil,
-					WorkflowRunID: workflowRunID,
-				})
-				if workflowRunID == "" {
+				if !workflowHistoryRecorded {
+					// Secrets answered in the TUI are dropped even when the
+					// command failed before its session could mark them
+					histInputs := make(map[string]string)
+					for k, v := range inputs {
+						if !secrets[k] {
+							histInputs[k] = v
+						}
+					}
+					if lastSession != nil {
+						histInputs = lastSession.PublicInputs(inputs)
+					}
+					history.Save(history.HistoryEntry{
+						Timestamp:     time.Now(),
+						Command:       selected,
+						Inputs:        histInputs,
+						Success:       err == nil,
+						Cancelled:     cancelled,
+						WorkflowRunID: workflowRunID,
+					})
+				}
+				if workflowRunID == "" && !strings.HasPrefix(selected, "workflow resume:") {
 					history.UpdateStats(selected)
 				}
 			}
@@ -166,8 +276,9 @@ func run(args []string) {
 						commandQueue = append(commandQueue, struct {
 							selected      string
 							inputs        map[string]string
+							secrets       map[string]bool
 							workflowRunID string
-						}{selected, inputs, workflowRunID})
+						}{selected, inputs, secrets, workflowRunID})
 					}
 					break
 				case WaitExit:
@@ -187,9 +298,13 @@ func run(args []string) {
 
 func mapSelectedToArgs(selected string) []string {
 	var cmdArgs []string
-	if strings.HasPrefix(selected, "workflow:") {
+	if strings.HasPrefix(selected, "workflow resume:") {
+		cmdArgs = []string{"workflow", "resume", strings.TrimPrefix(selected, "workflow resume:")}
+	} else if strings.HasPrefix(selected, "workflow:") {
 		// Let the dispatcher handle it
 		cmdArgs = []string{"workflow", strings.TrimPrefix(selected, "workflow:")}
+	} else if strings.HasPrefix(selected, "command:") {
+		cmdArgs = []string{"command", strings.TrimPrefix(selected, "command:")}
 	} else if strings.HasPrefix(selected, "docker ") || strings.HasPrefix(selected, "gcp ") || strings.HasPrefix(selected, "terraform ") {
 		cmdArgs = strings.Fields(selected)
 		if strings.Contains(selected, ":") {
@@ -242,6 +357,9 @@ func mapSelectedToArgs(selected string) []string {
 }
 
 func init() {
+	rootCmd.PersistentFlags().IntVarP(&Jobs, "jobs", "j", 1, "maximum number of independent tasks to run in parallel")
+	rootCmd.PersistentFlags().StringSliceVar(&ServiceNames, "service", nil, "only run tasks for these services (comma-separated)")
+	rootCmd.PersistentFlags().StringSliceVar(&ExcludeServices, "exclude-service", nil, "skip tasks for these services (comma-separated)")
 }
 
 func waitForAnyKey() WaitAction {
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/madewithfuture/cleat/internal/executor"
 	"github.com/madewithfuture/cleat/internal/history"
 )
 
@@ -43,12 +45,12 @@ func TestRun(t *testing.T) {
 
 	t.Run("No args, TUI returns docker up", func(t *testing.T) {
 		calls := 0
-		UIStart = func(string) (string, map[string]string, error) {
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
 			calls++
 			if calls == 1 {
-				return "docker up", nil, nil
+				return "docker up", nil, nil, nil
 			}
-			return "", nil, nil
+			return "", nil, nil, nil
 		}
 		// We need to prevent actual task execution if possible, or just check if buildCmd was triggered
 		// Since we don't have a clean way to mock the command implementation without more refactoring,
@@ -62,8 +64,8 @@ func TestRun(t *testing.T) {
 
 	t.Run("No args, TUI returns error", func(t *testing.T) {
 		exitCode = 0
-		UIStart = func(string) (string, map[string]string, error) {
-			return "", nil, errors.New("TUI error")
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
+			return "", nil, nil, errors.New("TUI error")
 		}
 		run([]string{"cleat"})
 		if exitCode != 1 {
@@ -73,8 +75,8 @@ func TestRun(t *testing.T) {
 
 	t.Run("No args, TUI returns empty (quit)", func(t *testing.T) {
 		exitCode = 0
-		UIStart = func(string) (string, map[string]string, error) {
-			return "", nil, nil
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
+			return "", nil, nil, nil
 		}
 		run([]string{"cleat"})
 		if exitCode != 0 {
@@ -84,12 +86,12 @@ func TestRun(t *testing.T) {
 
 	t.Run("No args, TUI returns npm run", func(t *testing.T) {
 		calls := 0
-		UIStart = func(string) (string, map[string]string, error) {
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
 			calls++
 			if calls == 1 {
-				return "npm run test", nil, nil
+				return "npm run test", nil, nil, nil
 			}
-			return "", nil, nil
+			return "", nil, nil, nil
 		}
 		// Should set args to [npm-run test]
 		run([]string{"cleat"})
@@ -97,36 +99,36 @@ func TestRun(t *testing.T) {
 
 	t.Run("No args, TUI returns gcp init", func(t *testing.T) {
 		calls := 0
-		UIStart = func(string) (string, map[string]string, error) {
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
 			calls++
 			if calls == 1 {
-				return "gcp init", nil, nil
+				return "gcp init", nil, nil, nil
 			}
-			return "", nil, nil
+			return "", nil, nil, nil
 		}
 		run([]string{"cleat"})
 	})
 
 	t.Run("No args, TUI returns gcp console", func(t *testing.T) {
 		calls := 0
-		UIStart = func(string) (string, map[string]string, error) {
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
 			calls++
 			if calls == 1 {
-				return "gcp console", nil, nil
+				return "gcp console", nil, nil, nil
 			}
-			return "", nil, nil
+			return "", nil, nil, nil
 		}
 		run([]string{"cleat"})
 	})
 
 	t.Run("No args, TUI returns gcp promote", func(t *testing.T) {
 		calls := 0
-		UIStart = func(string) (string, map[string]string, error) {
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
 			calls++
 			if calls == 1 {
-				return "gcp app-engine promote:svc", nil, nil
+				return "gcp app-engine promote:svc", nil, nil, nil
 			}
-			return "", nil, nil
+			return "", nil, nil, nil
 		}
 		run([]string{"cleat"})
 	})
@@ -170,12 +172,12 @@ workflows:
 		defer os.Chdir(oldWd)
 
 		uiCalls := 0
-		UIStart = func(string) (string, map[string]string, error) {
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
 			uiCalls++
 			if uiCalls == 1 {
-				return "workflow:my-workflow", nil, nil
+				return "workflow:my-workflow", nil, nil, nil
 			}
-			return "", nil, nil // Quit on second call
+			return "", nil, nil, nil // Quit on second call
 		}
 
 		// This will just run the first command of the workflow and then loop
@@ -221,12 +223,12 @@ func TestRunLoop(t *testing.T) {
 	t.Run("Loop for docker up command", func(t *testing.T) {
 		waitCalls = 0
 		calls := 0
-		UIStart = func(string) (string, map[string]string, error) {
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
 			calls++
 			if calls == 1 {
-				return "docker up", nil, nil
+				return "docker up", nil, nil, nil
 			}
-			return "", nil, nil // Quit on second call
+			return "", nil, nil, nil // Quit on second call
 		}
 
 		// Mocking execute is hard, but we can verify calls to UIStart
@@ -244,12 +246,12 @@ func TestRunLoop(t *testing.T) {
 	t.Run("Loop for gcp init command", func(t *testing.T) {
 		waitCalls = 0
 		calls := 0
-		UIStart = func(string) (string, map[string]string, error) {
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
 			calls++
 			if calls == 1 {
-				return "gcp init", nil, nil
+				return "gcp init", nil, nil, nil
 			}
-			return "", nil, nil // Quit on second call
+			return "", nil, nil, nil // Quit on second call
 		}
 
 		run([]string{"cleat"})
@@ -265,12 +267,12 @@ func TestRunLoop(t *testing.T) {
 	t.Run("Loop for docker down command", func(t *testing.T) {
 		waitCalls = 0
 		calls := 0
-		UIStart = func(string) (string, map[string]string, error) {
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
 			calls++
 			if calls == 1 {
-				return "docker down", nil, nil
+				return "docker down", nil, nil, nil
 			}
-			return "", nil, nil
+			return "", nil, nil, nil
 		}
 
 		run([]string{"cleat"})
@@ -286,12 +288,12 @@ func TestRunLoop(t *testing.T) {
 	t.Run("Re-run command with 'r'", func(t *testing.T) {
 		waitCalls = 0
 		uiCalls := 0
-		UIStart = func(string) (string, map[string]string, error) {
+		UIStart = func(string) (string, map[string]string, map[string]bool, error) {
 			uiCalls++
 			if uiCalls == 1 {
-				return "docker up", nil, nil
+				return "docker up", nil, nil, nil
 			}
-			return "", nil, nil
+			return "", nil, nil, nil
 		}
 
 		Wait = func() WaitAction {
@@ -320,6 +322,9 @@ func TestMapSelectedToArgs(t *testing.T) {
 		want     []string
 	}{
 		{"workflow:test", []string{"workflow", "test"}},
+		{"workflow resume:20260101-120000-abcdef", []string{"workflow", "resume", "20260101-120000-abcdef"}},
+		{"command:lint", []string{"command", "lint"}},
+		{"command:web:seed", []string{"command", "web:seed"}},
 		{"docker up", []string{"docker", "up"}},
 		{"docker up:svc", []string{"docker", "up", "svc"}},
 		{"django migrate", []string{"django", "migrate"}},
@@ -393,3 +398,144 @@ func TestWaitForAnyKey(t *testing.T) {
 		}
 	})
 }
+
+// interruptingExecutor behaves as if every command was stopped by Ctrl-C
+type interruptingExecutor struct {
+	executor.ShellExecutor
+	calls int
+}
+
+func (e *interruptingExecutor) Run(ctx context.Context, name string, args ...string) error {
+	e.calls++
+	return &executor.InterruptedError{Signal: os.Interrupt}
+}
+
+func (e *interruptingExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
+	return e.Run(ctx, name, args...)
+}
+
+func TestRun_InterruptedWorkflowRecordedAsCancelled(t *testing.T) {
+	oldUIStart := UIStart
+	oldExit := Exit
+	oldWait := Wait
+	oldExec := executor.Default
+	defer func() {
+		UIStart = oldUIStart
+		Exit = oldExit
+		Wait = oldWait
+		executor.Default = oldExec
+	}()
+
+	tmpDir := t.TempDir()
+	oldUserHomeDir := history.UserHomeDir
+	history.UserHomeDir = func() (string, error) { return tmpDir, nil }
+	defer func() { history.UserHomeDir = oldUserHomeDir }()
+
+	projectDir := filepath.Join(tmpDir, "project")
+	os.Mkdir(projectDir, 0755)
+	os.WriteFile(filepath.Join(projectDir, "cleat.yaml"), []byte(`
+workflows:
+  - name: wf
+    commands:
+      - echo one
+      - echo two
+`), 0644)
+	oldWd, _ := os.Getwd()
+	os.Chdir(projectDir)
+	defer os.Chdir(oldWd)
+
+	mock := &interruptingExecutor{}
+	executor.Default = mock
+	Exit = func(code int) {}
+	Wait = func() WaitAction { return WaitReturn }
+
+	uiCalls := 0
+	UIStart = func(string) (string, map[string]string, map[string]bool, error) {
+		uiCalls++
+		if uiCalls == 1 {
+			return "workflow:wf", nil, nil, nil
+		}
+		return "", nil, nil, nil
+	}
+
+	run([]string{"cleat"})
+
+	if mock.calls != 1 {
+		t.Errorf("expected workflow to stop after the interrupted step, got %d commands run", mock.calls)
+	}
+
+	entries, err := history.Load()
+	if err != nil {
+		t.Fatalf("failed to load history: %v", err)
+	}
+	// The interrupted step, then the run itself
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 history entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if !e.Cancelled || e.Success {
+			t.Errorf("expected entry to be recorded as cancelled, got %+v", e)
+		}
+	}
+	if entries[0].Command != "workflow:wf" || entries[0].WorkflowRunID == "" || entries[1].WorkflowRunID != entries[0].WorkflowRunID {
+		t.Errorf("expected step and run entries to share a run ID, got %+v", entries)
+	}
+}
+
+func TestRun_SecretTUIInputsKeptOutOfHistoryOnEarlyFailure(t *testing.T) {
+	oldUIStart := UIStart
+	oldExit := Exit
+	oldWait := Wait
+	defer func() {
+		UIStart = oldUIStart
+		Exit = oldExit
+		Wait = oldWait
+		preCollectedInputs = nil
+		preCollectedSecrets = nil
+	}()
+
+	tmpDir := t.TempDir()
+	oldUserHomeDir := history.UserHomeDir
+	history.UserHomeDir = func() (string, error) { return tmpDir, nil }
+	defer func() { history.UserHomeDir = oldUserHomeDir }()
+
+	// A config that fails to load, so the command errors before any session
+	// learns which inputs are secret
+	projectDir := filepath.Join(tmpDir, "project")
+	os.Mkdir(projectDir, 0755)
+	os.WriteFile(filepath.Join(projectDir, "cleat.yaml"), []byte("docker: [\n"), 0644)
+	oldWd, _ := os.Getwd()
+	os.Chdir(projectDir)
+	defer os.Chdir(oldWd)
+
+	Exit = func(code int) {}
+	Wait = func() WaitAction { return WaitReturn }
+
+	uiCalls := 0
+	UIStart = func(string) (string, map[string]string, map[string]bool, error) {
+		uiCalls++
+		if uiCalls == 1 {
+			return "workflow:wf", map[string]string{"env": "prod", "token": "hunter2"}, map[string]bool{"token": true}, nil
+		}
+		return "", nil, nil, nil
+	}
+
+	run([]string{"cleat"})
+
+	entries, err := history.Load()
+	if err != nil {
+		t.Fatalf("failed to load history: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(entries))
+	}
+	if entries[0].Success {
+		t.Errorf("expected the command to fail on the broken config")
+	}
+	if _, ok := entries[0].Inputs["token"]; ok {
+		t.Errorf("expected secret input to be left out of history, got %v", entries[0].Inputs)
+	}
+	if entries[0].Inputs["env"] != "prod" {
+		t.Errorf("expected public input to be kept, got %v", entries[0].Inputs)
+	}
+}
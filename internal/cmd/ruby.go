@@ -51,12 +51,15 @@ func newRubySubcommand(action string, short string, strategyAction string) *cobr
 			if len(args) == 1 {
 				cmdStr += ":" + args[0]
 			}
-			sess := createSessionAndMerge(cfg)
+			sess, err := createSessionAndMerge(cfg)
+			if err != nil {
+				return err
+			}
 			s := strategy.GetStrategyForCommand(cmdStr, sess)
 			if s == nil {
 				return fmt.Errorf("no strategy found for %s", cmdStr)
 			}
-			if err := s.Execute(sess); err != nil {
+			if err := executeStrategy(s, sess); err != nil {
 				return fmt.Errorf("ruby %s failed: %w", action, err)
 			}
 			return nil
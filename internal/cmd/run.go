@@ -23,12 +23,15 @@ var runCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		sess := createSessionAndMerge(cfg)
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
 		s := strategy.GetStrategyForCommand("run", sess)
 		if s == nil {
 			return fmt.Errorf("no strategy found for run")
 		}
-		if err := s.Execute(sess); err != nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("run failed: %w", err)
 		}
 		return nil
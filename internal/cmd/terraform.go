@@ -5,6 +5,7 @@ import (
 
 	"github.com/madewithfuture/cleat/internal/config"
 	"github.com/madewithfuture/cleat/internal/strategy"
+	"github.com/madewithfuture/cleat/internal/task"
 	"github.com/spf13/cobra"
 )
 
@@ -34,6 +35,11 @@ func newTerraformSubcommand(action string, short string, tfAction string, tfArgs
 				return fmt.Errorf("terraform not detected or configured")
 			}
 
+			sess, err := createSessionAndMerge(cfg)
+			if err != nil {
+				return err
+			}
+
 			var env string
 			if len(args) > 0 {
 				env = args[0]
@@ -60,7 +66,16 @@ func newTerraformSubcommand(action string, short string, tfAction string, tfArgs
 				if len(validEnvs) == 1 {
 					env = validEnvs[0]
 				} else if len(validEnvs) > 1 {
-					return fmt.Errorf("environment is required, must be one of: %v", validEnvs)
+					req := task.InputRequirement{
+						Key:     "terraform:env",
+						Prompt:  "Select Terraform environment",
+						Kind:    task.InputChoice,
+						Choices: validEnvs,
+					}
+					if err := strategy.CollectInputs(sess, []task.InputRequirement{req}); err != nil {
+						return fmt.Errorf("environment is required: %w", err)
+					}
+					env = sess.Inputs["terraform:env"]
 				} else if cfg.Terraform.UseFolders {
 					return fmt.Errorf("environment is required when using terraform folders")
 				}
@@ -79,9 +94,8 @@ func newTerraformSubcommand(action string, short string, tfAction string, tfArgs
 				}
 			}
 
-			sess := createSessionAndMerge(cfg)
 			s := strategy.NewTerraformStrategy(env, tfAction, tfArgs)
-			if err := s.Execute(sess); err != nil {
+			if err := executeStrategy(s, sess); err != nil {
 				return fmt.Errorf("terraform %s failed: %w", action, err)
 			}
 			return nil
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/madewithfuture/cleat/internal/executor"
@@ -13,12 +15,12 @@ type mockTFExecutor struct {
 	runCalled bool
 }
 
-func (m *mockTFExecutor) Run(name string, args ...string) error {
+func (m *mockTFExecutor) Run(ctx context.Context, name string, args ...string) error {
 	m.runCalled = true
 	return nil
 }
 
-func (m *mockTFExecutor) RunWithDir(dir string, name string, args ...string) error {
+func (m *mockTFExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
 	m.runCalled = true
 	return nil
 }
@@ -64,10 +66,32 @@ func TestTerraformCmd(t *testing.T) {
 		os.WriteFile(filepath.Join(iacDir, "dev", "main.tf"), []byte(""), 0644)
 		os.WriteFile(filepath.Join(iacDir, "prod", "main.tf"), []byte(""), 0644)
 
-		rootCmd.SetArgs([]string{"terraform", "plan"})
+		defer resetInputFlags()
+		rootCmd.SetArgs([]string{"--no-input", "terraform", "plan"})
 		err := rootCmd.Execute()
 		if err == nil {
-			t.Error("expected error for missing env when multiple exist")
+			t.Fatal("expected error for missing env when multiple exist")
+		}
+		if !strings.Contains(err.Error(), "terraform:env") || !strings.Contains(err.Error(), "one of: dev, prod") {
+			t.Errorf("expected error to list env choices, got %v", err)
+		}
+
+		// Env folders relative to the configured terraform dir
+		os.Mkdir(filepath.Join(tmpDir, "dev"), 0755)
+		os.Mkdir(filepath.Join(tmpDir, "prod"), 0755)
+		mock.runCalled = false
+		rootCmd.SetArgs([]string{"--no-input", "--input", "terraform:env=2", "terraform", "plan"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("expected env chosen by position to be accepted, got %v", err)
+		}
+		if !mock.runCalled {
+			t.Error("expected executor.Run to be called")
+		}
+
+		resetInputFlags()
+		rootCmd.SetArgs([]string{"--no-input", "--input", "terraform:env=staging", "terraform", "plan"})
+		if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "not one of") {
+			t.Errorf("expected invalid env choice to be rejected, got %v", err)
 		}
 	})
 
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/strategy"
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run the test suites of every service",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var cfg *config.Config
+		var err error
+		if ConfigPath != "" {
+			cfg, err = config.LoadConfig(ConfigPath)
+		} else {
+			cfg, err = config.LoadDefaultConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		sess, err := createSessionAndMerge(cfg)
+		if err != nil {
+			return err
+		}
+		s := strategy.GetStrategyForCommand("test", sess)
+		if s == nil {
+			return fmt.Errorf("no strategy found for test")
+		}
+		if err := executeStrategy(s, sess); err != nil {
+			return fmt.Errorf("test failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+}
@@ -2,9 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/executor"
 	"github.com/madewithfuture/cleat/internal/history"
+	"github.com/madewithfuture/cleat/internal/logger"
+	"github.com/madewithfuture/cleat/internal/session"
 	"github.com/madewithfuture/cleat/internal/strategy"
 	"github.com/spf13/cobra"
 )
@@ -14,36 +20,167 @@ var workflowCmd = &cobra.Command{
 	Short: "Run a named workflow",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		wfName := args[0]
-		cfg, err := config.LoadDefaultConfig()
+		return runWorkflow(args[0], nil)
+	},
+}
+
+var workflowResumeCmd = &cobra.Command{
+	Use:   "resume [run-id]",
+	Short: "Resume a failed workflow run from the step that failed",
+	Long: `Resume a failed workflow run from the step that failed.
+
+Steps that succeeded in the run are skipped and its recorded inputs are reused.
+Without a run ID the most recent failed run is resumed.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := ""
+		if len(args) > 0 {
+			id = args[0]
+		}
+		entries, err := history.Load()
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return err
 		}
-
-		// Load merged workflows (project + user)
-		workflows, err := history.LoadWorkflows(cfg)
+		run, err := history.FindWorkflowRun(entries, id)
 		if err != nil {
-			// Non-fatal, but we might miss the workflow we're looking for
-			// Proceeding might be okay if it's in cleat.yaml
-		} else {
-			cfg.Workflows = workflows
+			return err
 		}
+		if run.Success {
+			return fmt.Errorf("workflow run '%s' already succeeded", run.ID)
+		}
+		return runWorkflow(run.Workflow, run)
+	},
+}
+
+// workflowHistoryRecorded is set once a workflow command has written its own
+// history entries, so the TUI loop does not add another
+var workflowHistoryRecorded bool
+
+// runWorkflow runs the named workflow, or resumes it when run is set. Each
+// step and the run as a whole are recorded in history under one run ID.
+func runWorkflow(wfName string, run *history.WorkflowRun) error {
+	cfg, err := config.LoadDefaultConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Load merged workflows (project + user)
+	workflows, err := history.LoadWorkflows(cfg)
+	if err != nil {
+		// Non-fatal, but we might miss the workflow we're looking for
+		// Proceeding might be okay if it's in cleat.yaml
+	} else {
+		cfg.Workflows = workflows
+	}
+
+	// Config values are expanded once the parameters they may refer to are known
+	sess, err := newSession(cfg)
+	if err != nil {
+		return err
+	}
 
-		sess := createSessionAndMerge(cfg)
+	// Use the dispatcher to get the workflow strategy
+	s := strategy.GetStrategyForCommand("workflow:"+wfName, sess)
+	if s == nil {
+		return fmt.Errorf("unknown workflow: %s", wfName)
+	}
+	if err := applyWorkflowParams(s, sess); err != nil {
+		return err
+	}
 
-		// Use the dispatcher to get the workflow strategy
-		s := strategy.GetStrategyForCommand("workflow:"+wfName, sess)
-		if s == nil {
-			return fmt.Errorf("unknown workflow: %s", wfName)
+	runID := history.NewWorkflowRunID()
+	ws, _ := s.(*strategy.WorkflowStrategy)
+	if run != nil && ws != nil {
+		runID = run.ID
+		// Inputs given now win over the recorded ones
+		for k, v := range run.Inputs {
+			if _, ok := sess.Inputs[k]; !ok {
+				sess.Inputs[k] = v
+			}
 		}
+		ws.Resume(run.Succeeded)
+		logger.Info("resuming workflow run", map[string]interface{}{"workflow": wfName, "run_id": runID, "succeeded_steps": len(run.Succeeded)})
+	}
+	if err := strategy.CollectInputs(sess, strategy.Parameters(s, sess)); err != nil {
+		return err
+	}
+	if err := strategy.InterpolateConfig(sess); err != nil {
+		return err
+	}
 
-		if err := s.Execute(sess); err != nil {
+	if DryRun != "" || ws == nil {
+		if err := executeStrategy(s, sess); err != nil {
 			return fmt.Errorf("workflow execution failed: %w", err)
 		}
 		return nil
-	},
+	}
+
+	ws.OnStep(func(o strategy.StepOutcome) {
+		if o.Outcome == "skipped" {
+			return
+		}
+		saveHistory(history.HistoryEntry{
+			Timestamp:     time.Now(),
+			Command:       o.Command,
+			Inputs:        sess.PublicInputs(sess.Inputs),
+			Success:       o.Outcome == "success",
+			Cancelled:     executor.IsCancelled(o.Err),
+			WorkflowRunID: runID,
+			Step:          o.Index + 1,
+		})
+	})
+
+	err = ws.Execute(sess)
+	saveHistory(history.HistoryEntry{
+		Timestamp:     time.Now(),
+		Command:       "workflow:" + wfName,
+		Inputs:        sess.PublicInputs(sess.Inputs),
+		Success:       err == nil,
+		Cancelled:     executor.IsCancelled(err),
+		WorkflowRunID: runID,
+	})
+	workflowHistoryRecorded = true
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Resume with: cleat workflow resume %s\n", runID)
+		return fmt.Errorf("workflow execution failed: %w", err)
+	}
+	return nil
+}
+
+func saveHistory(entry history.HistoryEntry) {
+	if err := history.Save(entry); err != nil {
+		logger.Warn("failed to save workflow history", map[string]interface{}{"command": entry.Command, "error": err.Error()})
+	}
+}
+
+// WorkflowParams holds raw --param name=value pairs
+var WorkflowParams []string
+
+// applyWorkflowParams stores --param values as session inputs, rejecting
+// names the workflow does not declare
+func applyWorkflowParams(s strategy.Strategy, sess *session.Session) error {
+	declared := make(map[string]bool)
+	if p, ok := s.(strategy.Parameterized); ok {
+		for _, req := range p.Params() {
+			declared[req.Key] = true
+		}
+	}
+	for _, pair := range WorkflowParams {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid --param '%s', expected name=value", pair)
+		}
+		if !declared[name] {
+			return fmt.Errorf("workflow has no parameter '%s'", name)
+		}
+		sess.Inputs[name] = value
+	}
+	return nil
 }
 
 func init() {
+	workflowCmd.Flags().StringArrayVar(&WorkflowParams, "param", nil, "set a workflow parameter as name=value (repeatable)")
+	workflowCmd.AddCommand(workflowResumeCmd)
 	rootCmd.AddCommand(workflowCmd)
 }
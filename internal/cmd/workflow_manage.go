@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/executor"
+	"github.com/madewithfuture/cleat/internal/history"
+	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/strategy"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var workflowListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List workflows and where they are defined",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, workflows, err := loadWorkflowSources()
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		if len(workflows) == 0 {
+			fmt.Fprintln(out, "No workflows defined")
+			return nil
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tSTEPS\tSOURCE")
+		for _, wf := range workflows {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", wf.ID, wf.Name, len(wf.Commands)+len(wf.Finally), sourceLabel(wf))
+		}
+		return w.Flush()
+	},
+}
+
+var workflowShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Print a workflow's definition",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, workflows, err := loadWorkflowSources()
+		if err != nil {
+			return err
+		}
+		wf := findSourcedWorkflow(workflows, args[0])
+		if wf == nil {
+			return fmt.Errorf("unknown workflow: %s", args[0])
+		}
+		data, err := yaml.Marshal(wf.Workflow)
+		if err != nil {
+			return fmt.Errorf("failed to marshal workflow: %w", err)
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "# source: %s\n", sourceLabel(*wf))
+		_, err = out.Write(data)
+		return err
+	},
+}
+
+// Flags of workflow add
+var (
+	workflowAddUser  bool
+	workflowAddForce bool
+)
+
+var workflowAddCmd = &cobra.Command{
+	Use:   "add [name] [command]...",
+	Short: "Create a workflow that runs the given commands in order",
+	Long: `Create a workflow that runs the given commands in order.
+
+The workflow is saved to cleat.workflows.yaml, or with --user to your own
+workflow file for this project.`,
+	Example: `  cleat workflow add deploy build "terraform apply:prod" "gcp app-engine deploy"`,
+	Args:    cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := history.ValidateWorkflowName(name); err != nil {
+			return err
+		}
+		wf := config.Workflow{ID: history.Slugify(name), Name: name, Commands: config.PlainSteps(args[1:]...)}
+		if wf.ID == "" {
+			return fmt.Errorf("workflow name '%s' has no letters or digits", name)
+		}
+
+		_, workflows, err := loadWorkflowSources()
+		if err != nil {
+			return err
+		}
+		if existing := findSourcedWorkflow(workflows, wf.ID); existing != nil && !workflowAddForce {
+			return fmt.Errorf("workflow '%s' already exists in %s (use --force to replace it)", existing.ID, sourceLabel(*existing))
+		}
+
+		if workflowAddUser {
+			err = history.SaveWorkflowToUser(wf)
+		} else {
+			err = history.SaveWorkflowToProject(wf)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Added workflow '%s' (%s)\n", wf.Name, wf.ID)
+		return nil
+	},
+}
+
+var workflowRmCmd = &cobra.Command{
+	Use:     "rm [name]",
+	Aliases: []string{"remove"},
+	Short:   "Delete a workflow from the project and user workflow files",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, workflows, err := loadWorkflowSources()
+		if err != nil {
+			return err
+		}
+		wf := findSourcedWorkflow(workflows, args[0])
+		if wf == nil {
+			return fmt.Errorf("unknown workflow: %s", args[0])
+		}
+		if wf.Source == history.SourceConfig {
+			return fmt.Errorf("workflow '%s' is defined in %s; remove it there", wf.Name, sourceLabel(*wf))
+		}
+		if err := history.DeleteWorkflow(wf.ID); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed workflow '%s'\n", wf.Name)
+		return nil
+	},
+}
+
+var workflowValidateCmd = &cobra.Command{
+	Use:   "validate [name]",
+	Short: "Check that every step of the workflows resolves",
+	Long: `Check that every step of the workflows resolves, without running anything.
+
+Unknown commands, workflow cycles and malformed conditions are errors. Shell
+steps whose program is not on PATH are reported as warnings.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, workflows, err := loadWorkflowSources()
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 {
+			wf := findSourcedWorkflow(workflows, args[0])
+			if wf == nil {
+				return fmt.Errorf("unknown workflow: %s", args[0])
+			}
+			workflows = []history.SourcedWorkflow{*wf}
+		}
+
+		invalid := 0
+		for _, wf := range workflows {
+			if !validateWorkflow(cmd.OutOrStdout(), cfg, wf) {
+				invalid++
+			}
+		}
+		if invalid > 0 {
+			return fmt.Errorf("%d of %d workflow(s) are invalid", invalid, len(workflows))
+		}
+		return nil
+	},
+}
+
+// validateWorkflow reports the problems of one workflow to out and returns
+// whether it can run
+func validateWorkflow(out io.Writer, cfg *config.Config, wf history.SourcedWorkflow) bool {
+	sess := session.NewSession(cfg, executor.Default)
+	sess.NoInput = true
+	s := strategy.NewWorkflowStrategyFromConfig(wf.Workflow)
+	errs := s.Validate(sess)
+
+	var warnings []string
+	for _, step := range append(append([]config.WorkflowStep{}, wf.Commands...), wf.Finally...) {
+		command := strategy.ExpandParams(step.Run, wf.Params, sess.Inputs)
+		if _, ok := strategy.GetStrategyForCommand(command, sess).(*strategy.PassthroughStrategy); !ok {
+			continue
+		}
+		fields := strings.Fields(command)
+		if len(fields) == 0 || strings.Contains(fields[0], "=") {
+			continue
+		}
+		if _, err := exec.LookPath(fields[0]); err != nil {
+			warnings = append(warnings, fmt.Sprintf("'%s' is not a cleat command and '%s' was not found on PATH", command, fields[0]))
+		}
+	}
+
+	if len(errs) == 0 {
+		fmt.Fprintf(out, "✓ %s\n", wf.Name)
+	} else {
+		fmt.Fprintf(out, "✘ %s (%s)\n", wf.Name, sourceLabel(wf))
+	}
+	for _, err := range errs {
+		fmt.Fprintf(out, "    error: %v\n", err)
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(out, "    warning: %s\n", w)
+	}
+	return len(errs) == 0
+}
+
+// loadWorkflowSources loads the config and every workflow visible to it
+func loadWorkflowSources() (*config.Config, []history.SourcedWorkflow, error) {
+	var cfg *config.Config
+	var err error
+	if ConfigPath != "" {
+		cfg, err = config.LoadConfig(ConfigPath)
+	} else {
+		cfg, err = config.LoadDefaultConfig()
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	workflows, err := history.LoadWorkflowSources(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg.Workflows = nil
+	for _, wf := range workflows {
+		cfg.Workflows = append(cfg.Workflows, wf.Workflow)
+	}
+	return cfg, workflows, nil
+}
+
+func findSourcedWorkflow(workflows []history.SourcedWorkflow, idOrName string) *history.SourcedWorkflow {
+	for i, wf := range workflows {
+		if wf.ID == idOrName || wf.Name == idOrName {
+			return &workflows[i]
+		}
+	}
+	return nil
+}
+
+// sourceLabel describes where a workflow is defined
+func sourceLabel(wf history.SourcedWorkflow) string {
+	switch wf.Source {
+	case history.SourceConfig:
+		if wf.Path == "" {
+			return "cleat.yaml"
+		}
+		return filepath.Base(wf.Path)
+	case history.SourceProject:
+		return "project file " + filepath.Base(wf.Path)
+	default:
+		return "user file " + wf.Path
+	}
+}
+
+func init() {
+	workflowAddCmd.Flags().BoolVar(&workflowAddUser, "user", false, "save to your user workflow file instead of cleat.workflows.yaml")
+	workflowAddCmd.Flags().BoolVar(&workflowAddForce, "force", false, "replace an existing workflow with the same ID")
+	workflowCmd.AddCommand(workflowListCmd, workflowShowCmd, workflowAddCmd, workflowRmCmd, workflowValidateCmd)
+}
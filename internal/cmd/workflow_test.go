@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/madewithfuture/cleat/internal/config"
 	"github.com/madewithfuture/cleat/internal/executor"
+	"github.com/madewithfuture/cleat/internal/history"
+	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/strategy"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,13 +21,13 @@ type MockExecutor struct {
 	LastCmd   string
 }
 
-func (e *MockExecutor) Run(name string, args ...string) error {
+func (e *MockExecutor) Run(ctx context.Context, name string, args ...string) error {
 	e.RunCalled = true
 	e.LastCmd = name
 	return nil
 }
 
-func (e *MockExecutor) RunWithDir(dir string, name string, args ...string) error {
+func (e *MockExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
 	e.RunCalled = true
 	e.LastCmd = name
 	return nil
@@ -39,6 +45,10 @@ func TestRunWorkflowCmd_ExternalFile(t *testing.T) {
 	os.Chdir(tmpDir)
 	defer os.Chdir(oldWd)
 
+	oldUserHomeDir := history.UserHomeDir
+	history.UserHomeDir = func() (string, error) { return tmpDir, nil }
+	defer func() { history.UserHomeDir = oldUserHomeDir }()
+
 	// 1. Create a basic cleat.yaml (without the workflow)
 	os.WriteFile("cleat.yaml", []byte("version: 1\n"), 0644)
 
@@ -46,7 +56,7 @@ func TestRunWorkflowCmd_ExternalFile(t *testing.T) {
 	externalWf := []config.Workflow{
 		{
 			Name:     "external-wf",
-			Commands: []string{"echo test"},
+			Commands: config.PlainSteps("echo test"),
 		},
 	}
 	data, _ := yaml.Marshal(externalWf)
@@ -74,3 +84,218 @@ func TestRunWorkflowCmd_ExternalFile(t *testing.T) {
 		t.Error("External workflow command was not executed")
 	}
 }
+
+func TestApplyWorkflowParams(t *testing.T) {
+	defer func() { WorkflowParams = nil }()
+	cfg := &config.Config{
+		Workflows: []config.Workflow{
+			{Name: "deploy", Params: []config.WorkflowParam{{Name: "env"}}, Commands: config.PlainSteps("echo ${env}")},
+		},
+	}
+	sess := session.NewSession(cfg, nil)
+	s := strategy.GetStrategyForCommand("workflow:deploy", sess)
+
+	WorkflowParams = []string{"env=prod"}
+	if err := applyWorkflowParams(s, sess); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sess.Inputs["env"] != "prod" {
+		t.Errorf("expected env=prod, got %v", sess.Inputs)
+	}
+
+	for _, bad := range []string{"region=eu", "env"} {
+		WorkflowParams = []string{bad}
+		if err := applyWorkflowParams(s, sess); err == nil {
+			t.Errorf("expected error for --param %s", bad)
+		}
+	}
+}
+
+// flakyExecutor fails the first command containing fail, then lets it pass
+type flakyExecutor struct {
+	executor.ShellExecutor
+	fail   string
+	failed bool
+	ran    []string
+}
+
+func (e *flakyExecutor) Run(ctx context.Context, name string, args ...string) error {
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	e.ran = append(e.ran, cmd)
+	if !e.failed && strings.Contains(cmd, e.fail) {
+		e.failed = true
+		return fmt.Errorf("exit status 1")
+	}
+	return nil
+}
+
+func (e *flakyExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
+	return e.Run(ctx, name, args...)
+}
+
+func TestWorkflowResumeCmd(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	oldUserHomeDir := history.UserHomeDir
+	history.UserHomeDir = func() (string, error) { return tmpDir, nil }
+	defer func() { history.UserHomeDir = oldUserHomeDir }()
+
+	os.WriteFile("cleat.yaml", []byte(`
+workflows:
+  - name: deploy
+    commands:
+      - echo build
+      - echo flaky-push
+      - echo announce
+`), 0644)
+
+	mock := &flakyExecutor{fail: "flaky-push"}
+	oldDefault := executor.Default
+	executor.Default = mock
+	defer func() { executor.Default = oldDefault }()
+
+	rootCmd.SetArgs([]string{"workflow", "deploy"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected the first run to fail")
+	}
+
+	entries, _ := history.Load()
+	if len(entries) != 3 || entries[0].Command != "workflow:deploy" || entries[0].Success {
+		t.Fatalf("expected two step entries and a failed run entry, got %+v", entries)
+	}
+	runID := entries[0].WorkflowRunID
+
+	mock.ran = nil
+	rootCmd.SetArgs([]string{"workflow", "resume"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+
+	expected := []string{"sh -c echo flaky-push", "sh -c echo announce"}
+	if strings.Join(mock.ran, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected resume to run %v, got %v", expected, mock.ran)
+	}
+
+	entries, _ = history.Load()
+	if !entries[0].Success || entries[0].WorkflowRunID != runID {
+		t.Errorf("expected the resumed run to succeed under run ID %s, got %+v", runID, entries[0])
+	}
+
+	rootCmd.SetArgs([]string{"workflow", "resume", runID})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "already succeeded") {
+		t.Errorf("expected resuming a successful run to fail, got %v", err)
+	}
+}
+
+func TestWorkflowManagementCmds(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	oldUserHomeDir := history.UserHomeDir
+	history.UserHomeDir = func() (string, error) { return tmpDir, nil }
+	defer func() { history.UserHomeDir = oldUserHomeDir }()
+
+	os.WriteFile("cleat.yaml", []byte(`
+workflows:
+  - name: prepare
+    commands:
+      - echo prepare
+`), 0644)
+
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	defer rootCmd.SetOut(nil)
+	defer func() { workflowAddUser, workflowAddForce = false, false }()
+
+	execute := func(args ...string) error {
+		out.Reset()
+		rootCmd.SetArgs(args)
+		return rootCmd.Execute()
+	}
+
+	if err := execute("workflow", "add", "Ship It", "workflow:prepare", "echo ship"); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if err := execute("workflow", "add", "ship-it", "echo again"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected adding a duplicate to fail, got %v", err)
+	}
+	if err := execute("workflow", "add", "mine", "echo mine", "--user"); err != nil {
+		t.Fatalf("add --user failed: %v", err)
+	}
+	if err := execute("workflow", "add", "Show", "echo show"); err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Errorf("expected a workflow named like a subcommand to be refused, got %v", err)
+	}
+
+	if err := execute("workflow", "list"); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	for _, want := range []string{"ship-it", "Ship It", "project file cleat.workflows.yaml", "prepare", "cleat.yaml", "mine", "user file "} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected list output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	if err := execute("workflow", "show", "ship-it"); err != nil {
+		t.Fatalf("show failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "# source: project file") || !strings.Contains(out.String(), "- workflow:prepare") {
+		t.Errorf("unexpected show output:\n%s", out.String())
+	}
+
+	if err := execute("workflow", "validate"); err != nil {
+		t.Fatalf("expected workflows to be valid, got %v:\n%s", err, out.String())
+	}
+
+	execute("workflow", "add", "loop", "workflow:loop")
+	execute("workflow", "add", "broken", "workflow:missing", "echo ok")
+	err := execute("workflow", "validate")
+	if err == nil || !strings.Contains(err.Error(), "2 of 5") {
+		t.Errorf("expected two invalid workflows, got %v", err)
+	}
+	if !strings.Contains(out.String(), "cycle detected: loop -> loop") || !strings.Contains(out.String(), "unknown command: workflow:missing") {
+		t.Errorf("expected cycle and unknown command to be reported, got:\n%s", out.String())
+	}
+
+	if err := execute("workflow", "rm", "prepare"); err == nil || !strings.Contains(err.Error(), "remove it there") {
+		t.Errorf("expected removing a cleat.yaml workflow to fail, got %v", err)
+	}
+	if err := execute("workflow", "rm", "ship-it"); err != nil {
+		t.Fatalf("rm failed: %v", err)
+	}
+	workflows, _ := history.LoadWorkflows(nil)
+	for _, wf := range workflows {
+		if wf.ID == "ship-it" {
+			t.Error("expected ship-it to be removed")
+		}
+	}
+}
+
+func TestReservedWorkflowNames(t *testing.T) {
+	var names []string
+	for _, c := range workflowCmd.Commands() {
+		names = append(names, c.Name())
+		names = append(names, c.Aliases...)
+	}
+	for _, name := range names {
+		if !history.IsReservedWorkflowName(name) && name != "help" && name != "completion" {
+			t.Errorf("expected the workflow subcommand '%s' to be a reserved workflow name", name)
+		}
+	}
+}
+
+func TestWorkflowValidate_UnknownProgramIsAWarning(t *testing.T) {
+	cfg := &config.Config{}
+	wf := history.SourcedWorkflow{Workflow: config.Workflow{Name: "typo", Commands: config.PlainSteps("cleat-no-such-program --flag")}}
+	var out strings.Builder
+	if !validateWorkflow(&out, cfg, wf) {
+		t.Fatalf("expected workflow to be valid, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "warning: 'cleat-no-such-program --flag' is not a cleat command") {
+		t.Errorf("expected a PATH warning, got:\n%s", out.String())
+	}
+}
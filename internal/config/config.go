@@ -2,14 +2,14 @@ package config
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/madewithfuture/cleat/internal/config/schema"
-	"github.com/madewithfuture/cleat/internal/detector"
 	"github.com/madewithfuture/cleat/internal/logger"
-	"gopkg.in/yaml.v3"
 )
 
 const LatestVersion = 1
@@ -25,6 +25,19 @@ type RubyConfig = schema.RubyConfig
 type GCPConfig = schema.GCPConfig
 type TerraformConfig = schema.TerraformConfig
 type Workflow = schema.Workflow
+type WorkflowParam = schema.WorkflowParam
+type WorkflowStep = schema.WorkflowStep
+type CommandConfig = schema.CommandConfig
+type CommandRun = schema.CommandRun
+type CommandInput = schema.CommandInput
+
+// PackageManagers lists the accepted values of python.package_manager
+var PackageManagers = schema.PackageManagers
+
+// PlainSteps returns a workflow step for each command, as a plain string list would load
+func PlainSteps(commands ...string) []WorkflowStep {
+	return schema.PlainSteps(commands...)
+}
 
 // FindProjectRoot searches upwards from the current directory for a cleat.yaml/cleat.yml file, or other project markers like package.json, go.mod, etc.
 func FindProjectRoot() string {
@@ -153,7 +166,24 @@ func LoadConfigWithDefault(path string) (*Config, error) {
 }
 
 func parseConfig(data []byte, path string) (*Config, error) {
-	var cfg Config
+	return (&loader{local: true}).parse(data, path)
+}
+
+func (l *loader) parse(data []byte, path string) (*Config, error) {
+	l.root = filepath.Dir(path)
+	cfg, err := l.loadFile(data, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if l.local {
+		if err := l.mergeLocal(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+	if len(l.problems) > 0 && !l.thorough {
+		return nil, errors.Join(l.problems...)
+	}
+
 	var absErr error
 	cfg.SourcePath, absErr = filepath.Abs(path)
 	if absErr != nil {
@@ -161,29 +191,132 @@ func parseConfig(data []byte, path string) (*Config, error) {
 		cfg.SourcePath = path
 	}
 
-	err := yaml.Unmarshal(data, &cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config from %s: %w", path, err)
+	cfg.Inputs = make(map[string]string)
+
+	if l.origins != nil {
+		l.declare(cfg)
+	}
+	if l.skipDetection {
+		return cfg, nil
 	}
 
-	if cfg.Version == 0 {
-		cfg.Version = LatestVersion
+	baseDir := filepath.Dir(path)
+	if err := l.detect(baseDir, cfg); err != nil {
+		return nil, fmt.Errorf("auto-detection failed during config load of %s: %w", path, err)
 	}
 
-	if cfg.Version > LatestVersion || cfg.Version < 1 {
-		return nil, fmt.Errorf("unrecognized configuration version in %s: %d", path, cfg.Version)
+	if err := validateCommands(cfg); err != nil {
+		return nil, fmt.Errorf("invalid commands in %s: %w", path, err)
 	}
 
-	if cfg.Envs != nil && len(cfg.Envs) == 0 {
-		return nil, fmt.Errorf("envs in %s must have at least one item if provided", path)
+	if err := validateServiceDependencies(cfg); err != nil {
+		return nil, fmt.Errorf("invalid service dependencies in %s: %w", path, err)
 	}
 
-	cfg.Inputs = make(map[string]string)
+	return cfg, nil
+}
 
-	baseDir := filepath.Dir(path)
-	if err := detector.DetectAll(baseDir, &cfg); err != nil {
-		return nil, fmt.Errorf("auto-detection failed during config load of %s: %w", path, err)
+// validateCommands checks that user-defined commands are named, runnable,
+// unique within their scope and only depend on commands that exist
+func validateCommands(cfg *Config) error {
+	check := func(scope string, cmds []CommandConfig, svc *ServiceConfig) error {
+		seen := make(map[string]bool)
+		for _, c := range cmds {
+			if c.Name == "" {
+				return fmt.Errorf("%s: command without a name", scope)
+			}
+			if seen[c.Name] {
+				return fmt.Errorf("%s: duplicate command '%s'", scope, c.Name)
+			}
+			seen[c.Name] = true
+			if c.Run.IsEmpty() {
+				return fmt.Errorf("%s: command '%s' has nothing to run", scope, c.Name)
+			}
+			for _, dep := range c.DependsOn {
+				if _, _, ok := cfg.FindCommand(svc, dep); !ok {
+					return fmt.Errorf("%s: command '%s' depends on unknown command '%s'", scope, c.Name, dep)
+				}
+			}
+			for _, in := range c.Inputs {
+				if in.Key == "" {
+					return fmt.Errorf("%s: command '%s' has an input without a key", scope, c.Name)
+				}
+				if in.Type != "" && !contains(schema.InputTypes, in.Type) {
+					return fmt.Errorf("%s: input '%s' of command '%s' has unknown type '%s'", scope, in.Key, c.Name, in.Type)
+				}
+				if in.Type == "choice" && len(in.Choices) == 0 {
+					return fmt.Errorf("%s: choice input '%s' of command '%s' needs choices", scope, in.Key, c.Name)
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := check("commands", cfg.Commands, nil); err != nil {
+		return err
+	}
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		if err := check(fmt.Sprintf("services.%s.commands", svc.Name), svc.Commands, svc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateServiceDependencies checks that services only depend on services
+// that exist and reports the first dependency cycle with its full path
+func validateServiceDependencies(cfg *Config) error {
+	byName := make(map[string]*ServiceConfig, len(cfg.Services))
+	for i := range cfg.Services {
+		byName[cfg.Services[i].Name] = &cfg.Services[i]
+	}
+	for _, svc := range cfg.Services {
+		for _, dep := range svc.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return fmt.Errorf("service '%s' depends on unknown service '%s'", svc.Name, dep)
+			}
+		}
 	}
 
-	return &cfg, nil
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			start := 0
+			for i, p := range path {
+				if p == name {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("service dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range byName[name].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
+	}
+	for _, svc := range cfg.Services {
+		if err := visit(svc.Name); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -1391,3 +1391,111 @@ func TestLoadConfigErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadConfigCommands(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("Valid", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "commands.yaml")
+		os.WriteFile(path, []byte(`
+version: 1
+commands:
+  - name: lint
+    description: Lint everything
+    run: [golangci-lint, run]
+    env:
+      GOFLAGS: -mod=mod
+  - name: ci
+    run: echo done
+    depends_on: [lint, web:seed]
+services:
+  - name: web
+    dir: ./web
+    commands:
+      - name: seed
+        run: ./seed.sh
+        dir: scripts
+        inputs:
+          - key: db
+            type: choice
+            choices: [dev, test]
+`), 0644)
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		if len(cfg.Commands) != 2 || cfg.Commands[0].Run.Args[0] != "golangci-lint" || cfg.Commands[1].Run.Shell != "echo done" {
+			t.Errorf("unexpected commands: %+v", cfg.Commands)
+		}
+		if cfg.Commands[0].Env["GOFLAGS"] != "-mod=mod" {
+			t.Errorf("expected env to be loaded, got %v", cfg.Commands[0].Env)
+		}
+		var web *ServiceConfig
+		for i := range cfg.Services {
+			if cfg.Services[i].Name == "web" {
+				web = &cfg.Services[i]
+			}
+		}
+		if web == nil || len(web.Commands) != 1 || web.Commands[0].Inputs[0].Choices[1] != "test" {
+			t.Errorf("expected web service command with inputs, got %+v", web)
+		}
+	})
+
+	errorCases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"MissingName", "commands:\n  - run: ls\n", "command without a name"},
+		{"Duplicate", "commands:\n  - {name: a, run: ls}\n  - {name: a, run: pwd}\n", "duplicate command 'a'"},
+		{"NothingToRun", "commands:\n  - name: a\n", "has nothing to run"},
+		{"UnknownDependency", "commands:\n  - {name: a, run: ls, depends_on: [b]}\n", "depends on unknown command 'b'"},
+		{"UnknownInputType", "commands:\n  - name: a\n    run: ls\n    inputs: [{key: k, type: number}]\n", "unknown type 'number'"},
+		{"ChoiceWithoutChoices", "commands:\n  - name: a\n    run: ls\n    inputs: [{key: k, type: choice}]\n", "needs choices"},
+		{"ServiceScope", "services:\n  - name: web\n    commands:\n      - {name: a, run: ls, depends_on: [missing]}\n", "services.web.commands"},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, tt.name+".yaml")
+			os.WriteFile(path, []byte("version: 1\n"+tt.content), 0644)
+			_, err := LoadConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigServiceDependencies(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"Valid", "services:\n  - {name: lib, dir: .}\n  - {name: api, dir: ., depends_on: [lib]}\n", ""},
+		{"UnknownService", "services:\n  - {name: api, dir: ., depends_on: [db]}\n", "service 'api' depends on unknown service 'db'"},
+		{"Cycle", "services:\n  - {name: web, dir: ., depends_on: [api]}\n  - {name: api, dir: ., depends_on: [lib]}\n  - {name: lib, dir: ., depends_on: [web]}\n", "service dependency cycle: web -> api -> lib -> web"},
+		{"SelfDependency", "services:\n  - {name: api, dir: ., depends_on: [api]}\n", "service dependency cycle: api -> api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, tt.name+".yaml")
+			os.WriteFile(path, []byte("version: 1\n"+tt.content), 0644)
+			cfg, err := LoadConfig(path)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("LoadConfig failed: %v", err)
+				}
+				if deps := cfg.ServiceDependencies("api"); len(deps) != 1 || deps[0] != "lib" {
+					t.Errorf("expected api to depend on lib, got %v", deps)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A config file can be layered over a base file with extends and can add
+// other files with include. The merge rules are:
+//
+//   - extends: the extending file wins. Services, workflows and commands
+//     replace the base entry with the same name (id for workflows) and are
+//     added otherwise; envs replace the base list when set; the fields of
+//     google_cloud_platform and terraform override the base fields that are
+//     set; docker is on when either file turns it on.
+//   - include: included files add services, workflows and commands, and a
+//     name declared twice is an error; envs are added to the list; the fields
+//     of google_cloud_platform and terraform are only taken when the
+//     including file leaves them empty.
+//
+// Relative dirs and app_yaml paths in every file are resolved against that
+// file's directory, so each service records its SourcePath and its dir is
+// rewritten relative to the directory of the file that was loaded.
+
+// loadFile decodes the config file at path together with the files it
+// extends and includes. chain holds the files being loaded, to report cycles.
+func (l *loader) loadFile(data []byte, path string, chain []string) (*Config, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	for i, p := range chain {
+		if p == absPath {
+			cycle := append(append([]string{}, chain[i:]...), absPath)
+			return nil, fmt.Errorf("config include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	chain = append(chain, absPath)
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlError(path, err)
+	}
+	var cfg Config
+	var root *yaml.Node
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+		l.checkFields(path, root, reflect.TypeOf(cfg), "")
+		if err := root.Decode(&cfg); err != nil {
+			return nil, yamlError(path, err)
+		}
+	}
+	l.files = append(l.files, loadedFile{path: absPath, root: root})
+	if cfg.Version == 0 {
+		cfg.Version = LatestVersion
+	}
+	if cfg.Version > LatestVersion || cfg.Version < 1 {
+		return nil, fmt.Errorf("unrecognized configuration version in %s: %d", path, cfg.Version)
+	}
+	if cfg.Envs != nil && len(cfg.Envs) == 0 {
+		return nil, fmt.Errorf("envs in %s must have at least one item if provided", path)
+	}
+	for i := range cfg.Services {
+		cfg.Services[i].SourcePath = absPath
+	}
+	for i := range cfg.Workflows {
+		cfg.Workflows[i].SourcePath = absPath
+	}
+	l.checkFile(path, root, &cfg)
+
+	dir := filepath.Dir(absPath)
+	for _, pattern := range cfg.Include {
+		paths, err := includePaths(dir, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include '%s' in %s: %w", pattern, path, err)
+		}
+		for _, p := range paths {
+			if p == absPath {
+				continue
+			}
+			inc, err := l.loadReferencedFile(p, chain)
+			if err != nil {
+				return nil, err
+			}
+			rebase(inc, filepath.Dir(p), dir)
+			if err := include(&cfg, inc); err != nil {
+				return nil, fmt.Errorf("failed to include %s in %s: %w", p, path, err)
+			}
+		}
+	}
+
+	if cfg.Extends == "" {
+		return &cfg, nil
+	}
+	basePath, err := expandHome(cfg.Extends)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extends '%s' in %s: %w", cfg.Extends, path, err)
+	}
+	if !filepath.IsAbs(basePath) {
+		basePath = filepath.Join(dir, basePath)
+	}
+	base, err := l.loadReferencedFile(basePath, chain)
+	if err != nil {
+		return nil, err
+	}
+	rebase(base, filepath.Dir(basePath), dir)
+	return extend(base, &cfg), nil
+}
+
+func (l *loader) loadReferencedFile(path string, chain []string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return l.loadFile(data, path, chain)
+}
+
+// includePaths returns the files matching pattern, relative to dir, in
+// sorted order. A pattern without wildcards must name an existing file.
+func includePaths(dir, pattern string) ([]string, error) {
+	pattern, err := expandHome(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		if _, err := os.Stat(pattern); err != nil {
+			return nil, err
+		}
+		return []string{pattern}, nil
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// rebase rewrites the relative dirs and app_yaml paths of a config file in
+// from so they are relative to to instead. The dirs of service commands and a
+// service's dockerfile are relative to the service dir, so they move with it.
+func rebase(cfg *Config, from, to string) {
+	if from == to {
+		return
+	}
+	move := func(dir string) string {
+		if filepath.IsAbs(dir) {
+			return dir
+		}
+		p := filepath.Join(from, dir)
+		if rel, err := filepath.Rel(to, p); err == nil {
+			return rel
+		}
+		return p
+	}
+	for i := range cfg.Services {
+		cfg.Services[i].Dir = move(cfg.Services[i].Dir)
+		if cfg.Services[i].AppYaml != "" {
+			cfg.Services[i].AppYaml = move(cfg.Services[i].AppYaml)
+		}
+	}
+	if cfg.AppYaml != "" {
+		cfg.AppYaml = move(cfg.AppYaml)
+	}
+	for i := range cfg.Commands {
+		cfg.Commands[i].Dir = move(cfg.Commands[i].Dir)
+	}
+	if cfg.Terraform != nil && cfg.Terraform.Dir != "" {
+		cfg.Terraform.Dir = move(cfg.Terraform.Dir)
+	}
+}
+
+// include adds the services, workflows and commands of inc to cfg
+func include(cfg, inc *Config) error {
+	for _, svc := range inc.Services {
+		for _, existing := range cfg.Services {
+			if existing.Name == svc.Name {
+				return fmt.Errorf("service '%s' is already declared in %s", svc.Name, existing.SourcePath)
+			}
+		}
+		cfg.Services = append(cfg.Services, svc)
+	}
+	for _, wf := range inc.Workflows {
+		for _, existing := range cfg.Workflows {
+			if existing.ID == wf.ID {
+				return fmt.Errorf("workflow '%s' is already declared", wf.ID)
+			}
+		}
+		cfg.Workflows = append(cfg.Workflows, wf)
+	}
+	for _, c := range inc.Commands {
+		for _, existing := range cfg.Commands {
+			if existing.Name == c.Name {
+				return fmt.Errorf("command '%s' is already declared", c.Name)
+			}
+		}
+		cfg.Commands = append(cfg.Commands, c)
+	}
+
+	for _, env := range inc.Envs {
+		if !contains(cfg.Envs, env) {
+			cfg.Envs = append(cfg.Envs, env)
+		}
+	}
+	cfg.Docker = cfg.Docker || inc.Docker
+	if cfg.AppYaml == "" {
+		cfg.AppYaml = inc.AppYaml
+	}
+	cfg.GoogleCloudPlatform = mergeGCP(inc.GoogleCloudPlatform, cfg.GoogleCloudPlatform)
+	cfg.Terraform = mergeTerraform(inc.Terraform, cfg.Terraform)
+	return nil
+}
+
+// extend returns cfg layered over base
+func extend(base, cfg *Config) *Config {
+	merged := *cfg
+	merged.Services = nil
+	for _, svc := range base.Services {
+		if i := findService(cfg.Services, svc.Name); i >= 0 {
+			svc = cfg.Services[i]
+		}
+		merged.Services = append(merged.Services, svc)
+	}
+	for _, svc := range cfg.Services {
+		if findService(base.Services, svc.Name) < 0 {
+			merged.Services = append(merged.Services, svc)
+		}
+	}
+
+	merged.Workflows = nil
+	for _, wf := range base.Workflows {
+		for _, own := range cfg.Workflows {
+			if own.ID == wf.ID {
+				wf = own
+			}
+		}
+		merged.Workflows = append(merged.Workflows, wf)
+	}
+	for _, wf := range cfg.Workflows {
+		found := false
+		for _, b := range base.Workflows {
+			found = found || b.ID == wf.ID
+		}
+		if !found {
+			merged.Workflows = append(merged.Workflows, wf)
+		}
+	}
+
+	merged.Commands = nil
+	for _, c := range base.Commands {
+		for _, own := range cfg.Commands {
+			if own.Name == c.Name {
+				c = own
+			}
+		}
+		merged.Commands = append(merged.Commands, c)
+	}
+	for _, c := range cfg.Commands {
+		found := false
+		for _, b := range base.Commands {
+			found = found || b.Name == c.Name
+		}
+		if !found {
+			merged.Commands = append(merged.Commands, c)
+		}
+	}
+
+	if merged.Envs == nil {
+		merged.Envs = base.Envs
+	}
+	merged.Docker = base.Docker || cfg.Docker
+	if merged.AppYaml == "" {
+		merged.AppYaml = base.AppYaml
+	}
+	merged.GoogleCloudPlatform = mergeGCP(base.GoogleCloudPlatform, cfg.GoogleCloudPlatform)
+	merged.Terraform = mergeTerraform(base.Terraform, cfg.Terraform)
+	return &merged
+}
+
+func findService(services []ServiceConfig, name string) int {
+	for i := range services {
+		if services[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeGCP returns base with the fields set in override replaced
+func mergeGCP(base, override *GCPConfig) *GCPConfig {
+	if base == nil || override == nil {
+		if override != nil {
+			return override
+		}
+		return base
+	}
+	merged := *base
+	if override.ProjectName != "" {
+		merged.ProjectName = override.ProjectName
+	}
+	if override.Account != "" {
+		merged.Account = override.Account
+	}
+	if override.ImpersonateServiceAccount != "" {
+		merged.ImpersonateServiceAccount = override.ImpersonateServiceAccount
+	}
+	return &merged
+}
+
+// mergeTerraform returns base with the fields set in override replaced
+func mergeTerraform(base, override *TerraformConfig) *TerraformConfig {
+	if base == nil || override == nil {
+		if override != nil {
+			return override
+		}
+		return base
+	}
+	merged := *base
+	if override.Dir != "" {
+		merged.Dir = override.Dir
+	}
+	if override.Envs != nil {
+		merged.Envs = override.Envs
+	}
+	return &merged
+}
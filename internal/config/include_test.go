@@ -0,0 +1,246 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfig_Include(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, "cleat.yaml"), `
+include:
+  - services/*/cleat.yaml
+envs: [dev]
+google_cloud_platform:
+  project_name: root-project
+services:
+  - name: web
+    dir: web
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "services", "api", "cleat.yaml"), `
+envs: [dev, staging]
+google_cloud_platform:
+  project_name: api-project
+  account: api@example.com
+services:
+  - name: api
+    commands:
+      - name: seed
+        run: ./seed
+commands:
+  - name: migrate
+    run: ./migrate
+workflows:
+  - id: deploy-api
+    name: Deploy API
+    commands: [command:migrate]
+`)
+
+	cfg, err := LoadConfig(filepath.Join(tmpDir, "cleat.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if len(cfg.Services) != 2 {
+		t.Fatalf("expected 2 services, got %+v", cfg.Services)
+	}
+	api := cfg.Services[1]
+	if api.Name != "api" || api.Dir != filepath.Join("services", "api") {
+		t.Errorf("expected api in services/api, got %s in '%s'", api.Name, api.Dir)
+	}
+	if api.SourcePath != filepath.Join(tmpDir, "services", "api", "cleat.yaml") {
+		t.Errorf("unexpected SourcePath of api: %s", api.SourcePath)
+	}
+	if cfg.Services[0].SourcePath != filepath.Join(tmpDir, "cleat.yaml") {
+		t.Errorf("unexpected SourcePath of web: %s", cfg.Services[0].SourcePath)
+	}
+
+	if len(cfg.Commands) != 1 || cfg.Commands[0].Dir != filepath.Join("services", "api") {
+		t.Errorf("expected migrate to run in services/api, got %+v", cfg.Commands)
+	}
+	if len(cfg.Workflows) != 1 || cfg.Workflows[0].ID != "deploy-api" {
+		t.Errorf("expected the included workflow, got %+v", cfg.Workflows)
+	}
+	if !reflect.DeepEqual(cfg.Envs, []string{"dev", "staging"}) {
+		t.Errorf("expected envs to be combined, got %v", cfg.Envs)
+	}
+	gcp := cfg.GoogleCloudPlatform
+	if gcp.ProjectName != "root-project" || gcp.Account != "api@example.com" {
+		t.Errorf("expected the including file's GCP settings to win, got %+v", gcp)
+	}
+}
+
+func TestLoadConfig_IncludeRebasesPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, "cleat.yaml"), `
+include:
+  - services/api/cleat.yaml
+google_cloud_platform:
+  project_name: root-project
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "services", "api", "cleat.yaml"), `
+app_yaml: app.yaml
+services:
+  - name: api
+    app_yaml: deploy/app.yaml
+    dockerfile: Dockerfile.prod
+    commands:
+      - name: seed
+        dir: scripts
+        run: ./seed
+  - name: admin
+    dir: admin
+    commands:
+      - name: build
+        run: make
+`)
+
+	cfg, err := LoadConfig(filepath.Join(tmpDir, "cleat.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	apiDir := filepath.Join("services", "api")
+	if cfg.AppYaml != filepath.Join(apiDir, "app.yaml") {
+		t.Errorf("expected app_yaml in services/api, got '%s'", cfg.AppYaml)
+	}
+	if len(cfg.Services) != 2 {
+		t.Fatalf("expected 2 services, got %+v", cfg.Services)
+	}
+	api, admin := cfg.Services[0], cfg.Services[1]
+	if api.AppYaml != filepath.Join(apiDir, "deploy", "app.yaml") {
+		t.Errorf("expected the app_yaml of api in services/api/deploy, got '%s'", api.AppYaml)
+	}
+	// Service commands and dockerfiles are relative to the service dir
+	if got := filepath.Join(api.Dir, api.Dockerfile); got != filepath.Join(apiDir, "Dockerfile.prod") {
+		t.Errorf("expected the dockerfile of api in services/api, got '%s'", got)
+	}
+	if got := filepath.Join(api.Dir, api.Commands[0].Dir); got != filepath.Join(apiDir, "scripts") {
+		t.Errorf("expected seed to run in services/api/scripts, got '%s'", got)
+	}
+	if got := filepath.Join(admin.Dir, admin.Commands[0].Dir); got != filepath.Join(apiDir, "admin") {
+		t.Errorf("expected build to run in services/api/admin, got '%s'", got)
+	}
+}
+
+func TestLoadConfig_Extends(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, "org", "base.yaml"), `
+envs: [dev, prod]
+google_cloud_platform:
+  project_name: org-project
+  account: ci@example.com
+services:
+  - name: tools
+    dir: tools
+workflows:
+  - id: ci
+    name: CI
+    commands: [lint]
+  - id: release
+    name: Release
+    commands: [build]
+commands:
+  - name: audit
+    run: ./audit
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "project", "cleat.yaml"), `
+extends: ../org/base.yaml
+google_cloud_platform:
+  project_name: my-project
+workflows:
+  - id: ci
+    name: CI
+    commands: [lint, test]
+`)
+
+	cfg, err := LoadConfig(filepath.Join(tmpDir, "project", "cleat.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if len(cfg.Services) == 0 || cfg.Services[0].Name != "tools" {
+		t.Fatalf("expected the base service, got %+v", cfg.Services)
+	}
+	if cfg.Services[0].Dir != filepath.Join("..", "org", "tools") {
+		t.Errorf("expected tools dir relative to the project, got '%s'", cfg.Services[0].Dir)
+	}
+	if len(cfg.Workflows) != 2 || len(cfg.Workflows[0].Commands) != 2 || cfg.Workflows[1].ID != "release" {
+		t.Errorf("expected ci to be replaced and release kept, got %+v", cfg.Workflows)
+	}
+	if len(cfg.Commands) != 1 || cfg.Commands[0].Name != "audit" {
+		t.Errorf("expected the base command, got %+v", cfg.Commands)
+	}
+	if !reflect.DeepEqual(cfg.Envs, []string{"dev", "prod"}) {
+		t.Errorf("expected the base envs, got %v", cfg.Envs)
+	}
+	gcp := cfg.GoogleCloudPlatform
+	if gcp.ProjectName != "my-project" || gcp.Account != "ci@example.com" {
+		t.Errorf("expected GCP settings to be layered, got %+v", gcp)
+	}
+}
+
+func TestLoadConfig_IncludeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "DuplicateService",
+			files: map[string]string{
+				"cleat.yaml": "include: [api.yaml]\nservices:\n  - name: api\n    dir: .\n",
+				"api.yaml":   "services:\n  - name: api\n",
+			},
+			wantErr: "service 'api' is already declared",
+		},
+		{
+			name: "MissingFile",
+			files: map[string]string{
+				"cleat.yaml": "include: [missing.yaml]\n",
+			},
+			wantErr: "invalid include 'missing.yaml'",
+		},
+		{
+			name: "Cycle",
+			files: map[string]string{
+				"cleat.yaml": "include: [other.yaml]\n",
+				"other.yaml": "extends: cleat.yaml\n",
+			},
+			wantErr: "config include cycle",
+		},
+		{
+			name: "InvalidVersion",
+			files: map[string]string{
+				"cleat.yaml": "extends: base.yaml\n",
+				"base.yaml":  "version: 99\n",
+			},
+			wantErr: "unrecognized configuration version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for name, content := range tt.files {
+				writeConfigFile(t, filepath.Join(tmpDir, name), content)
+			}
+			_, err := LoadConfig(filepath.Join(tmpDir, "cleat.yaml"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// Detect returns the configuration auto-detection finds in dir, as if dir
+// had an empty cleat.yaml and no cleat.local.yaml
+func Detect(dir string) (*Config, error) {
+	return (&loader{}).parse(nil, filepath.Join(dir, "cleat.yaml"))
+}
+
+// Render writes cfg, the result of Detect possibly with services or modules
+// turned off, as a commented cleat.yaml that loads back into cfg. Commands
+// are left out since they are read again from the Makefile, justfile or
+// Taskfile on load. With minimal, only what detected, the untouched result
+// of Detect, does not already contain is written.
+func Render(cfg, detected *Config, minimal bool) ([]byte, error) {
+	out := *cfg
+	if minimal {
+		out = minimalConfig(cfg, detected)
+	}
+	hasCommands := len(out.Commands) > 0
+	out.Commands = nil
+	services := out.Services
+	out.Services = make([]ServiceConfig, len(services))
+	for i, svc := range services {
+		hasCommands = hasCommands || len(svc.Commands) > 0
+		svc.Commands = nil
+		svc.SourcePath = ""
+		out.Services[i] = svc
+	}
+	if len(out.Services) == 0 {
+		out.Services = nil
+	}
+
+	root, err := encodeConfig(out)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if i > 0 {
+			key.HeadComment = "\n"
+		}
+		key.HeadComment += wrapComment(schema.Description("Config." + key.Value))
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# yaml-language-server: $schema=%s\n", schema.SchemaURL)
+	buf.WriteString("# Cleat configuration, written by `cleat init` from what it detected.\n")
+	buf.WriteString("# Detection still runs on load, so only change what it gets wrong.\n")
+	buf.WriteString("# See https://github.com/madewithfuture/cleat/blob/main/docs/configuration.md\n\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	if hasCommands {
+		buf.WriteString("\n# Targets of the Makefile, justfile or Taskfile become commands on load.\n")
+		buf.WriteString("# Add your own under commands:, or under a service's commands:.\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeConfig returns cfg as a YAML mapping without its zero values
+func encodeConfig(cfg Config) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := root.Encode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	pruneZero(&root, reflect.ValueOf(cfg))
+	return &root, nil
+}
+
+// minimalConfig returns the fields of cfg that differ from detected.
+// Services up to the last one that differs are kept, with at least their
+// name and dir, so detection adds the others back in the same order.
+func minimalConfig(cfg, detected *Config) Config {
+	out := Config{Version: cfg.Version, Workflows: cfg.Workflows}
+	if cfg.Docker != detected.Docker {
+		out.Docker = cfg.Docker
+	}
+	if !reflect.DeepEqual(cfg.Envs, detected.Envs) {
+		out.Envs = cfg.Envs
+	}
+	if cfg.AppYaml != detected.AppYaml {
+		out.AppYaml = cfg.AppYaml
+	}
+	if !reflect.DeepEqual(cfg.GoogleCloudPlatform, detected.GoogleCloudPlatform) {
+		out.GoogleCloudPlatform = cfg.GoogleCloudPlatform
+	}
+	if !reflect.DeepEqual(cfg.Terraform, detected.Terraform) {
+		out.Terraform = cfg.Terraform
+	}
+
+	last := -1
+	for i, svc := range cfg.Services {
+		j := findService(detected.Services, svc.Name)
+		if j < 0 || !sameService(svc, detected.Services[j]) {
+			last = i
+		}
+	}
+	for _, svc := range cfg.Services[:last+1] {
+		j := findService(detected.Services, svc.Name)
+		if j < 0 {
+			out.Services = append(out.Services, svc)
+			continue
+		}
+		out.Services = append(out.Services, minimalService(svc, detected.Services[j]))
+	}
+	return out
+}
+
+func sameService(a, b ServiceConfig) bool {
+	a.SourcePath, b.SourcePath = "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+// minimalService returns the name and dir of svc and the fields that differ
+// from d. Modules are written as a whole, since a service that lists
+// modules keeps them as given.
+func minimalService(svc, d ServiceConfig) ServiceConfig {
+	out := ServiceConfig{Name: svc.Name, Dir: svc.Dir}
+	if !reflect.DeepEqual(svc.Docker, d.Docker) {
+		out.Docker = svc.Docker
+	}
+	if svc.Dockerfile != d.Dockerfile {
+		out.Dockerfile = svc.Dockerfile
+	}
+	if svc.Image != d.Image {
+		out.Image = svc.Image
+	}
+	if svc.Command != d.Command {
+		out.Command = svc.Command
+	}
+	if svc.AppYaml != d.AppYaml {
+		out.AppYaml = svc.AppYaml
+	}
+	if !reflect.DeepEqual(svc.Modules, d.Modules) {
+		out.Modules = svc.Modules
+	}
+	if !reflect.DeepEqual(svc.DependsOn, d.DependsOn) {
+		out.DependsOn = svc.DependsOn
+	}
+	return out
+}
+
+// pruneZero removes the keys of node whose field in v holds its zero value,
+// which loads back the same. Pointers are kept, since a nil pointer is
+// omitted already and a pointer to false means something.
+func pruneZero(node *yaml.Node, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := make(map[string]int)
+		for _, f := range yamlFieldList(v.Type()) {
+			fields[f.name] = f.index
+		}
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if idx, ok := fields[key.Value]; ok {
+				fv := v.Field(idx)
+				if fv.Kind() != reflect.Ptr && fv.IsZero() {
+					continue
+				}
+				pruneZero(value, fv)
+			}
+			content = append(content, key, value)
+		}
+		node.Content = content
+	case v.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			if i < v.Len() {
+				pruneZero(item, v.Index(i))
+			}
+		}
+	}
+}
+
+// yamlField is a field of a struct with its YAML key
+type yamlField struct {
+	name  string
+	index int
+}
+
+// yamlFieldList returns the fields of struct type t that YAML reads, in order
+func yamlFieldList(t reflect.Type) []yamlField {
+	var fields []yamlField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields = append(fields, yamlField{name, i})
+	}
+	return fields
+}
+
+// wrapComment breaks text into comment lines of at most 76 characters
+func wrapComment(text string) string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > 76 {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// copyProject copies a fixture project into a temp dir, without its cleat.yaml
+func copyProject(t *testing.T, src string) string {
+	t.Helper()
+	dst := t.TempDir()
+	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if rel == "cleat.yaml" {
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+	if err != nil {
+		t.Fatalf("failed to copy %s: %v", src, err)
+	}
+	return dst
+}
+
+// withoutSources clears the fields that record where a config was loaded from
+func withoutSources(cfg *Config) *Config {
+	c := *cfg
+	c.SourcePath = ""
+	c.Inputs = nil
+	c.Services = append([]ServiceConfig(nil), cfg.Services...)
+	for i := range c.Services {
+		c.Services[i].SourcePath = ""
+	}
+	return &c
+}
+
+// turnOff disables the docker setting and modules of the first service, the
+// way cleat init does when a service is declined
+func turnOff(cfg *Config) {
+	if len(cfg.Services) == 0 {
+		return
+	}
+	svc := &cfg.Services[0]
+	off := false
+	svc.Docker = &off
+	for i, m := range svc.Modules {
+		switch {
+		case m.Python != nil:
+			svc.Modules[i] = ModuleConfig{Python: &PythonConfig{Enabled: &off}}
+		case m.Npm != nil:
+			svc.Modules[i] = ModuleConfig{Npm: &NpmConfig{Enabled: &off}}
+		case m.Go != nil:
+			svc.Modules[i] = ModuleConfig{Go: &GoConfig{Enabled: &off}}
+		case m.Ruby != nil:
+			svc.Modules[i] = ModuleConfig{Ruby: &RubyConfig{Enabled: &off}}
+		}
+	}
+}
+
+func TestRender_RoundTrip(t *testing.T) {
+	fixtures, err := os.ReadDir("../../testdata/fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fixture := range fixtures {
+		for _, minimal := range []bool{false, true} {
+			for _, declined := range []bool{false, true} {
+				name := fixture.Name()
+				if minimal {
+					name += "/minimal"
+				}
+				if declined {
+					name += "/declined"
+				}
+				t.Run(name, func(t *testing.T) {
+					dir := copyProject(t, filepath.Join("../../testdata/fixtures", fixture.Name()))
+					os.WriteFile(filepath.Join(dir, "Makefile"), []byte("build:\n\tgo build\n"), 0644)
+
+					detected, err := Detect(dir)
+					if err != nil {
+						t.Fatalf("Detect failed: %v", err)
+					}
+					want, _ := Detect(dir)
+					if declined {
+						turnOff(want)
+					}
+
+					data, err := Render(want, detected, minimal)
+					if err != nil {
+						t.Fatalf("Render failed: %v", err)
+					}
+					path := filepath.Join(dir, "cleat.yaml")
+					os.WriteFile(path, data, 0644)
+					got, err := LoadConfig(path)
+					if err != nil {
+						t.Fatalf("rendered config does not load: %v\n%s", err, data)
+					}
+					if !reflect.DeepEqual(withoutSources(got), withoutSources(want)) {
+						t.Errorf("rendered config loads differently\n%s\ngot  %+v\nwant %+v", data, withoutSources(got), withoutSources(want))
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "Makefile"), []byte("build:\n\tgo build\n"), 0644)
+	detected, err := Detect(dir)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+
+	t.Run("Full", func(t *testing.T) {
+		data, err := Render(detected, detected, false)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		out := string(data)
+		for _, want := range []string{
+			"# yaml-language-server: $schema=",
+			"# Services of the project, each in its own directory.\nservices:",
+			"- name: default",
+			"- go:",
+			"# Targets of the Makefile, justfile or Taskfile become commands on load.",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected output to contain %q, got:\n%s", want, out)
+			}
+		}
+		for _, unwanted := range []string{"docker: false", "\ncommands:", "make"} {
+			if strings.Contains(out, unwanted) {
+				t.Errorf("expected output not to contain %q, got:\n%s", unwanted, out)
+			}
+		}
+	})
+
+	t.Run("Minimal", func(t *testing.T) {
+		data, err := Render(detected, detected, true)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if strings.Contains(string(data), "services:") {
+			t.Errorf("expected no services when nothing differs from detection, got:\n%s", data)
+		}
+		if !strings.Contains(string(data), "version: 1") {
+			t.Errorf("expected the version, got:\n%s", data)
+		}
+	})
+}
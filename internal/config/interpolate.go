@@ -0,0 +1,331 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Namespaces of the references Expand replaces. ${name}, without one, is a
+// workflow parameter.
+var namespaces = []string{"env", "input", "config", "git"}
+
+// GitBranch returns the branch checked out in dir; tests replace it
+var GitBranch = func(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "branch", "--show-current").Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return "", errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// reference is a ${namespace:key} or ${namespace:key:-default} in a value
+type reference struct {
+	start, end int
+	namespace  string
+	key        string
+	def        string
+	hasDefault bool
+}
+
+func (r reference) String() string {
+	if r.hasDefault {
+		return fmt.Sprintf("${%s:%s:-%s}", r.namespace, r.key, r.def)
+	}
+	return fmt.Sprintf("${%s:%s}", r.namespace, r.key)
+}
+
+// references finds the references in value. A default may itself contain
+// braces, as long as they are balanced.
+func references(value string) []reference {
+	var refs []reference
+	for i := 0; i < len(value); i++ {
+		if !strings.HasPrefix(value[i:], "${") {
+			continue
+		}
+		ns, rest, ok := strings.Cut(value[i+2:], ":")
+		if !ok || !isNamespace(ns) {
+			continue
+		}
+		depth, end := 1, -1
+		for j := 0; j < len(rest); j++ {
+			if rest[j] == '{' {
+				depth++
+			} else if rest[j] == '}' {
+				if depth--; depth == 0 {
+					end = j
+					break
+				}
+			}
+		}
+		if end < 0 {
+			continue
+		}
+		ref := reference{start: i, namespace: ns}
+		ref.key, ref.def, ref.hasDefault = strings.Cut(rest[:end], ":-")
+		ref.end = i + 2 + len(ns) + 1 + end + 1
+		refs = append(refs, ref)
+		i = ref.end - 1
+	}
+	return refs
+}
+
+func isNamespace(s string) bool {
+	for _, ns := range namespaces {
+		if s == ns {
+			return true
+		}
+	}
+	return false
+}
+
+// Expand replaces the ${env:VAR}, ${input:key}, ${config:path} and
+// ${git:branch} references in value. A reference can give a default after
+// :-, as in ${env:REGION:-europe-west1}; one without a default whose value is
+// not set is an error. Config paths are those of config show, such as
+// google_cloud_platform.project_name or services[0].dir.
+func Expand(value string, cfg *Config, inputs map[string]string) (string, error) {
+	e := &expander{cfg: cfg, inputs: inputs}
+	return e.expand(value)
+}
+
+// Interpolate returns a copy of cfg with the references in its values
+// expanded; cfg itself is left as it is. Commands and workflows are copied
+// as they are: they are expanded as they run, once their own inputs are
+// known.
+func Interpolate(cfg *Config, inputs map[string]string) (*Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	e := &expander{cfg: cfg, inputs: inputs}
+	expanded := deepCopy(reflect.ValueOf(cfg)).Interface().(*Config)
+	var errs []error
+	walkValues(reflect.ValueOf(expanded).Elem(), "", func(path, value string) string {
+		v, err := e.expand(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			return value
+		}
+		return v
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// deepCopy returns a copy of v that shares no pointer, slice or map with it
+// along the config's fields
+func deepCopy(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			p := reflect.New(v.Type().Elem())
+			p.Elem().Set(deepCopy(v.Elem()))
+			c.Set(p)
+		}
+	case reflect.Struct:
+		for _, f := range yamlFieldList(v.Type()) {
+			c.Field(f.index).Set(deepCopy(v.Field(f.index)))
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				s.Index(i).Set(deepCopy(v.Index(i)))
+			}
+			c.Set(s)
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			m := reflect.MakeMapWithSize(v.Type(), v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				m.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+			}
+			c.Set(m)
+		}
+	}
+	return c
+}
+
+// ValueInputs returns the inputs that the values of cfg refer to without a
+// default, which must be known before Interpolate
+func ValueInputs(cfg *Config) []string {
+	if cfg == nil {
+		return nil
+	}
+	var keys []string
+	seen := make(map[string]bool)
+	walkValues(reflect.ValueOf(cfg).Elem(), "", func(path, value string) string {
+		for _, key := range InputRefs(value) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+		return value
+	})
+	return keys
+}
+
+// InputRefs returns the keys of the ${input:key} references in value that
+// have no default
+func InputRefs(value string) []string {
+	var keys []string
+	for _, ref := range references(value) {
+		if ref.namespace == "input" && !ref.hasDefault {
+			keys = append(keys, ref.key)
+		}
+	}
+	return keys
+}
+
+// walkValues calls fn on every string within v, outside commands and
+// workflows, and stores what it returns
+func walkValues(v reflect.Value, path string, fn func(path, value string) string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			walkValues(v.Elem(), path, fn)
+		}
+	case reflect.Struct:
+		for _, f := range yamlFieldList(v.Type()) {
+			if f.name == "commands" || f.name == "workflows" {
+				continue
+			}
+			walkValues(v.Field(f.index), joinPath(path, f.name), fn)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkValues(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case reflect.String:
+		if strings.Contains(v.String(), "${") {
+			v.SetString(fn(path, v.String()))
+		}
+	}
+}
+
+// expander resolves references against a config and inputs
+type expander struct {
+	cfg    *Config
+	inputs map[string]string
+	// fields holds the values of cfg by path, read on the first ${config:}
+	fields map[string]string
+	// visiting holds the config paths being expanded, to catch cycles
+	visiting map[string]bool
+}
+
+func (e *expander) expand(value string) (string, error) {
+	refs := references(value)
+	if len(refs) == 0 {
+		return value, nil
+	}
+	var b strings.Builder
+	var errs []error
+	last := 0
+	for _, ref := range refs {
+		b.WriteString(value[last:ref.start])
+		last = ref.end
+		v, ok, err := e.lookup(ref)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+			b.WriteString(value[ref.start:ref.end])
+		case ok:
+			b.WriteString(v)
+		case ref.hasDefault:
+			def, err := e.expand(ref.def)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			b.WriteString(def)
+		default:
+			errs = append(errs, fmt.Errorf("%s: %s has no value (give it a default with ${%s:%s:-value})", ref, undefined(ref), ref.namespace, ref.key))
+			b.WriteString(value[ref.start:ref.end])
+		}
+	}
+	b.WriteString(value[last:])
+	return b.String(), errors.Join(errs...)
+}
+
+// undefined describes what a reference without a value refers to
+func undefined(ref reference) string {
+	switch ref.namespace {
+	case "env":
+		return fmt.Sprintf("environment variable %s", ref.key)
+	case "input":
+		return fmt.Sprintf("input '%s'", ref.key)
+	case "config":
+		return fmt.Sprintf("config field '%s'", ref.key)
+	}
+	return fmt.Sprintf("%s '%s'", ref.namespace, ref.key)
+}
+
+// lookup returns the value of ref, and whether it has one
+func (e *expander) lookup(ref reference) (string, bool, error) {
+	switch ref.namespace {
+	case "env":
+		v, ok := os.LookupEnv(ref.key)
+		return v, ok && v != "", nil
+	case "input":
+		v, ok := e.inputs[ref.key]
+		return v, ok, nil
+	case "config":
+		return e.configValue(ref.key)
+	case "git":
+		if ref.key != "branch" {
+			return "", false, fmt.Errorf("unknown git variable '%s', must be: branch", ref.key)
+		}
+		dir := "."
+		if e.cfg != nil && e.cfg.SourcePath != "" {
+			dir = filepath.Dir(e.cfg.SourcePath)
+		}
+		branch, err := GitBranch(dir)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read the git branch: %w", err)
+		}
+		return branch, branch != "", nil
+	}
+	return "", false, fmt.Errorf("unknown namespace '%s'", ref.namespace)
+}
+
+// configValue returns the value at path in the config, with its own
+// references expanded
+func (e *expander) configValue(path string) (string, bool, error) {
+	if e.cfg == nil {
+		return "", false, nil
+	}
+	if e.fields == nil {
+		e.fields = make(map[string]string)
+		for _, f := range flattenConfig(e.cfg) {
+			e.fields[f.path] = f.value
+		}
+		e.visiting = make(map[string]bool)
+	}
+	v, ok := e.fields[path]
+	if !ok {
+		return "", false, nil
+	}
+	if e.visiting[path] {
+		return "", false, fmt.Errorf("config field '%s' refers to itself", path)
+	}
+	e.visiting[path] = true
+	defer delete(e.visiting, path)
+	v, err := e.expand(v)
+	if err != nil {
+		return "", false, err
+	}
+	return v, true, nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("CLEAT_TEST_ACCOUNT", "ops@example.com")
+	t.Setenv("CLEAT_TEST_EMPTY", "")
+	oldGitBranch := GitBranch
+	GitBranch = func(dir string) (string, error) { return "main", nil }
+	defer func() { GitBranch = oldGitBranch }()
+
+	cfg := &Config{
+		GoogleCloudPlatform: &GCPConfig{ProjectName: "acme-${input:env}", Account: "${env:CLEAT_TEST_ACCOUNT}"},
+		Envs:                []string{"dev", "${config:envs[1]}"},
+	}
+	inputs := map[string]string{"env": "prod"}
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{"gcloud --account=${env:CLEAT_TEST_ACCOUNT}", "gcloud --account=ops@example.com", ""},
+		{"${env:CLEAT_TEST_MISSING:-europe-west1}", "europe-west1", ""},
+		{"${env:CLEAT_TEST_EMPTY:-fallback}", "fallback", ""},
+		{"${env:CLEAT_TEST_MISSING:-${input:env}}", "prod", ""},
+		{"deploy ${input:env}", "deploy prod", ""},
+		{"${config:google_cloud_platform.project_name}", "acme-prod", ""},
+		{"release/${git:branch}", "release/main", ""},
+		{"${name} and ${HOME} are left alone", "${name} and ${HOME} are left alone", ""},
+		{"${env:CLEAT_TEST_MISSING}", "", "${env:CLEAT_TEST_MISSING}: environment variable CLEAT_TEST_MISSING has no value (give it a default with ${env:CLEAT_TEST_MISSING:-value})"},
+		{"${input:tag}", "", "input 'tag' has no value"},
+		{"${config:terraform.dir}", "", "config field 'terraform.dir' has no value"},
+		{"${config:envs[1]}", "", "config field 'envs[1]' refers to itself"},
+		{"${git:sha}", "", "unknown git variable 'sha', must be: branch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Expand(tt.value, cfg, inputs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("GitError", func(t *testing.T) {
+		GitBranch = func(dir string) (string, error) { return "", errors.New("not a git repository") }
+		if _, err := Expand("${git:branch}", cfg, nil); err == nil || !strings.Contains(err.Error(), "failed to read the git branch: not a git repository") {
+			t.Errorf("expected the git error, got %v", err)
+		}
+	})
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("CLEAT_TEST_PROJECT", "acme")
+	cfg := &Config{
+		GoogleCloudPlatform: &GCPConfig{ProjectName: "${env:CLEAT_TEST_PROJECT}", Account: "${input:account}"},
+		Services:            []ServiceConfig{{Name: "api", Dir: "${env:CLEAT_TEST_DIR:-api}", Commands: []CommandConfig{{Name: "seed", Run: CommandRun{Shell: "seed ${input:count}"}}}}},
+		Commands:            []CommandConfig{{Name: "tag", Run: CommandRun{Shell: "git tag ${input:tag}"}}},
+		Workflows:           []Workflow{{Name: "ship", Commands: PlainSteps("deploy:${env:CLEAT_TEST_MISSING}")}},
+	}
+
+	if got := ValueInputs(cfg); !reflect.DeepEqual(got, []string{"account"}) {
+		t.Errorf("expected only the inputs of config values, got %v", got)
+	}
+
+	_, err := Interpolate(cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "google_cloud_platform.account: ${input:account}: input 'account' has no value") {
+		t.Fatalf("expected an error for the missing input, got %v", err)
+	}
+	expanded, err := Interpolate(cfg, map[string]string{"account": "ops@example.com"})
+	if err != nil {
+		t.Fatalf("Interpolate failed: %v", err)
+	}
+	if expanded.GoogleCloudPlatform.ProjectName != "acme" || expanded.GoogleCloudPlatform.Account != "ops@example.com" || expanded.Services[0].Dir != "api" {
+		t.Errorf("unexpected values %+v, service dir %q", expanded.GoogleCloudPlatform, expanded.Services[0].Dir)
+	}
+	if expanded.Commands[0].Run.Shell != "git tag ${input:tag}" || expanded.Services[0].Commands[0].Run.Shell != "seed ${input:count}" || expanded.Workflows[0].Commands[0].Run != "deploy:${env:CLEAT_TEST_MISSING}" {
+		t.Errorf("expected commands and workflows to be left for when they run, got %+v", expanded)
+	}
+	if cfg.GoogleCloudPlatform.Account != "${input:account}" || cfg.Services[0].Dir != "${env:CLEAT_TEST_DIR:-api}" {
+		t.Errorf("expected the config itself left as it is, got %+v, service dir %q", cfg.GoogleCloudPlatform, cfg.Services[0].Dir)
+	}
+	expanded.Services[0].Commands[0].Name = "changed"
+	if cfg.Services[0].Commands[0].Name != "seed" {
+		t.Error("expected the copy to share nothing with the config")
+	}
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocalConfigName is the per-developer file merged over cleat.yaml. It sits
+// next to cleat.yaml and is meant to be left out of version control.
+//
+// It is merged over the configuration after includes and extends:
+//
+//   - a mapping, such as google_cloud_platform or a service, is merged key by
+//     key, so only the fields the local file sets change;
+//   - services, commands, workflow params and command inputs are matched by
+//     name (workflows by id, then name, inputs by key, modules by kind) and
+//     merged the same way; an entry the configuration lacks is added;
+//   - any other list, such as envs or a command's run, is replaced as a whole;
+//   - a value the local file sets replaces the configured one, including
+//     false and empty values.
+//
+// The local file cannot use extends or include.
+const LocalConfigName = "cleat.local.yaml"
+
+// override is a value the local file set, recorded for provenance
+type override struct {
+	key  *yaml.Node
+	node *yaml.Node
+	path string
+}
+
+// localPath returns the local file next to the config file at path, or its
+// .yml spelling when only that exists
+func localPath(path string) string {
+	local := filepath.Join(filepath.Dir(path), LocalConfigName)
+	if _, err := os.Stat(local); os.IsNotExist(err) {
+		if yml := filepath.Join(filepath.Dir(path), "cleat.local.yml"); fileExists(yml) {
+			return yml
+		}
+	}
+	return local
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// mergeLocal merges the local file next to the config file at path over cfg
+func (l *loader) mergeLocal(cfg *Config, path string) error {
+	local := localPath(path)
+	data, err := os.ReadFile(local)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", local, err)
+	}
+	absLocal, err := filepath.Abs(local)
+	if err != nil {
+		absLocal = local
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return yamlError(local, err)
+	}
+	cfg.LocalPath = absLocal
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	var decoded Config
+	l.checkFields(local, root, reflect.TypeOf(decoded), "")
+	if err := root.Decode(&decoded); err != nil {
+		return yamlError(local, err)
+	}
+	for _, key := range []string{"extends", "include"} {
+		if i := mappingKey(root, key); i >= 0 {
+			l.report(local, root.Content[i], "%s cannot be used in %s", key, LocalConfigName)
+		}
+	}
+	l.checkFile(local, root, &decoded)
+	l.files = append(l.files, loadedFile{path: absLocal, root: root})
+
+	return l.merge(absLocal, reflect.ValueOf(cfg).Elem(), nil, root, "")
+}
+
+// merge sets what node declares on v, which is at path in the config
+func (l *loader) merge(file string, v reflect.Value, key, node *yaml.Node, path string) error {
+	_, custom := v.Addr().Interface().(yaml.Unmarshaler)
+	switch {
+	case v.Kind() == reflect.Ptr && node.Kind == yaml.MappingNode && v.Type().Elem().Kind() == reflect.Struct:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return l.merge(file, v.Elem(), key, node, path)
+
+	case v.Kind() == reflect.Struct && node.Kind == yaml.MappingNode && !custom:
+		fields := make(map[string]int)
+		for _, f := range yamlFieldList(v.Type()) {
+			fields[f.name] = f.index
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, value := node.Content[i], node.Content[i+1]
+			index, ok := fields[k.Value]
+			if !ok || k.Value == "extends" || k.Value == "include" {
+				continue
+			}
+			if err := l.merge(file, v.Field(index), k, value, joinPath(path, k.Value)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case v.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode && hasListKey(v.Type().Elem()):
+		for _, itemNode := range node.Content {
+			item := reflect.New(v.Type().Elem())
+			if err := itemNode.Decode(item.Interface()); err != nil {
+				return yamlError(file, err)
+			}
+			id := listKey(item.Elem())
+			match := -1
+			for i := 0; i < v.Len(); i++ {
+				if listKey(v.Index(i)) == id {
+					match = i
+					break
+				}
+			}
+			if match >= 0 {
+				if err := l.merge(file, v.Index(match), nil, itemNode, fmt.Sprintf("%s[%d]", path, match)); err != nil {
+					return err
+				}
+				continue
+			}
+			if f := item.Elem().FieldByName("SourcePath"); f.IsValid() {
+				f.SetString(file)
+			}
+			l.overrides = append(l.overrides, override{nil, itemNode, fmt.Sprintf("%s[%d]", path, v.Len())})
+			v.Set(reflect.Append(v, item.Elem()))
+		}
+		return nil
+
+	case v.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, value := node.Content[i], node.Content[i+1]
+			elem := reflect.New(v.Type().Elem())
+			if err := value.Decode(elem.Interface()); err != nil {
+				return yamlError(file, err)
+			}
+			v.SetMapIndex(reflect.ValueOf(k.Value), elem.Elem())
+			l.overrides = append(l.overrides, override{k, value, joinPath(path, k.Value)})
+		}
+		return nil
+	}
+
+	decoded := reflect.New(v.Type())
+	if err := node.Decode(decoded.Interface()); err != nil {
+		return yamlError(file, err)
+	}
+	v.Set(decoded.Elem())
+	l.overrides = append(l.overrides, override{key, node, path})
+	return nil
+}
+
+// hasListKey reports whether lists of t are merged item by item
+func hasListKey(t reflect.Type) bool {
+	return listKey(reflect.New(t).Elem()) != nil
+}
+
+// listKey returns what matches an item of a list in the local file to the
+// item it overrides, or nil for items of lists that are replaced whole
+func listKey(v reflect.Value) interface{} {
+	type key struct{ kind, id string }
+	switch item := v.Interface().(type) {
+	case ServiceConfig:
+		return key{"service", item.Name}
+	case CommandConfig:
+		return key{"command", item.Name}
+	case WorkflowParam:
+		return key{"param", item.Name}
+	case CommandInput:
+		return key{"input", item.Key}
+	case Workflow:
+		if item.ID != "" {
+			return key{"workflow", item.ID}
+		}
+		return key{"workflow", item.Name}
+	case ModuleConfig:
+		switch {
+		case item.Python != nil:
+			return key{"module", "python"}
+		case item.Npm != nil:
+			return key{"module", "npm"}
+		case item.Go != nil:
+			return key{"module", "go"}
+		case item.Ruby != nil:
+			return key{"module", "ruby"}
+		}
+		return key{"module", ""}
+	}
+	return nil
+}
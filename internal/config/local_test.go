@@ -0,0 +1,153 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_Local(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, "base.yaml"), `services:
+  - name: worker
+    dir: .
+    image: python:3.12
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "cleat.yaml"), `extends: base.yaml
+docker: true
+envs: [dev, staging, prod]
+google_cloud_platform:
+  project_name: acme
+  account: ci@acme.dev
+services:
+  - name: api
+    dir: .
+    docker: true
+    modules:
+      - python:
+          django: true
+          package_manager: pip
+commands:
+  - name: seed
+    run: ./seed
+    env:
+      SIZE: small
+      MODE: fast
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "cleat.local.yaml"), `docker: false
+envs: [dev]
+google_cloud_platform:
+  account: me@acme.dev
+services:
+  - name: api
+    docker: false
+    modules:
+      - python:
+          package_manager: uv
+  - name: worker
+    image: python:3.13
+  - name: notebook
+    dir: .
+commands:
+  - name: seed
+    env:
+      SIZE: large
+`)
+
+	cfg, err := LoadConfig(filepath.Join(tmpDir, "cleat.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.LocalPath != filepath.Join(tmpDir, "cleat.local.yaml") {
+		t.Errorf("unexpected LocalPath %q", cfg.LocalPath)
+	}
+	if cfg.Docker {
+		t.Error("expected docker turned off by the local file")
+	}
+	if !reflect.DeepEqual(cfg.Envs, []string{"dev"}) {
+		t.Errorf("expected envs replaced, got %v", cfg.Envs)
+	}
+	if gcp := cfg.GoogleCloudPlatform; gcp.ProjectName != "acme" || gcp.Account != "me@acme.dev" {
+		t.Errorf("expected only the account overridden, got %+v", gcp)
+	}
+
+	var names []string
+	for _, svc := range cfg.Services {
+		names = append(names, svc.Name)
+	}
+	if strings.Join(names, ",") != "worker,api,notebook" {
+		t.Fatalf("unexpected services %v", names)
+	}
+	worker, api, notebook := cfg.Services[0], cfg.Services[1], cfg.Services[2]
+	if worker.Image != "python:3.13" || worker.Dir != "." {
+		t.Errorf("expected the extended service merged, got %+v", worker)
+	}
+	if api.IsDocker() {
+		t.Error("expected docker: false on api")
+	}
+	if len(api.Modules) != 1 || !api.Modules[0].Python.Django || api.Modules[0].Python.PackageManager != "uv" {
+		t.Errorf("expected the python module merged, got %+v", api.Modules)
+	}
+	if api.SourcePath != filepath.Join(tmpDir, "cleat.yaml") || notebook.SourcePath != cfg.LocalPath {
+		t.Errorf("unexpected sources %q and %q", api.SourcePath, notebook.SourcePath)
+	}
+	seed := cfg.Commands[0]
+	if seed.Run.Shell != "./seed" || !reflect.DeepEqual(seed.Env, map[string]string{"SIZE": "large", "MODE": "fast"}) {
+		t.Errorf("expected the command's env merged, got %+v", seed)
+	}
+
+	t.Run("Origins", func(t *testing.T) {
+		_, fields, err := LoadWithOrigins(filepath.Join(tmpDir, "cleat.yaml"), false)
+		if err != nil {
+			t.Fatalf("LoadWithOrigins failed: %v", err)
+		}
+		origins := fieldOrigins(fields)
+		tests := []struct {
+			path string
+			want Origin
+		}{
+			{"google_cloud_platform.project_name", Origin{Source: SourceDeclared, File: "cleat.yaml", Line: 5}},
+			{"google_cloud_platform.account", Origin{Source: SourceDeclared, File: "cleat.local.yaml", Line: 4}},
+			{"services[1].modules[0].python.package_manager", Origin{Source: SourceDeclared, File: "cleat.local.yaml", Line: 10}},
+			{"services[2].name", Origin{Source: SourceDeclared, File: "cleat.local.yaml", Line: 13}},
+			{"commands[0].env.MODE", Origin{Source: SourceDeclared, File: "cleat.yaml", Line: 20}},
+		}
+		for _, tt := range tests {
+			if got := origins[tt.path]; got != tt.want {
+				t.Errorf("origin of %s = %+v, want %+v", tt.path, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("NotDetected", func(t *testing.T) {
+		detected, err := Detect(tmpDir)
+		if err != nil {
+			t.Fatalf("Detect failed: %v", err)
+		}
+		if detected.LocalPath != "" || len(detected.Services) > 0 && detected.Services[0].Name == "notebook" {
+			t.Errorf("expected Detect to leave the local file out, got %+v", detected)
+		}
+	})
+}
+
+func TestLoadConfig_LocalProblems(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, "cleat.yaml"), "envs: [dev]\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "cleat.local.yaml"), `extends: other.yaml
+dockr: false
+`)
+
+	_, err := LoadConfig(filepath.Join(tmpDir, "cleat.yaml"))
+	if err == nil {
+		t.Fatal("expected problems in the local file")
+	}
+	for _, want := range []string{
+		"cleat.local.yaml:1:1: extends cannot be used in cleat.local.yaml",
+		"cleat.local.yaml:2:1: unknown field 'dockr' (did you mean 'docker'?)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/detector"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a field of the effective config
+const (
+	SourceDeclared = "declared"
+	SourceDetected = "detected"
+	SourceDefault  = "default"
+)
+
+// Origin says where a field of the effective config came from
+type Origin struct {
+	// Source is declared, detected or default
+	Source string `json:"source"`
+	// File is the config file that declares the field, or the file that
+	// made a detector set it, relative to the project root when inside it
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+	// Detector names the detector that set the field
+	Detector string `json:"detector,omitempty"`
+}
+
+func (o Origin) String() string {
+	switch {
+	case o.Source == SourceDeclared && o.Line > 0:
+		return fmt.Sprintf("declared in %s:%d", o.File, o.Line)
+	case o.Source == SourceDeclared:
+		return "declared in " + o.File
+	case o.Source == SourceDetected:
+		return fmt.Sprintf("detected by %s from %s", o.Detector, o.File)
+	case o.Detector != "":
+		return "default from " + o.Detector
+	}
+	return "default"
+}
+
+// Field is a value of the effective config and where it came from
+type Field struct {
+	// Path locates the field by YAML keys and indexes, such as
+	// services[0].modules[1].npm.scripts[0]
+	Path  string `json:"path"`
+	Value string `json:"value"`
+	Origin
+}
+
+// loadedFile is a config file read by the loader, with its YAML
+type loadedFile struct {
+	path string
+	root *yaml.Node
+}
+
+// LoadWithOrigins loads the config file at path, which need not exist, and
+// reports where each of its fields came from. Without detect, only what the
+// config files declare is loaded, with their includes and extends applied.
+func LoadWithOrigins(path string, detect bool) (*Config, []Field, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	l := &loader{origins: make(map[string]Origin), skipDetection: !detect, local: true}
+	cfg, err := l.parse(data, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fields []Field
+	for _, f := range flattenConfig(cfg) {
+		origin, ok := l.origins[f.path]
+		if !ok {
+			origin = Origin{Source: SourceDefault}
+		}
+		fields = append(fields, Field{Path: f.path, Value: f.value, Origin: origin})
+	}
+	return cfg, fields, nil
+}
+
+// declare records the origin of every field the loaded files declare.
+// Services and workflows come from their SourcePath; top-level fields and
+// commands from the first file that sets them, since the loaded file wins
+// over the files it includes or extends.
+func (l *loader) declare(cfg *Config) {
+	for _, key := range []string{"version", "extends", "include", "docker", "google_cloud_platform", "terraform", "envs", "app_yaml"} {
+		for _, f := range l.files {
+			if i := mappingKey(f.root, key); i >= 0 {
+				l.declareNode(f.path, f.root.Content[i], f.root.Content[i+1], key)
+				break
+			}
+		}
+	}
+	for i, svc := range cfg.Services {
+		if node := l.findItem(svc.SourcePath, "services", "name", svc.Name); node != nil {
+			l.declareNode(svc.SourcePath, nil, node, fmt.Sprintf("services[%d]", i))
+		}
+	}
+	for i, wf := range cfg.Workflows {
+		node := l.findItem(wf.SourcePath, "workflows", "id", wf.ID)
+		if node == nil {
+			node = l.findItem(wf.SourcePath, "workflows", "name", wf.Name)
+		}
+		if node != nil {
+			l.declareNode(wf.SourcePath, nil, node, fmt.Sprintf("workflows[%d]", i))
+		}
+	}
+	for i, c := range cfg.Commands {
+		for _, f := range l.files {
+			if node := l.findItem(f.path, "commands", "name", c.Name); node != nil {
+				l.declareNode(f.path, nil, node, fmt.Sprintf("commands[%d]", i))
+				break
+			}
+		}
+	}
+	// The local file wins over every other
+	for _, o := range l.overrides {
+		l.declareNode(cfg.LocalPath, o.key, o.node, o.path)
+	}
+}
+
+// findItem returns the item of the list at key in file whose field is value
+func (l *loader) findItem(file, key, field, value string) *yaml.Node {
+	for _, f := range l.files {
+		if f.path != file {
+			continue
+		}
+		list := mappingValue(f.root, key)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for _, item := range list.Content {
+			if v := mappingValue(item, field); v != nil && v.Value == value {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+// declareNode records node, and everything within it, as declared in file.
+// The position is that of key, the line the value starts on.
+func (l *loader) declareNode(file string, key, node *yaml.Node, path string) {
+	line := node.Line
+	if key != nil {
+		line = key.Line
+	}
+	l.origins[path] = Origin{Source: SourceDeclared, File: l.relative(file), Line: line}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			l.declareNode(file, node.Content[i], node.Content[i+1], joinPath(path, node.Content[i].Value))
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			l.declareNode(file, nil, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// relative returns file relative to the project root when it is inside it
+func (l *loader) relative(file string) string {
+	root, err := filepath.Abs(l.root)
+	if err != nil {
+		return file
+	}
+	if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return file
+}
+
+// detect runs the detectors on cfg, recording which one set each field
+// when origins are kept
+func (l *loader) detect(baseDir string, cfg *Config) error {
+	if l.origins == nil {
+		return detector.DetectAll(baseDir, cfg)
+	}
+	for _, d := range detector.Detectors() {
+		before := make(map[string]string)
+		for _, f := range flattenConfig(cfg) {
+			before[f.path] = f.value
+		}
+		if err := d.Detect(baseDir, cfg); err != nil {
+			return err
+		}
+		for _, f := range flattenConfig(cfg) {
+			if v, ok := before[f.path]; ok && v == f.value {
+				continue
+			}
+			l.origins[f.path] = detectedOrigin(d, baseDir, cfg, f.path)
+		}
+	}
+	return nil
+}
+
+// detectedOrigin asks d which file made it set the field at path
+func detectedOrigin(d detector.Detector, baseDir string, cfg *Config, path string) Origin {
+	e, ok := d.(detector.Explainer)
+	if !ok {
+		return Origin{Source: SourceDetected, Detector: fmt.Sprintf("%T", d)}
+	}
+
+	var svc *ServiceConfig
+	if rest, ok := strings.CutPrefix(path, "services["); ok {
+		if end := strings.Index(rest, "]"); end >= 0 {
+			if i, err := strconv.Atoi(rest[:end]); err == nil && i < len(cfg.Services) {
+				svc = &cfg.Services[i]
+			}
+		}
+	}
+	// The field is the last key of the path, without indexes
+	field := path
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+	if i := strings.Index(field, "["); i >= 0 {
+		field = field[:i]
+	}
+
+	file := e.Trigger(baseDir, cfg, svc, field)
+	if file == "" {
+		return Origin{Source: SourceDefault, Detector: e.Name()}
+	}
+	return Origin{Source: SourceDetected, File: file, Detector: e.Name()}
+}
+
+// flatField is a value of the config at a path
+type flatField struct {
+	path  string
+	value string
+}
+
+// flattenConfig lists the values of cfg by path, leaving out zero values as
+// Render does. Fields YAML cannot set, such as terraform's use_folders, which
+// detection works out, are left out too, so the output loads back.
+func flattenConfig(cfg *Config) []flatField {
+	var fields []flatField
+	flattenValue(reflect.ValueOf(cfg).Elem(), "", &fields)
+	return fields
+}
+
+func flattenValue(v reflect.Value, path string, fields *[]flatField) {
+	if run, ok := v.Interface().(CommandRun); ok {
+		value := run.Shell
+		if len(run.Args) > 0 {
+			value = strings.Join(run.Args, " ")
+		}
+		*fields = append(*fields, flatField{path, value})
+		return
+	}
+	// Values written as a plain string, such as a workflow step, are one field
+	if m, ok := v.Interface().(yaml.Marshaler); ok && v.Kind() == reflect.Struct {
+		if out, err := m.MarshalYAML(); err == nil {
+			if s, ok := out.(string); ok {
+				*fields = append(*fields, flatField{path, s})
+				return
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			flattenValue(v.Elem(), path, fields)
+		}
+	case reflect.Struct:
+		n := len(*fields)
+		for _, f := range yamlFieldList(v.Type()) {
+			fv := v.Field(f.index)
+			if fv.Kind() != reflect.Ptr && fv.IsZero() {
+				continue
+			}
+			flattenValue(fv, joinPath(path, f.name), fields)
+		}
+		// An empty struct, such as a module without settings, is still there
+		if len(*fields) == n && path != "" {
+			*fields = append(*fields, flatField{path, "{}"})
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			flattenValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fields)
+		}
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			flattenValue(v.MapIndex(reflect.ValueOf(k)), joinPath(path, k), fields)
+		}
+	default:
+		*fields = append(*fields, flatField{path, fmt.Sprint(v.Interface())})
+	}
+}
+
+// RenderOrigins writes cfg as YAML with the origin of each field, from
+// fields, as a comment
+func RenderOrigins(cfg *Config, fields []Field) ([]byte, error) {
+	root, err := encodeConfig(*cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	origins := make(map[string]Origin)
+	for _, f := range fields {
+		origins[f.Path] = f.Origin
+	}
+	annotate(root, "", origins)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// annotate adds the origin of each value within node as a line comment
+func annotate(node *yaml.Node, path string, origins map[string]Origin) {
+	comment := func(target *yaml.Node, p string) {
+		if o, ok := origins[p]; ok {
+			target.LineComment = o.String()
+		}
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			p := joinPath(path, key.Value)
+			if value.Kind == yaml.ScalarNode {
+				comment(value, p)
+				continue
+			}
+			// Values listed as a whole, such as run as a list, annotate the key
+			comment(key, p)
+			annotate(value, p, origins)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			if item.Kind == yaml.ScalarNode {
+				comment(item, p)
+				continue
+			}
+			annotate(item, p, origins)
+		}
+	}
+}
+
+// mappingKey returns the index of key in a mapping node, or -1
+func mappingKey(node *yaml.Node, key string) int {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
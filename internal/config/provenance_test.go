@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func fieldOrigins(fields []Field) map[string]Origin {
+	origins := make(map[string]Origin)
+	for _, f := range fields {
+		origins[f.Path] = f.Origin
+	}
+	return origins
+}
+
+func TestLoadWithOrigins(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, "base.yaml"), `envs: [dev, prod]
+services:
+  - name: tools
+    dir: tools
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "services", "api", "cleat.yaml"), `services:
+  - name: api
+    dir: .
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "cleat.yaml"), `extends: base.yaml
+include:
+  - services/*/cleat.yaml
+services:
+  - name: web
+    dir: web
+    modules:
+      - npm: {}
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "web", "package.json"), `{"scripts": {"build": "vite build"}}`)
+	writeConfigFile(t, filepath.Join(tmpDir, "docker-compose.yaml"), `services:
+  db:
+    image: postgres:16
+`)
+
+	cfg, fields, err := LoadWithOrigins(filepath.Join(tmpDir, "cleat.yaml"), true)
+	if err != nil {
+		t.Fatalf("LoadWithOrigins failed: %v", err)
+	}
+	if len(fields) == 0 {
+		t.Fatal("expected fields")
+	}
+	var names []string
+	for _, svc := range cfg.Services {
+		names = append(names, svc.Name)
+	}
+	if strings.Join(names, ",") != "tools,web,api,db" {
+		t.Fatalf("unexpected services %v", names)
+	}
+
+	origins := fieldOrigins(fields)
+	tests := []struct {
+		path string
+		want Origin
+	}{
+		{"version", Origin{Source: SourceDefault}},
+		{"envs[1]", Origin{Source: SourceDeclared, File: "base.yaml", Line: 1}},
+		{"services[0].dir", Origin{Source: SourceDeclared, File: "base.yaml", Line: 4}},
+		{"services[1].name", Origin{Source: SourceDeclared, File: "cleat.yaml", Line: 5}},
+		{"services[1].modules[0].npm.scripts[0]", Origin{Source: SourceDetected, File: filepath.Join("web", "package.json"), Detector: "npm"}},
+		{"services[1].modules[0].npm.service", Origin{Source: SourceDefault, Detector: "npm"}},
+		{"services[2].dir", Origin{Source: SourceDeclared, File: filepath.Join("services", "api", "cleat.yaml"), Line: 3}},
+		{"services[3].image", Origin{Source: SourceDetected, File: "docker-compose.yaml", Detector: "docker"}},
+		{"docker", Origin{Source: SourceDetected, File: "docker-compose.yaml", Detector: "docker"}},
+	}
+	for _, tt := range tests {
+		if got, ok := origins[tt.path]; !ok || got != tt.want {
+			t.Errorf("origin of %s = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+
+	t.Run("Declared", func(t *testing.T) {
+		_, fields, err := LoadWithOrigins(filepath.Join(tmpDir, "cleat.yaml"), false)
+		if err != nil {
+			t.Fatalf("LoadWithOrigins failed: %v", err)
+		}
+		for _, f := range fields {
+			if f.Source == SourceDetected {
+				t.Errorf("expected nothing detected, got %s from %s", f.Path, f.Detector)
+			}
+		}
+		if _, ok := fieldOrigins(fields)["services[1].modules[0].npm"]; !ok {
+			t.Errorf("expected the empty npm module as a field, got %+v", fields)
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644)
+		_, fields, err := LoadWithOrigins(filepath.Join(dir, "cleat.yaml"), true)
+		if err != nil {
+			t.Fatalf("LoadWithOrigins failed: %v", err)
+		}
+		want := Origin{Source: SourceDetected, File: "go.mod", Detector: "go"}
+		if got := fieldOrigins(fields)["services[0].name"]; got != want {
+			t.Errorf("origin of the default service = %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestRenderOrigins(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, "cleat.yaml"), `services:
+  - name: web
+    dir: web
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, ".iac", "prod", "main.tf"), "")
+
+	cfg, fields, err := LoadWithOrigins(filepath.Join(tmpDir, "cleat.yaml"), true)
+	if err != nil {
+		t.Fatalf("LoadWithOrigins failed: %v", err)
+	}
+	data, err := RenderOrigins(cfg, fields)
+	if err != nil {
+		t.Fatalf("RenderOrigins failed: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"version: 1 # default\n",
+		"- name: web # declared in cleat.yaml:2\n",
+		"- prod # detected by terraform from .iac\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	// The output loads back without warnings
+	if strings.Contains(out, "use_folders") {
+		t.Errorf("expected use_folders, which is deprecated, left out:\n%s", out)
+	}
+}
+
+func TestOriginString(t *testing.T) {
+	tests := []struct {
+		origin Origin
+		want   string
+	}{
+		{Origin{Source: SourceDeclared, File: "cleat.yaml", Line: 3}, "declared in cleat.yaml:3"},
+		{Origin{Source: SourceDetected, File: "web/package.json", Detector: "npm"}, "detected by npm from web/package.json"},
+		{Origin{Source: SourceDefault, Detector: "npm"}, "default from npm"},
+		{Origin{Source: SourceDefault}, "default"},
+	}
+	for _, tt := range tests {
+		if got := tt.origin.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
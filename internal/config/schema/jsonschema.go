@@ -0,0 +1,289 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PackageManagers lists the accepted values of python.package_manager
+var PackageManagers = []string{"uv", "pip", "poetry"}
+
+// InputTypes lists the accepted values of a command input's type
+var InputTypes = []string{"text", "choice", "confirm", "secret"}
+
+// SchemaURL is where each release publishes the JSON Schema of cleat.yaml
+const SchemaURL = "https://github.com/madewithfuture/cleat/releases/latest/download/cleat.schema.json"
+
+// WorkflowsSchemaURL is where each release publishes the JSON Schema of cleat.workflows.yaml
+const WorkflowsSchemaURL = "https://github.com/madewithfuture/cleat/releases/latest/download/cleat.workflows.schema.json"
+
+// descriptions documents every field of the config, keyed by Go type and YAML key
+var descriptions = map[string]string{
+	"Config.version":               "Version of the configuration format.",
+	"Config.extends":               "Base config file this file is layered over, such as a shared org file. Relative to this file; ~/ is the home directory.",
+	"Config.include":               "Config files or glob patterns, such as services/*/cleat.yaml, whose services, workflows and commands are added to this file's.",
+	"Config.docker":                "Use Docker Compose. Detected from docker-compose.yaml.",
+	"Config.google_cloud_platform": "Google Cloud settings.",
+	"Config.terraform":             "Terraform settings. Detected from a .iac directory.",
+	"Config.envs":                  "Environment names, used by Terraform and GCP commands. Detected from .envs/*.env.",
+	"Config.services":              "Services of the project, each in its own directory.",
+	"Config.app_yaml":              "App Engine app.yaml deployed for the whole project.",
+	"Config.workflows":             "Named sequences of commands.",
+	"Config.commands":              "Project commands, run with cleat command <name>.",
+
+	"ServiceConfig.name":       "Unique name of the service.",
+	"ServiceConfig.dir":        "Directory of the service, relative to the file that declares it.",
+	"ServiceConfig.docker":     "Run the service's tasks in Docker Compose.",
+	"ServiceConfig.dockerfile": "Dockerfile of the service, relative to its dir.",
+	"ServiceConfig.image":      "Docker image of the service. Detected from docker-compose.yaml.",
+	"ServiceConfig.command":    "Command of the service's container. Detected from docker-compose.yaml.",
+	"ServiceConfig.modules":    "Stacks within the service. Detected from files such as manage.py, package.json and go.mod.",
+	"ServiceConfig.app_yaml":   "App Engine app.yaml deployed for this service.",
+	"ServiceConfig.commands":   "Commands that run in the service directory, run with cleat command <service>:<name>.",
+	"ServiceConfig.depends_on": "Services whose tasks run before this service's. Detected from docker-compose.yaml.",
+
+	"ModuleConfig.python": "Python stack.",
+	"ModuleConfig.npm":    "NPM stack.",
+	"ModuleConfig.go":     "Go stack.",
+	"ModuleConfig.ruby":   "Ruby stack.",
+
+	"PythonConfig.enabled":         "Set to false to turn off the detected Python module.",
+	"PythonConfig.django":          "The service is a Django project. Detected from manage.py.",
+	"PythonConfig.django_service":  "Docker Compose service that runs Django tasks.",
+	"PythonConfig.package_manager": "Python package manager. Detected from uv.lock, requirements.txt or poetry.lock.",
+
+	"NpmConfig.enabled": "Set to false to turn off the detected NPM module.",
+	"NpmConfig.service": "Docker Compose service that runs NPM scripts.",
+	"NpmConfig.scripts": "NPM scripts offered as commands. Detected from package.json.",
+
+	"GoConfig.enabled": "Set to false to turn off the detected Go module.",
+	"GoConfig.service": "Docker Compose service that runs Go commands.",
+
+	"RubyConfig.enabled":       "Set to false to turn off the detected Ruby module.",
+	"RubyConfig.rails":         "The service is a Rails project. Detected from bin/rails.",
+	"RubyConfig.rails_service": "Docker Compose service that runs Rails tasks.",
+
+	"GCPConfig.project_name":                "Google Cloud project ID.",
+	"GCPConfig.account":                     "Google Cloud account to use.",
+	"GCPConfig.impersonate_service_account": "Service account to impersonate.",
+
+	"TerraformConfig.dir":  "Directory of the Terraform code, relative to the file that declares it.",
+	"TerraformConfig.envs": "Environments with their own Terraform folder.",
+	// Not a field of the struct; see deprecated
+	"TerraformConfig.use_folders": "Deprecated and ignored: folders per env are detected from the Terraform dir.",
+
+	"CommandConfig.name":        "Unique name within the project or service.",
+	"CommandConfig.description": "Shown in the task preview.",
+	"CommandConfig.run":         "A shell command line, or an argument list run without a shell.",
+	"CommandConfig.dir":         "Working directory. For service commands it is relative to the service dir.",
+	"CommandConfig.env":         "Extra environment variables.",
+	"CommandConfig.depends_on":  "Commands to run first: name for a command in the same scope or at the top level, or service:name.",
+	"CommandConfig.inputs":      "Values to ask for before running, passed to the command as CLEAT_INPUT_<KEY>.",
+
+	"CommandInput.key":     "Name of the input.",
+	"CommandInput.prompt":  "Question asked for the value.",
+	"CommandInput.default": "Value used when none is given.",
+	"CommandInput.type":    "Kind of prompt.",
+	"CommandInput.choices": "Values a choice input accepts.",
+	"CommandInput.pattern": "Regular expression the value must match.",
+
+	"Workflow.id":       "Identifier used in workflow:<id>. Derived from the name when omitted.",
+	"Workflow.name":     "Name shown in the TUI.",
+	"Workflow.params":   "Values the commands refer to as ${name}.",
+	"Workflow.commands": "Steps run in order.",
+	"Workflow.finally":  "Steps run after the commands, whether they succeeded or not.",
+
+	"WorkflowStep.id":                "Names the step so later conditions can refer to its outcome.",
+	"WorkflowStep.run":               "Command to run.",
+	"WorkflowStep.if":                "Condition on env, inputs or earlier steps; the step is skipped when it is false.",
+	"WorkflowStep.continue_on_error": "Carry on with the workflow when the step fails.",
+
+	"WorkflowParam.name":        "Name the commands refer to as ${name}.",
+	"WorkflowParam.description": "Shown when the value is asked for.",
+	"WorkflowParam.default":     "Value used when none is given.",
+	"WorkflowParam.choices":     "Values the parameter accepts.",
+}
+
+// Description returns the documentation of a config field, keyed by Go type
+// and YAML key such as "Config.services"
+func Description(key string) string {
+	return descriptions[key]
+}
+
+// required lists the fields that must be given, keyed like descriptions
+var required = map[string]bool{
+	"ServiceConfig.name": true,
+	"CommandConfig.name": true,
+	"CommandConfig.run":  true,
+	"CommandInput.key":   true,
+	"WorkflowParam.name": true,
+	"WorkflowStep.run":   true,
+}
+
+// enums lists the values a string field accepts, keyed like descriptions
+var enums = map[string][]string{
+	"PythonConfig.package_manager": PackageManagers,
+	"CommandInput.type":            InputTypes,
+}
+
+// deprecated lists the keys older files may still have, keyed like
+// descriptions. They are accepted with a warning, so the schema lists them
+// as deprecated rather than rejecting them as unknown
+var deprecated = map[string]map[string]interface{}{
+	"TerraformConfig.use_folders": {"type": "boolean"},
+}
+
+// ConfigJSONSchema returns the JSON Schema of cleat.yaml
+func ConfigJSONSchema() ([]byte, error) {
+	g := &schemaGenerator{definitions: make(map[string]interface{})}
+	// The root is the Config object itself, since keywords next to a root $ref are ignored
+	t := reflect.TypeOf(Config{})
+	g.define(t)
+	root, _ := g.definitions[t.Name()].(map[string]interface{})
+	delete(g.definitions, t.Name())
+	return g.document(SchemaURL, "cleat.yaml", root)
+}
+
+// WorkflowsJSONSchema returns the JSON Schema of cleat.workflows.yaml and
+// of the user workflow files, which are lists of workflows
+func WorkflowsJSONSchema() ([]byte, error) {
+	g := &schemaGenerator{definitions: make(map[string]interface{})}
+	root := g.schemaFor(reflect.TypeOf([]Workflow{}))
+	return g.document(WorkflowsSchemaURL, "cleat.workflows.yaml", root)
+}
+
+type schemaGenerator struct {
+	definitions map[string]interface{}
+	err         error
+}
+
+func (g *schemaGenerator) document(id, title string, root map[string]interface{}) ([]byte, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	doc := map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"$id":         id,
+		"title":       title,
+		"definitions": g.definitions,
+	}
+	for k, v := range root {
+		doc[k] = v
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// schemaFor describes t, adding struct types to the definitions
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(CommandRun{}):
+		return map[string]interface{}{"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "minItems": 1},
+		}}
+	case reflect.TypeOf(WorkflowStep{}):
+		g.define(t)
+		return map[string]interface{}{"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"$ref": "#/definitions/" + t.Name()},
+		}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		g.define(t)
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	}
+	g.err = fmt.Errorf("no JSON Schema for %s", t)
+	return nil
+}
+
+// define adds the object schema of struct type t to the definitions
+func (g *schemaGenerator) define(t reflect.Type) {
+	if _, ok := g.definitions[t.Name()]; ok {
+		return
+	}
+	// Reserve the name first so recursive types terminate
+	g.definitions[t.Name()] = nil
+
+	properties := make(map[string]interface{})
+	var req []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		key := t.Name() + "." + name
+		desc, ok := descriptions[key]
+		if !ok {
+			g.err = fmt.Errorf("no description for %s", key)
+		}
+
+		prop := g.schemaFor(f.Type)
+		if ref, ok := prop["$ref"]; ok {
+			// Keywords next to $ref are ignored in draft-07
+			prop = map[string]interface{}{"allOf": []interface{}{map[string]interface{}{"$ref": ref}}}
+		}
+		prop["description"] = desc
+		if values, ok := enums[key]; ok {
+			prop["enum"] = values
+		}
+		properties[name] = prop
+		if required[key] {
+			req = append(req, name)
+		}
+	}
+
+	def := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(req) > 0 {
+		def["required"] = req
+	}
+	for key, prop := range deprecated {
+		typeName, name, _ := strings.Cut(key, ".")
+		if typeName != t.Name() {
+			continue
+		}
+		p := map[string]interface{}{"description": descriptions[key], "deprecated": true}
+		for k, v := range prop {
+			p[k] = v
+		}
+		properties[name] = p
+	}
+	switch t {
+	case reflect.TypeOf(Config{}):
+		properties["version"].(map[string]interface{})["enum"] = []int{1}
+		properties["envs"].(map[string]interface{})["minItems"] = 1
+	}
+	g.definitions[t.Name()] = def
+}
@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the published JSON Schemas in docs/schema")
+
+// TestJSONSchemaUpToDate fails when the config structs change without the
+// published schemas being regenerated with `go test ./internal/config/schema -update`
+func TestJSONSchemaUpToDate(t *testing.T) {
+	tests := []struct {
+		file     string
+		generate func() ([]byte, error)
+	}{
+		{"cleat.schema.json", ConfigJSONSchema},
+		{"cleat.workflows.schema.json", WorkflowsJSONSchema},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := tt.generate()
+			if err != nil {
+				t.Fatalf("failed to generate schema: %v", err)
+			}
+			path := filepath.Join("..", "..", "..", "docs", "schema", tt.file)
+			if *update {
+				if err := os.WriteFile(path, data, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			published, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", path, err)
+			}
+			if string(published) != string(data) {
+				t.Errorf("%s is out of date; run: go test ./internal/config/schema -update", path)
+			}
+		})
+	}
+}
+
+func TestConfigJSONSchema(t *testing.T) {
+	data, err := ConfigJSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Properties  map[string]json.RawMessage `json:"properties"`
+		Definitions map[string]struct {
+			Properties map[string]struct {
+				Description string   `json:"description"`
+				Enum        []string `json:"enum"`
+				Deprecated  bool     `json:"deprecated"`
+			} `json:"properties"`
+			Required []string `json:"required"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	for _, key := range []string{"version", "include", "extends", "services", "workflows", "commands"} {
+		if _, ok := doc.Properties[key]; !ok {
+			t.Errorf("expected top-level property %s", key)
+		}
+	}
+	if _, ok := doc.Properties["inputs"]; ok {
+		t.Error("fields tagged yaml:\"-\" must not be in the schema")
+	}
+
+	pm := doc.Definitions["PythonConfig"].Properties["package_manager"]
+	if len(pm.Enum) != len(PackageManagers) || pm.Description == "" {
+		t.Errorf("expected package_manager to list the package managers, got %+v", pm)
+	}
+	if uf, ok := doc.Definitions["TerraformConfig"].Properties["use_folders"]; !ok || !uf.Deprecated || uf.Description == "" {
+		t.Errorf("expected the deprecated use_folders to be listed as deprecated, got %+v", uf)
+	}
+	if req := doc.Definitions["CommandConfig"].Required; len(req) != 2 || req[0] != "name" || req[1] != "run" {
+		t.Errorf("expected name and run to be required, got %v", req)
+	}
+}
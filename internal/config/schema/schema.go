@@ -1,5 +1,12 @@
 package schema
 
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
 type NpmConfig struct {
 	Enabled *bool    `yaml:"enabled,omitempty"`
 	Service string   `yaml:"service"`
@@ -44,14 +51,70 @@ type ModuleConfig struct {
 }
 
 type ServiceConfig struct {
-	Name       string         `yaml:"name"`
-	Dir        string         `yaml:"dir"`
-	Docker     *bool          `yaml:"docker,omitempty"`
-	Dockerfile string         `yaml:"dockerfile,omitempty"`
-	Image      string         `yaml:"image,omitempty"`
-	Command    string         `yaml:"command,omitempty"`
-	Modules    []ModuleConfig `yaml:"modules"`
-	AppYaml    string         `yaml:"app_yaml,omitempty"`
+	Name       string          `yaml:"name"`
+	Dir        string          `yaml:"dir"`
+	Docker     *bool           `yaml:"docker,omitempty"`
+	Dockerfile string          `yaml:"dockerfile,omitempty"`
+	Image      string          `yaml:"image,omitempty"`
+	Command    string          `yaml:"command,omitempty"`
+	Modules    []ModuleConfig  `yaml:"modules"`
+	AppYaml    string          `yaml:"app_yaml,omitempty"`
+	Commands   []CommandConfig `yaml:"commands,omitempty"`
+	// DependsOn names the services whose tasks run before this service's
+	DependsOn []string `yaml:"depends_on,omitempty"`
+
+	// SourcePath is the absolute path to the config file that declared the service
+	SourcePath string `yaml:"-"`
+}
+
+// CommandConfig is a user-defined command declared in cleat.yaml
+type CommandConfig struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Run         CommandRun        `yaml:"run"`
+	Dir         string            `yaml:"dir,omitempty"`
+	Env         map[string]string `yaml:"env,omitempty"`
+	DependsOn   []string          `yaml:"depends_on,omitempty"`
+	Inputs      []CommandInput    `yaml:"inputs,omitempty"`
+}
+
+// CommandRun is either a shell string or an argv list
+type CommandRun struct {
+	Shell string
+	Args  []string
+}
+
+func (r *CommandRun) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		r.Shell = value.Value
+		return nil
+	case yaml.SequenceNode:
+		return value.Decode(&r.Args)
+	}
+	return fmt.Errorf("line %d: run must be a string or a list of arguments", value.Line)
+}
+
+func (r CommandRun) MarshalYAML() (interface{}, error) {
+	if len(r.Args) > 0 {
+		return r.Args, nil
+	}
+	return r.Shell, nil
+}
+
+// IsEmpty reports whether nothing was given to run
+func (r CommandRun) IsEmpty() bool {
+	return r.Shell == "" && len(r.Args) == 0
+}
+
+// CommandInput declares a value a command asks for before it runs
+type CommandInput struct {
+	Key     string   `yaml:"key"`
+	Prompt  string   `yaml:"prompt,omitempty"`
+	Default string   `yaml:"default,omitempty"`
+	Type    string   `yaml:"type,omitempty"` // text (default), choice, confirm or secret
+	Choices []string `yaml:"choices,omitempty"`
+	Pattern string   `yaml:"pattern,omitempty"`
 }
 
 func (s *ServiceConfig) IsDocker() bool {
@@ -90,13 +153,79 @@ func (r *RubyConfig) IsEnabled() bool {
 }
 
 type Workflow struct {
-	ID       string   `yaml:"id" json:"id"`
-	Name     string   `yaml:"name" json:"name"`
-	Commands []string `yaml:"commands" json:"commands"`
+	ID       string          `yaml:"id" json:"id"`
+	Name     string          `yaml:"name" json:"name"`
+	Params   []WorkflowParam `yaml:"params,omitempty" json:"params,omitempty"`
+	Commands []WorkflowStep  `yaml:"commands" json:"commands"`
+	// Finally runs after the commands whether they succeeded or not
+	Finally []WorkflowStep `yaml:"finally,omitempty" json:"finally,omitempty"`
+
+	// SourcePath is the absolute path to the config file that declared the workflow
+	SourcePath string `yaml:"-" json:"-"`
+}
+
+// WorkflowStep is one command of a workflow. It is written either as the
+// command string or as a mapping that adds a condition or error handling.
+type WorkflowStep struct {
+	// ID names the step so later conditions can refer to its outcome
+	ID  string `yaml:"id,omitempty" json:"id,omitempty"`
+	Run string `yaml:"run" json:"run"`
+	// If is a condition on env, inputs or earlier steps; the step is skipped when it is false
+	If              string `yaml:"if,omitempty" json:"if,omitempty"`
+	ContinueOnError bool   `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty"`
+
+	// Line and Column locate the step in the file it was loaded from
+	Line   int `yaml:"-" json:"-"`
+	Column int `yaml:"-" json:"-"`
+}
+
+// PlainSteps returns a step for each command, with no condition or error handling
+func PlainSteps(commands ...string) []WorkflowStep {
+	steps := make([]WorkflowStep, len(commands))
+	for i, c := range commands {
+		steps[i] = WorkflowStep{Run: c}
+	}
+	return steps
+}
+
+func (s *WorkflowStep) UnmarshalYAML(value *yaml.Node) error {
+	s.Line, s.Column = value.Line, value.Column
+	if value.Kind == yaml.ScalarNode {
+		s.Run = value.Value
+		return nil
+	}
+	type plain WorkflowStep
+	if err := value.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	s.Line, s.Column = value.Line, value.Column
+	return nil
+}
+
+// MarshalYAML writes steps without options as plain strings, as older files have them
+func (s WorkflowStep) MarshalYAML() (interface{}, error) {
+	if s.ID == "" && s.If == "" && !s.ContinueOnError {
+		return s.Run, nil
+	}
+	type plain WorkflowStep
+	return plain(s), nil
+}
+
+// WorkflowParam is a named value a workflow's commands refer to as ${name}
+type WorkflowParam struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Default     string   `yaml:"default,omitempty" json:"default,omitempty"`
+	Choices     []string `yaml:"choices,omitempty" json:"choices,omitempty"`
 }
 
 type Config struct {
-	Version             int              `yaml:"version"`
+	Version int `yaml:"version"`
+	// Extends names a base config file that this file is layered over
+	Extends string `yaml:"extends,omitempty"`
+	// Include lists config files (or glob patterns) whose services,
+	// workflows and commands are added to this file's
+	Include             []string         `yaml:"include,omitempty"`
 	Docker              bool             `yaml:"docker"`
 	GoogleCloudPlatform *GCPConfig       `yaml:"google_cloud_platform,omitempty"`
 	Terraform           *TerraformConfig `yaml:"terraform,omitempty"`
@@ -104,10 +233,109 @@ type Config struct {
 	Services            []ServiceConfig  `yaml:"services"`
 	AppYaml             string           `yaml:"app_yaml,omitempty"`
 	Workflows           []Workflow       `yaml:"workflows,omitempty"`
+	Commands            []CommandConfig  `yaml:"commands,omitempty"`
 
 	// Inputs stores transient values collected during execution
 	Inputs map[string]string `yaml:"-"`
 
 	// SourcePath is the absolute path to the loaded config file
 	SourcePath string `yaml:"-"`
+
+	// LocalPath is the absolute path to the cleat.local.yaml merged over the
+	// config file, empty when there is none
+	LocalPath string `yaml:"-"`
+}
+
+// FilterServices returns a copy of the config holding only the services named
+// in include (all of them when it is empty) that are not named in exclude
+func (c *Config) FilterServices(include, exclude []string) (*Config, error) {
+	known := make(map[string]bool, len(c.Services))
+	for _, svc := range c.Services {
+		known[svc.Name] = true
+	}
+	inSet := func(names []string) (map[string]bool, error) {
+		set := make(map[string]bool, len(names))
+		for _, name := range names {
+			if !known[name] {
+				return nil, fmt.Errorf("unknown service '%s'", name)
+			}
+			set[name] = true
+		}
+		return set, nil
+	}
+	included, err := inSet(include)
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := inSet(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := *c
+	filtered.Services = nil
+	for _, svc := range c.Services {
+		if (len(included) > 0 && !included[svc.Name]) || excluded[svc.Name] {
+			continue
+		}
+		filtered.Services = append(filtered.Services, svc)
+	}
+	return &filtered, nil
+}
+
+// ServiceDependencies returns every service the named service depends on,
+// directly or through other services, in breadth-first order
+func (c *Config) ServiceDependencies(name string) []string {
+	byName := make(map[string]*ServiceConfig, len(c.Services))
+	for i := range c.Services {
+		byName[c.Services[i].Name] = &c.Services[i]
+	}
+
+	var deps []string
+	seen := map[string]bool{name: true}
+	queue := []string{name}
+	for len(queue) > 0 {
+		svc, ok := byName[queue[0]]
+		queue = queue[1:]
+		if !ok {
+			continue
+		}
+		for _, dep := range svc.DependsOn {
+			if !seen[dep] {
+				seen[dep] = true
+				deps = append(deps, dep)
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return deps
+}
+
+// FindCommand resolves a command reference as seen from svc (nil for the top
+// level). A bare name matches svc's own commands first and then the top-level
+// ones; "service:name" selects a command of that service.
+func (c *Config) FindCommand(svc *ServiceConfig, ref string) (*ServiceConfig, *CommandConfig, bool) {
+	if svcName, name, ok := strings.Cut(ref, ":"); ok {
+		for i := range c.Services {
+			if c.Services[i].Name == svcName {
+				return findCommandIn(&c.Services[i], c.Services[i].Commands, name)
+			}
+		}
+		return nil, nil, false
+	}
+	if svc != nil {
+		if s, cmd, ok := findCommandIn(svc, svc.Commands, ref); ok {
+			return s, cmd, true
+		}
+	}
+	return findCommandIn(nil, c.Commands, ref)
+}
+
+func findCommandIn(svc *ServiceConfig, cmds []CommandConfig, name string) (*ServiceConfig, *CommandConfig, bool) {
+	for i := range cmds {
+		if cmds[i].Name == name {
+			return svc, &cmds[i], true
+		}
+	}
+	return nil, nil, false
 }
@@ -1,6 +1,11 @@
 package schema
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
 
 func TestServiceConfig_IsDocker(t *testing.T) {
 	trueVal := true
@@ -116,3 +121,152 @@ func TestRubyConfig_IsEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandRun_UnmarshalYAML(t *testing.T) {
+	var cmds []CommandConfig
+	data := `
+- name: shell
+  run: make build
+- name: argv
+  run: [go, test, ./...]
+`
+	if err := yaml.Unmarshal([]byte(data), &cmds); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if cmds[0].Run.Shell != "make build" || len(cmds[0].Run.Args) != 0 {
+		t.Errorf("unexpected shell run: %+v", cmds[0].Run)
+	}
+	if len(cmds[1].Run.Args) != 3 || cmds[1].Run.Args[2] != "./..." {
+		t.Errorf("unexpected argv run: %+v", cmds[1].Run)
+	}
+
+	out, err := yaml.Marshal(cmds)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var roundTrip []CommandConfig
+	if err := yaml.Unmarshal(out, &roundTrip); err != nil {
+		t.Fatalf("round trip failed: %v", err)
+	}
+	if roundTrip[0].Run.Shell != "make build" || len(roundTrip[1].Run.Args) != 3 {
+		t.Errorf("round trip lost run values: %+v", roundTrip)
+	}
+
+	if err := yaml.Unmarshal([]byte("- name: bad\n  run: {a: b}\n"), &cmds); err == nil {
+		t.Error("expected error for a mapping run value")
+	}
+}
+
+func TestConfig_FindCommand(t *testing.T) {
+	cfg := &Config{
+		Commands: []CommandConfig{{Name: "lint"}, {Name: "seed"}},
+		Services: []ServiceConfig{
+			{Name: "web", Commands: []CommandConfig{{Name: "seed"}}},
+		},
+	}
+	web := &cfg.Services[0]
+
+	tests := []struct {
+		name    string
+		svc     *ServiceConfig
+		ref     string
+		wantSvc *ServiceConfig
+		wantCmd *CommandConfig
+		ok      bool
+	}{
+		{"TopLevel", nil, "lint", nil, &cfg.Commands[0], true},
+		{"ServiceShadowsTopLevel", web, "seed", web, &web.Commands[0], true},
+		{"ServiceFallsBackToTopLevel", web, "lint", nil, &cfg.Commands[0], true},
+		{"QualifiedService", nil, "web:seed", web, &web.Commands[0], true},
+		{"UnknownService", nil, "api:seed", nil, nil, false},
+		{"Unknown", nil, "deploy", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, cmd, ok := cfg.FindCommand(tt.svc, tt.ref)
+			if ok != tt.ok || svc != tt.wantSvc || cmd != tt.wantCmd {
+				t.Errorf("FindCommand(%q) = %v, %v, %v", tt.ref, svc, cmd, ok)
+			}
+		})
+	}
+}
+
+func TestConfig_FilterServices(t *testing.T) {
+	cfg := &Config{Services: []ServiceConfig{{Name: "web"}, {Name: "api"}, {Name: "worker"}}}
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "include", include: []string{"web", "api"}, want: []string{"web", "api"}},
+		{name: "exclude", exclude: []string{"worker"}, want: []string{"web", "api"}},
+		{name: "include and exclude", include: []string{"web", "api"}, exclude: []string{"api"}, want: []string{"web"}},
+		{name: "unknown service", include: []string{"db"}, wantErr: true},
+		{name: "unknown excluded service", exclude: []string{"db"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := cfg.FilterServices(tt.include, tt.exclude)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, svc := range filtered.Services {
+				got = append(got, svc.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if len(cfg.Services) != 3 {
+		t.Error("expected the original config to be left untouched")
+	}
+}
+
+func TestWorkflowStep_YAML(t *testing.T) {
+	input := `
+name: it
+commands:
+  - docker up
+  - id: tests
+    run: go test
+    continue_on_error: true
+  - run: echo failed
+    if: steps.tests.outcome == 'failure'
+finally:
+  - docker down
+`
+	var wf Workflow
+	if err := yaml.Unmarshal([]byte(input), &wf); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if len(wf.Commands) != 3 || wf.Commands[0].Run != "docker up" || wf.Commands[1].ID != "tests" || !wf.Commands[1].ContinueOnError {
+		t.Errorf("unexpected commands: %+v", wf.Commands)
+	}
+	if wf.Commands[2].If != "steps.tests.outcome == 'failure'" {
+		t.Errorf("unexpected condition: %q", wf.Commands[2].If)
+	}
+	if len(wf.Finally) != 1 || wf.Finally[0].Run != "docker down" {
+		t.Errorf("unexpected finally: %+v", wf.Finally)
+	}
+
+	out, err := yaml.Marshal(Workflow{Name: "plain", Commands: PlainSteps("build", "test")})
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if !strings.Contains(string(out), "- build\n") {
+		t.Errorf("expected plain steps to be written as strings, got:\n%s", out)
+	}
+}
@@ -0,0 +1,349 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a mistake at a position in a config file
+type Problem struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	// Warning marks a problem that does not stop the config from loading
+	Warning bool
+}
+
+func (p *Problem) Error() string {
+	if p.Warning {
+		return (&Problem{File: p.File, Line: p.Line, Column: p.Column, Message: "warning: " + p.Message}).Error()
+	}
+	switch {
+	case p.Line == 0:
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Column, p.Message)
+}
+
+// loader reads a config file and the files it includes, collecting problems
+type loader struct {
+	// thorough adds the checks of Validate, which look outside the config files
+	thorough bool
+	// root is the directory of the config file being loaded
+	root     string
+	problems []error
+	// warnings are the problems that do not stop the config from loading
+	warnings []error
+	// files are the config files read, in the order they were loaded
+	files []loadedFile
+	// origins, when set, records where each field of the config came from
+	origins map[string]Origin
+	// skipDetection loads only what the config files declare
+	skipDetection bool
+	// local merges the cleat.local.yaml next to the loaded file
+	local bool
+	// overrides are the values the local file set
+	overrides []override
+}
+
+// deprecatedFields are keys older files may still have, which are accepted
+// and ignored with a warning, by path in the config
+var deprecatedFields = map[string]string{
+	"terraform.use_folders": "'use_folders' in terraform is deprecated and ignored: folders per env are detected from the terraform dir",
+}
+
+// Validate loads the config file at path and returns every problem found.
+// Besides what loading checks (unknown fields, duplicate services, unknown
+// package managers), it reports dirs that do not exist and django_service
+// names that docker-compose does not define. Deprecated fields are returned
+// as problems marked Warning. The config is nil when it could not be loaded
+// at all.
+func Validate(path string) (*Config, []error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to read config file %s: %w", path, err)}
+	}
+	l := &loader{thorough: true, local: true}
+	cfg, err := l.parse(data, path)
+	if err != nil {
+		return nil, append(append(l.problems, err), l.warnings...)
+	}
+	return cfg, append(l.problems, l.warnings...)
+}
+
+func (l *loader) report(file string, node *yaml.Node, format string, args ...interface{}) {
+	p := &Problem{File: file, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		p.Line, p.Column = node.Line, node.Column
+	}
+	l.problems = append(l.problems, p)
+}
+
+// warn records a problem that does not stop the config from loading
+func (l *loader) warn(file string, node *yaml.Node, message string) {
+	p := &Problem{File: file, Line: node.Line, Column: node.Column, Message: message, Warning: true}
+	logger.Warn(message, map[string]interface{}{"file": file, "line": node.Line})
+	l.warnings = append(l.warnings, p)
+}
+
+// checkFields reports the keys of node that t has no field for
+func (l *loader) checkFields(file string, node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				continue
+			}
+			ft, ok := fields[key.Value]
+			if msg, deprecated := deprecatedFields[joinPath(path, key.Value)]; deprecated && !ok {
+				l.warn(file, key, msg)
+				continue
+			}
+			if !ok {
+				msg := fmt.Sprintf("unknown field '%s'", key.Value)
+				if path != "" {
+					msg += " in " + path
+				}
+				if s := suggest(key.Value, fields); s != "" {
+					msg += fmt.Sprintf(" (did you mean '%s'?)", s)
+				}
+				l.report(file, key, "%s", msg)
+				continue
+			}
+			l.checkFields(file, value, ft, joinPath(path, key.Value))
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			l.checkFields(file, item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			l.checkFields(file, node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of struct type t to their field types
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// suggest returns the field closest to a misspelled key, if any is close
+func suggest(key string, fields map[string]reflect.Type) string {
+	best, bestDist := "", len(key)/3+1
+	for name := range fields {
+		if d := editDistance(key, name); d < bestDist || (d == bestDist && best != "" && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
+
+// checkFile checks the services and commands of one config file, decoded
+// from root, before it is merged with the files it includes or extends
+func (l *loader) checkFile(file string, root *yaml.Node, cfg *Config) {
+	dir := filepath.Dir(file)
+	services := mappingValue(root, "services")
+	seen := make(map[string]*yaml.Node)
+	for i, svc := range cfg.Services {
+		node := sequenceItem(services, i)
+		nameNode := orNode(mappingValue(node, "name"), node)
+		if first, ok := seen[svc.Name]; ok {
+			l.report(file, nameNode, "duplicate service '%s' (first declared on line %d)", svc.Name, first.Line)
+		} else {
+			seen[svc.Name] = nameNode
+		}
+
+		modules := mappingValue(node, "modules")
+		for j, mod := range svc.Modules {
+			if mod.Python == nil {
+				continue
+			}
+			py := mappingValue(sequenceItem(modules, j), "python")
+			if pm := mod.Python.PackageManager; pm != "" && !contains(PackageManagers, pm) {
+				l.report(file, orNode(mappingValue(py, "package_manager"), py), "unknown package manager '%s', must be one of: %s", pm, strings.Join(PackageManagers, ", "))
+			}
+			if l.thorough && mod.Python.DjangoService != "" {
+				l.checkComposeService(file, orNode(mappingValue(py, "django_service"), py), mod.Python.DjangoService)
+			}
+		}
+
+		if !l.thorough {
+			continue
+		}
+		svcDir := dir
+		if svc.Dir != "" {
+			svcDir = resolveDir(dir, svc.Dir)
+			l.checkDir(file, orNode(mappingValue(node, "dir"), node), svcDir, "dir '%s' of service '%s' does not exist", svc.Dir, svc.Name)
+		}
+		cmds := mappingValue(node, "commands")
+		for j, c := range svc.Commands {
+			if c.Dir != "" {
+				cmdNode := sequenceItem(cmds, j)
+				l.checkDir(file, orNode(mappingValue(cmdNode, "dir"), cmdNode), resolveDir(svcDir, c.Dir), "dir '%s' of command '%s' does not exist", c.Dir, c.Name)
+			}
+		}
+	}
+
+	if !l.thorough {
+		return
+	}
+	cmds := mappingValue(root, "commands")
+	for i, c := range cfg.Commands {
+		if c.Dir != "" {
+			cmdNode := sequenceItem(cmds, i)
+			l.checkDir(file, orNode(mappingValue(cmdNode, "dir"), cmdNode), resolveDir(dir, c.Dir), "dir '%s' of command '%s' does not exist", c.Dir, c.Name)
+		}
+	}
+	if cfg.Terraform != nil && cfg.Terraform.Dir != "" {
+		tf := mappingValue(root, "terraform")
+		l.checkDir(file, orNode(mappingValue(tf, "dir"), tf), resolveDir(dir, cfg.Terraform.Dir), "terraform dir '%s' does not exist", cfg.Terraform.Dir)
+	}
+}
+
+func (l *loader) checkDir(file string, node *yaml.Node, dir string, format string, args ...interface{}) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		l.report(file, node, format, args...)
+	}
+}
+
+// checkComposeService reports name unless the project's docker-compose file defines it
+func (l *loader) checkComposeService(file string, node *yaml.Node, name string) {
+	for _, composeFile := range []string{"docker-compose.yaml", "docker-compose.yml"} {
+		data, err := os.ReadFile(filepath.Join(l.root, composeFile))
+		if err != nil {
+			continue
+		}
+		var compose struct {
+			Services map[string]interface{} `yaml:"services"`
+		}
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			l.report(file, node, "django_service '%s' cannot be checked: %s is invalid: %v", name, composeFile, err)
+			return
+		}
+		if _, ok := compose.Services[name]; !ok {
+			l.report(file, node, "django_service '%s' is not a service in %s", name, composeFile)
+		}
+		return
+	}
+	l.report(file, node, "django_service '%s' needs a docker-compose.yaml in %s", name, l.root)
+}
+
+func resolveDir(base, dir string) string {
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(base, dir)
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sequenceItem returns item i of a sequence node, or nil
+func sequenceItem(node *yaml.Node, i int) *yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+		return nil
+	}
+	return node.Content[i]
+}
+
+func orNode(node, fallback *yaml.Node) *yaml.Node {
+	if node != nil {
+		return node
+	}
+	return fallback
+}
+
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlError turns the line numbers of yaml.v3 errors into file positions
+func yamlError(file string, err error) error {
+	var lines []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		lines = typeErr.Errors
+	} else {
+		lines = []string{err.Error()}
+	}
+
+	var errs []error
+	for _, line := range lines {
+		m := yamlLine.FindStringSubmatch(line)
+		if m == nil {
+			errs = append(errs, &Problem{File: file, Message: strings.TrimPrefix(line, "yaml: ")})
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		errs = append(errs, &Problem{File: file, Line: n, Message: m[2]})
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_StrictErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "UnknownField",
+			content: `services:
+  - name: backend
+    modules:
+      - python:
+          package_manger: uv
+`,
+			wantErr: "cleat.yaml:5:11: unknown field 'package_manger' in services[0].modules[0].python (did you mean 'package_manager'?)",
+		},
+		{
+			name:    "UnknownTopLevelField",
+			content: "version: 1\nworkflow:\n  - id: x\n",
+			wantErr: "cleat.yaml:2:1: unknown field 'workflow' (did you mean 'workflows'?)",
+		},
+		{
+			name:    "DuplicateService",
+			content: "services:\n  - name: api\n  - name: api\n",
+			wantErr: "cleat.yaml:3:11: duplicate service 'api' (first declared on line 2)",
+		},
+		{
+			name: "UnknownPackageManager",
+			content: `services:
+  - name: backend
+    modules:
+      - python:
+          package_manager: pipenv
+`,
+			wantErr: "cleat.yaml:5:28: unknown package manager 'pipenv', must be one of: uv, pip, poetry",
+		},
+		{
+			name:    "WrongType",
+			content: "version: 1\ndocker: [yes]\n",
+			wantErr: "cleat.yaml:2: cannot unmarshal",
+		},
+		{
+			name:    "Syntax",
+			content: "version: 1\nservices:\n  - name: a\n    dir: \"b\n",
+			wantErr: "cleat.yaml:4: found unexpected end of stream",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			path := filepath.Join(tmpDir, "cleat.yaml")
+			writeConfigFile(t, path, tt.content)
+			_, err := LoadConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "cleat.yaml")
+	writeConfigFile(t, filepath.Join(tmpDir, "docker-compose.yaml"), "services:\n  web: {}\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "backend", "manage.py"), "")
+	writeConfigFile(t, path, `services:
+  - name: backend
+    dir: backend
+    modules:
+      - python:
+          django: true
+          django_service: api
+    commands:
+      - name: seed
+        dir: scripts
+        run: ./seed
+  - name: worker
+    dir: worker
+terraform:
+  dir: infra
+`)
+
+	cfg, problems := Validate(path)
+	if cfg == nil {
+		t.Fatalf("expected the config to load, got %v", problems)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.Error())
+	}
+	expected := []string{
+		path + ":7:27: django_service 'api' is not a service in docker-compose.yaml",
+		path + ":10:14: dir 'scripts' of command 'seed' does not exist",
+		path + ":13:10: dir 'worker' of service 'worker' does not exist",
+		path + ":15:8: terraform dir 'infra' does not exist",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected problems:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	// Loading skips the checks that look outside the file
+	if _, err := LoadConfig(path); err != nil {
+		t.Errorf("expected LoadConfig to succeed, got %v", err)
+	}
+}
+
+func TestValidate_ValidConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "cleat.yaml")
+	writeConfigFile(t, filepath.Join(tmpDir, "api", "go.mod"), "module api\n")
+	writeConfigFile(t, path, `version: 1
+services:
+  - name: api
+    dir: api
+    modules:
+      - go: {}
+workflows:
+  - id: ci
+    name: CI
+    commands:
+      - run: test
+        if: env.CI == 'true'
+`)
+
+	if cfg, problems := Validate(path); cfg == nil || len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+}
+
+func TestValidate_DeprecatedField(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "cleat.yaml")
+	writeConfigFile(t, path, "version: 1\nterraform:\n  use_folders: true\n")
+
+	cfg, problems := Validate(path)
+	if cfg == nil || len(problems) != 1 {
+		t.Fatalf("expected the config to load with one warning, got %v", problems)
+	}
+	expected := path + ":3:3: warning: 'use_folders' in terraform is deprecated and ignored: folders per env are detected from the terraform dir"
+	if problems[0].Error() != expected {
+		t.Errorf("expected %q, got %q", expected, problems[0].Error())
+	}
+
+	if _, err := LoadConfig(path); err != nil {
+		t.Errorf("expected LoadConfig to accept use_folders, got %v", err)
+	}
+	writeConfigFile(t, filepath.Join(tmpDir, LocalConfigName), "terraform:\n  use_folders: false\n")
+	if _, err := LoadConfig(path); err != nil {
+		t.Errorf("expected a local file to accept use_folders, got %v", err)
+	}
+}
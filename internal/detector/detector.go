@@ -1,6 +1,9 @@
 package detector
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/madewithfuture/cleat/internal/config/schema"
 )
 
@@ -9,9 +12,20 @@ type Detector interface {
 	Detect(baseDir string, cfg *schema.Config) error
 }
 
-// DetectAll runs all registered detectors against the provided config in a specific order
-func DetectAll(baseDir string, cfg *schema.Config) error {
-	detectors := []Detector{
+// Explainer is implemented by detectors that can say why they set a field,
+// so the effective config can show where each value came from
+type Explainer interface {
+	// Name identifies the detector, such as docker or npm
+	Name() string
+	// Trigger returns the file, relative to baseDir, that made the detector
+	// set field (a YAML key) of svc, or of cfg when svc is nil. It returns ""
+	// when the value is a default rather than something found on disk.
+	Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string
+}
+
+// Detectors returns the registered detectors in the order DetectAll runs them
+func Detectors() []Detector {
+	return []Detector{
 		&EnvDetector{},
 		&DockerDetector{},
 		&DjangoDetector{},
@@ -20,12 +34,48 @@ func DetectAll(baseDir string, cfg *schema.Config) error {
 		&GoDetector{},
 		&GcpDetector{},
 		&TerraformDetector{},
+		&MakeDetector{},
+		&JustDetector{},
+		&TaskfileDetector{},
 	}
+}
 
-	for _, d := range detectors {
+// DetectAll runs all registered detectors against the provided config in a specific order
+func DetectAll(baseDir string, cfg *schema.Config) error {
+	for _, d := range Detectors() {
 		if err := d.Detect(baseDir, cfg); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// serviceDir returns the directory of svc, or baseDir when svc is nil
+func serviceDir(baseDir string, svc *schema.ServiceConfig) string {
+	if svc == nil || svc.Dir == "" {
+		return baseDir
+	}
+	return filepath.Join(baseDir, svc.Dir)
+}
+
+// markerFile returns the first of names in the directory of svc, relative
+// to baseDir. A root service may also be named after a subdirectory holding
+// the file, as detection allows.
+func markerFile(baseDir string, svc *schema.ServiceConfig, names ...string) string {
+	dirs := []string{serviceDir(baseDir, svc)}
+	if svc != nil && (svc.Dir == "" || svc.Dir == ".") && svc.Name != "" {
+		dirs = append(dirs, filepath.Join(baseDir, svc.Name))
+	}
+	for _, dir := range dirs {
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				if rel, err := filepath.Rel(baseDir, path); err == nil {
+					return rel
+				}
+				return path
+			}
+		}
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package detector
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/madewithfuture/cleat/internal/config/schema"
@@ -285,6 +286,45 @@ services:
 	}
 }
 
+func TestDockerDetector_DependsOn(t *testing.T) {
+	tmpDir := t.TempDir()
+	dc := `
+services:
+  web:
+    build: ./web
+    depends_on: [api, db]
+  api:
+    build: ./api
+    depends_on:
+      lib:
+        condition: service_completed_successfully
+      db:
+        condition: service_healthy
+  lib:
+    build: ./lib
+  db:
+    image: postgres
+`
+	os.WriteFile(filepath.Join(tmpDir, "docker-compose.yaml"), []byte(dc), 0644)
+
+	cfg := &schema.Config{Services: []schema.ServiceConfig{{Name: "web", DependsOn: []string{"api"}}}}
+	if err := (&DockerDetector{}).Detect(tmpDir, cfg); err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+
+	expected := map[string][]string{
+		"web": {"api"}, // configured depends_on wins over compose
+		"api": {"db", "lib"},
+		"lib": nil,
+		"db":  nil,
+	}
+	for _, s := range cfg.Services {
+		if !reflect.DeepEqual(s.DependsOn, expected[s.Name]) {
+			t.Errorf("expected %s depends_on %v, got %v", s.Name, expected[s.Name], s.DependsOn)
+		}
+	}
+}
+
 func TestDockerDetector_Malformed(t *testing.T) {
 	tmpDir, _ := os.MkdirTemp("", "cleat-docker-malformed-*")
 	defer os.RemoveAll(tmpDir)
@@ -562,6 +602,28 @@ func TestDualStackDetector_NoServices(t *testing.T) {
 	}
 }
 
+func TestDetector_ModuleClaimedByOtherService(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte(`{"scripts": {"build": "vite build"}}`), 0644)
+
+	// web declares NPM itself, so api must not get the module as a fallback
+	cfg := &schema.Config{
+		Services: []schema.ServiceConfig{
+			{Name: "api", Dir: "."},
+			{Name: "web", Dir: ".", Modules: []schema.ModuleConfig{{Npm: &schema.NpmConfig{}}}},
+		},
+	}
+	if err := (&NpmDetector{}).Detect(tmpDir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Services[0].Modules) != 0 {
+		t.Errorf("expected api to have no modules, got %+v", cfg.Services[0].Modules)
+	}
+	if cfg.Services[1].Modules[0].Npm.Service != "web" {
+		t.Errorf("expected web's NPM module to get defaults, got %+v", cfg.Services[1].Modules[0].Npm)
+	}
+}
+
 func TestDetectionInDockerContext_AmbiguousNames(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "cleat-docker-ambiguous-*")
 	if err != nil {
@@ -707,3 +769,39 @@ func TestRootDetectionWithDockerServices(t *testing.T) {
 		t.Error("service 'backend-node' not found")
 	}
 }
+
+func TestDetectors_Explain(t *testing.T) {
+	for _, d := range Detectors() {
+		if _, ok := d.(Explainer); !ok {
+			t.Errorf("%T does not say why it sets fields", d)
+		}
+	}
+
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "web"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "web", "package.json"), []byte(`{}`), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "requirements.txt"), []byte(""), 0644)
+	cfg := &schema.Config{}
+	web := &schema.ServiceConfig{Name: "web", Dir: "web"}
+	root := &schema.ServiceConfig{Name: "default", Dir: "."}
+
+	tests := []struct {
+		name  string
+		d     Explainer
+		svc   *schema.ServiceConfig
+		field string
+		want  string
+	}{
+		{"Found in the service dir", &NpmDetector{}, web, "scripts", filepath.Join("web", "package.json")},
+		{"Default", &NpmDetector{}, web, "service", ""},
+		{"Package manager from the root", &DjangoDetector{}, web, "package_manager", "requirements.txt"},
+		{"Missing", &GoDetector{}, root, "name", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Trigger(tmpDir, cfg, tt.svc, tt.field); got != tt.want {
+				t.Errorf("Trigger() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -60,6 +60,8 @@ func (d *DjangoDetector) Detect(baseDir string, cfg *schema.Config) error {
 		if hasManagePy {
 			var matches []*schema.ServiceConfig
 			var others []*schema.ServiceConfig
+			// A service that declares the module already claims the file
+			claimed := false
 			for _, s := range svcs {
 				explicit := false
 				for _, m := range s.Modules {
@@ -69,6 +71,7 @@ func (d *DjangoDetector) Detect(baseDir string, cfg *schema.Config) error {
 					}
 				}
 				if explicit {
+					claimed = true
 					continue
 				}
 
@@ -83,7 +86,7 @@ func (d *DjangoDetector) Detect(baseDir string, cfg *schema.Config) error {
 				for _, s := range matches {
 					s.Modules = append(s.Modules, schema.ModuleConfig{Python: &schema.PythonConfig{Django: true}})
 				}
-			} else if len(others) > 0 {
+			} else if len(others) > 0 && !claimed {
 				for _, s := range others {
 					s.Modules = append(s.Modules, schema.ModuleConfig{Python: &schema.PythonConfig{Django: true}})
 				}
@@ -115,6 +118,21 @@ func (d *DjangoDetector) Detect(baseDir string, cfg *schema.Config) error {
 	return nil
 }
 
+func (d *DjangoDetector) Name() string { return "django" }
+
+func (d *DjangoDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	switch field {
+	case "django_service":
+		return ""
+	case "package_manager":
+		if f := markerFile(baseDir, svc, packageManagerFiles...); f != "" {
+			return f
+		}
+		return markerFile(baseDir, nil, packageManagerFiles...)
+	}
+	return markerFile(baseDir, svc, "manage.py")
+}
+
 func detectPackageManager(dir string, baseDir string) string {
 	// 1. Check service root
 	if pm := checkDirForPackageManager(dir); pm != "" {
@@ -131,6 +149,9 @@ func detectPackageManager(dir string, baseDir string) string {
 	return "uv"
 }
 
+// packageManagerFiles are the files checkDirForPackageManager looks for, in order
+var packageManagerFiles = []string{"uv.lock", "requirements.txt", "poetry.lock"}
+
 func checkDirForPackageManager(dir string) string {
 	if _, err := os.Stat(filepath.Join(dir, "uv.lock")); err == nil {
 		return "uv"
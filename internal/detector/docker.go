@@ -3,6 +3,7 @@ package detector
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/madewithfuture/cleat/internal/config/schema"
@@ -31,9 +32,10 @@ func (d *DockerDetector) Detect(baseDir string, cfg *schema.Config) error {
 	}
 
 	type dcService struct {
-		Build   interface{} `yaml:"build"`
-		Image   string      `yaml:"image"`
-		Command interface{} `yaml:"command"`
+		Build     interface{} `yaml:"build"`
+		Image     string      `yaml:"image"`
+		Command   interface{} `yaml:"command"`
+		DependsOn interface{} `yaml:"depends_on"`
 	}
 	var dc struct {
 		Services map[string]dcService `yaml:"services"`
@@ -42,7 +44,14 @@ func (d *DockerDetector) Detect(baseDir string, cfg *schema.Config) error {
 		return err
 	}
 
-	for name, s := range dc.Services {
+	// Services are added in name order so detection is repeatable
+	names := make([]string, 0, len(dc.Services))
+	for name := range dc.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := dc.Services[name]
 		buildContext := ""
 		dockerfile := ""
 		if s.Build != nil {
@@ -73,6 +82,8 @@ func (d *DockerDetector) Detect(baseDir string, cfg *schema.Config) error {
 			}
 		}
 
+		dependsOn := composeDependsOn(s.DependsOn)
+
 		found := false
 		for i := range cfg.Services {
 			if cfg.Services[i].Name == name {
@@ -91,6 +102,9 @@ func (d *DockerDetector) Detect(baseDir string, cfg *schema.Config) error {
 				if cfg.Services[i].Command == "" && command != "" {
 					cfg.Services[i].Command = command
 				}
+				if len(cfg.Services[i].DependsOn) == 0 && len(dependsOn) > 0 {
+					cfg.Services[i].DependsOn = dependsOn
+				}
 				found = true
 				break
 			}
@@ -103,6 +117,7 @@ func (d *DockerDetector) Detect(baseDir string, cfg *schema.Config) error {
 				Dockerfile: dockerfile,
 				Image:      s.Image,
 				Command:    command,
+				DependsOn:  dependsOn,
 			})
 		}
 	}
@@ -110,6 +125,32 @@ func (d *DockerDetector) Detect(baseDir string, cfg *schema.Config) error {
 	return nil
 }
 
+func (d *DockerDetector) Name() string { return "docker" }
+
+func (d *DockerDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	return markerFile(baseDir, nil, "docker-compose.yaml", "docker-compose.yml")
+}
+
+// composeDependsOn reads a compose depends_on entry, given either as a list
+// of service names or as a map of service names to conditions
+func composeDependsOn(v interface{}) []string {
+	var deps []string
+	switch d := v.(type) {
+	case []interface{}:
+		for _, item := range d {
+			if name, ok := item.(string); ok {
+				deps = append(deps, name)
+			}
+		}
+	case map[string]interface{}:
+		for name := range d {
+			deps = append(deps, name)
+		}
+		sort.Strings(deps)
+	}
+	return deps
+}
+
 func ptrBool(b bool) *bool {
 	return &b
 }
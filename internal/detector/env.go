@@ -26,3 +26,9 @@ func (d *EnvDetector) Detect(baseDir string, cfg *schema.Config) error {
 	}
 	return nil
 }
+
+func (d *EnvDetector) Name() string { return "env" }
+
+func (d *EnvDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	return markerFile(baseDir, nil, ".envs")
+}
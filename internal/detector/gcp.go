@@ -50,3 +50,9 @@ func (d *GcpDetector) Detect(baseDir string, cfg *schema.Config) error {
 
 	return nil
 }
+
+func (d *GcpDetector) Name() string { return "gcp" }
+
+func (d *GcpDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	return markerFile(baseDir, svc, "app.yaml")
+}
@@ -59,6 +59,8 @@ func (d *GoDetector) Detect(baseDir string, cfg *schema.Config) error {
 		if hasGoMod {
 			var matches []*schema.ServiceConfig
 			var others []*schema.ServiceConfig
+			// A service that declares the module already claims the file
+			claimed := false
 			for _, s := range svcs {
 				explicit := false
 				for _, m := range s.Modules {
@@ -68,6 +70,7 @@ func (d *GoDetector) Detect(baseDir string, cfg *schema.Config) error {
 					}
 				}
 				if explicit {
+					claimed = true
 					continue
 				}
 
@@ -82,7 +85,7 @@ func (d *GoDetector) Detect(baseDir string, cfg *schema.Config) error {
 				for _, s := range matches {
 					s.Modules = append(s.Modules, schema.ModuleConfig{Go: &schema.GoConfig{}})
 				}
-			} else if len(others) > 0 {
+			} else if len(others) > 0 && !claimed {
 				for _, s := range others {
 					s.Modules = append(s.Modules, schema.ModuleConfig{Go: &schema.GoConfig{}})
 				}
@@ -105,6 +108,15 @@ func (d *GoDetector) Detect(baseDir string, cfg *schema.Config) error {
 	return nil
 }
 
+func (d *GoDetector) Name() string { return "go" }
+
+func (d *GoDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	if field == "service" {
+		return ""
+	}
+	return markerFile(baseDir, svc, "go.mod")
+}
+
 func matchesGo(svc *schema.ServiceConfig, searchDir string) bool {
 	if svc.Dockerfile != "" {
 		dfPath := filepath.Join(searchDir, svc.Dockerfile)
@@ -0,0 +1,124 @@
+package detector
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config/schema"
+)
+
+// JustDetector exposes justfile recipes as commands
+type JustDetector struct{}
+
+// justfiles are the names just looks for
+var justfiles = []string{"justfile", "Justfile", ".justfile"}
+
+func (d *JustDetector) Detect(baseDir string, cfg *schema.Config) error {
+	return addRunnerCommands(baseDir, cfg, justfiles, "just", parseJustfile)
+}
+
+func (d *JustDetector) Name() string { return "just" }
+
+func (d *JustDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	return markerFile(baseDir, svc, justfiles...)
+}
+
+var (
+	justRecipeName = regexp.MustCompile(`^@?([A-Za-z_][A-Za-z0-9_-]*)`)
+	justQuoted     = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	justDocAttr    = regexp.MustCompile(`doc\(\s*(?:"([^"]*)"|'([^']*)')\s*\)`)
+)
+
+// parseJustfile lists the public recipes of a justfile. Descriptions come
+// from the comment just above a recipe or its [doc] attribute. Recipes that
+// start with an underscore or are marked [private] are left out.
+func parseJustfile(path string) ([]runnerTarget, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var targets []runnerTarget
+	var doc []string
+	private := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		switch {
+		case line == "" || line[0] == ' ' || line[0] == '\t':
+			// Blank lines and recipe bodies end a doc comment
+			doc = nil
+			private = false
+			continue
+		case strings.HasPrefix(line, "#!"):
+			continue
+		case strings.HasPrefix(line, "#"):
+			doc = append(doc, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			continue
+		case strings.HasPrefix(line, "["):
+			if strings.Contains(line, "private") {
+				private = true
+			}
+			if m := justDocAttr.FindStringSubmatch(line); m != nil {
+				doc = []string{m[1] + m[2]}
+			}
+			continue
+		}
+
+		header, deps, ok := splitJustRecipe(line)
+		name := justRecipeName.FindStringSubmatch(header)
+		if ok && name != nil && !private && !strings.HasPrefix(name[1], "_") {
+			targets = append(targets, runnerTarget{Name: name[1], Description: strings.Join(doc, " "), Deps: justDeps(deps)})
+		}
+		doc = nil
+		private = false
+	}
+	return targets, scanner.Err()
+}
+
+// splitJustRecipe splits a recipe line at its colon into the name with its
+// parameters and the dependencies. Assignments (":=") and other lines are
+// not recipes.
+func splitJustRecipe(line string) (header, deps string, ok bool) {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ':':
+			if strings.HasPrefix(line[i+1:], "=") {
+				return "", "", false
+			}
+			header = line[:i]
+			for _, keyword := range []string{"alias ", "set ", "export ", "import ", "mod "} {
+				if strings.HasPrefix(header, keyword) {
+					return "", "", false
+				}
+			}
+			return header, line[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// justDeps returns the recipes named in a dependency list such as
+// `build (test "unit") && notify`; recipes after && run afterwards and are left out
+func justDeps(s string) []string {
+	if i := strings.Index(s, "&&"); i >= 0 {
+		s = s[:i]
+	}
+	var deps []string
+	for _, field := range strings.Fields(justQuoted.ReplaceAllString(s, "")) {
+		if field = strings.Trim(field, "()"); field != "" {
+			deps = append(deps, field)
+		}
+	}
+	return deps
+}
@@ -0,0 +1,127 @@
+package detector
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config/schema"
+)
+
+// MakeDetector exposes Makefile targets as commands
+type MakeDetector struct{}
+
+// makefiles are the names make looks for, in order
+var makefiles = []string{"GNUmakefile", "makefile", "Makefile"}
+
+func (d *MakeDetector) Detect(baseDir string, cfg *schema.Config) error {
+	return addRunnerCommands(baseDir, cfg, makefiles, "make", parseMakefile)
+}
+
+func (d *MakeDetector) Name() string { return "make" }
+
+func (d *MakeDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	return markerFile(baseDir, svc, makefiles...)
+}
+
+// makeRule matches "targets: prerequisites ## description"; assignments
+// such as "x := y" do not match
+var makeRule = regexp.MustCompile(`^([A-Za-z0-9_.\-]+(?:[ \t]+[A-Za-z0-9_.\-]+)*)[ \t]*:([^=].*)?$`)
+
+// parseMakefile lists the explicit targets of a Makefile. Descriptions come
+// from "## text" after the prerequisites or on the lines just above the
+// rule. Special targets (.PHONY), pattern rules and files are left out.
+func parseMakefile(path string) ([]runnerTarget, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var targets []runnerTarget
+	seen := make(map[string]int)
+	phony := make(map[string]bool)
+	var doc []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "##") {
+			doc = append(doc, strings.TrimSpace(strings.TrimLeft(line, "#")))
+			continue
+		}
+		m := makeRule.FindStringSubmatch(line)
+		if m == nil {
+			doc = nil
+			continue
+		}
+
+		rest := m[2]
+		desc := strings.Join(doc, " ")
+		doc = nil
+		if strings.HasPrefix(rest, ":") {
+			// Double-colon rule, unless it is a "::=" assignment
+			rest = rest[1:]
+			if strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, ":=") {
+				continue
+			}
+		}
+		if i := strings.Index(rest, "##"); i >= 0 {
+			desc = strings.TrimSpace(rest[i+2:])
+			rest = rest[:i]
+		}
+		if i := strings.Index(rest, "#"); i >= 0 {
+			rest = rest[:i]
+		}
+		if i := strings.Index(rest, ";"); i >= 0 {
+			rest = rest[:i]
+		}
+		// Order-only prerequisites and variables are not targets to show
+		if i := strings.Index(rest, "|"); i >= 0 {
+			rest = rest[:i]
+		}
+		var deps []string
+		if strings.Contains(rest, "=") {
+			// Target-specific variable
+			rest = ""
+		}
+		for _, dep := range strings.Fields(rest) {
+			if !strings.ContainsAny(dep, "$%/") {
+				deps = append(deps, dep)
+			}
+		}
+
+		for _, name := range strings.Fields(m[1]) {
+			if name == ".PHONY" {
+				for _, dep := range deps {
+					phony[dep] = true
+				}
+			}
+			if strings.HasPrefix(name, ".") {
+				continue
+			}
+			if i, ok := seen[name]; ok {
+				// A target may be given prerequisites over several rules
+				targets[i].Deps = append(targets[i].Deps, deps...)
+				if targets[i].Description == "" {
+					targets[i].Description = desc
+				}
+				continue
+			}
+			seen[name] = len(targets)
+			targets = append(targets, runnerTarget{Name: name, Description: desc, Deps: deps})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Names with a dot are usually files, unless declared phony or documented
+	var commands []runnerTarget
+	for _, t := range targets {
+		if phony[t.Name] || t.Description != "" || !strings.Contains(t.Name, ".") {
+			commands = append(commands, t)
+		}
+	}
+	return commands, nil
+}
@@ -61,6 +61,8 @@ func (d *NpmDetector) Detect(baseDir string, cfg *schema.Config) error {
 		if hasPackageJson {
 			var matches []*schema.ServiceConfig
 			var others []*schema.ServiceConfig
+			// A service that declares the module already claims the file
+			claimed := false
 			for _, s := range svcs {
 				explicit := false
 				for _, m := range s.Modules {
@@ -70,6 +72,7 @@ func (d *NpmDetector) Detect(baseDir string, cfg *schema.Config) error {
 					}
 				}
 				if explicit {
+					claimed = true
 					continue
 				}
 
@@ -84,7 +87,7 @@ func (d *NpmDetector) Detect(baseDir string, cfg *schema.Config) error {
 				for _, s := range matches {
 					s.Modules = append(s.Modules, schema.ModuleConfig{Npm: &schema.NpmConfig{}})
 				}
-			} else if len(others) > 0 {
+			} else if len(others) > 0 && !claimed {
 				for _, s := range others {
 					s.Modules = append(s.Modules, schema.ModuleConfig{Npm: &schema.NpmConfig{}})
 				}
@@ -123,6 +126,15 @@ func (d *NpmDetector) Detect(baseDir string, cfg *schema.Config) error {
 	return nil
 }
 
+func (d *NpmDetector) Name() string { return "npm" }
+
+func (d *NpmDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	if field == "service" {
+		return ""
+	}
+	return markerFile(baseDir, svc, "package.json")
+}
+
 type packageJSON struct {
 	Scripts map[string]string `json:"scripts"`
 }
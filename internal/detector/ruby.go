@@ -60,6 +60,8 @@ func (d *RubyDetector) Detect(baseDir string, cfg *schema.Config) error {
 		if hasGemfile {
 			var matches []*schema.ServiceConfig
 			var others []*schema.ServiceConfig
+			// A service that declares the module already claims the file
+			claimed := false
 			for _, s := range svcs {
 				explicit := false
 				for _, m := range s.Modules {
@@ -69,6 +71,7 @@ func (d *RubyDetector) Detect(baseDir string, cfg *schema.Config) error {
 					}
 				}
 				if explicit {
+					claimed = true
 					continue
 				}
 
@@ -83,7 +86,7 @@ func (d *RubyDetector) Detect(baseDir string, cfg *schema.Config) error {
 				for _, s := range matches {
 					s.Modules = append(s.Modules, schema.ModuleConfig{Ruby: d.detectRubyConfig(s, searchDir)})
 				}
-			} else if len(others) > 0 {
+			} else if len(others) > 0 && !claimed {
 				for _, s := range others {
 					s.Modules = append(s.Modules, schema.ModuleConfig{Ruby: d.detectRubyConfig(s, searchDir)})
 				}
@@ -107,6 +110,18 @@ func (d *RubyDetector) Detect(baseDir string, cfg *schema.Config) error {
 	return nil
 }
 
+func (d *RubyDetector) Name() string { return "ruby" }
+
+func (d *RubyDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	switch field {
+	case "rails_service":
+		return ""
+	case "rails":
+		return markerFile(baseDir, svc, filepath.Join("bin", "rails"), filepath.Join("config", "application.rb"))
+	}
+	return markerFile(baseDir, svc, "Gemfile")
+}
+
 func (d *RubyDetector) detectRubyConfig(svc *schema.ServiceConfig, dir string) *schema.RubyConfig {
 	isRails := false
 	if _, err := os.Stat(filepath.Join(dir, "bin", "rails")); err == nil {
@@ -0,0 +1,93 @@
+package detector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config/schema"
+)
+
+// runnerTarget is a target of a task runner such as make, just or task
+type runnerTarget struct {
+	Name        string
+	Description string
+	Deps        []string
+}
+
+// addRunnerCommands exposes the targets of the first of files found in the
+// project root and in each service directory as commands that run them with
+// tool. Commands already defined under a target's name are kept.
+func addRunnerCommands(baseDir string, cfg *schema.Config, files []string, tool string, parse func(path string) ([]runnerTarget, error)) error {
+	add := func(dir string, cmds *[]schema.CommandConfig) error {
+		path := findFile(dir, files)
+		if path == "" {
+			return nil
+		}
+		targets, err := parse(path)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		existing := make(map[string]bool)
+		for _, c := range *cmds {
+			existing[c.Name] = true
+		}
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			absDir = dir
+		}
+		for _, t := range targets {
+			// ":" separates a service from its command in command references
+			name := strings.ReplaceAll(t.Name, ":", "-")
+			if existing[name] {
+				continue
+			}
+			existing[name] = true
+			*cmds = append(*cmds, schema.CommandConfig{
+				Name:        name,
+				Description: runnerDescription(t),
+				Run:         schema.CommandRun{Args: []string{tool, t.Name}},
+				Dir:         absDir,
+			})
+		}
+		return nil
+	}
+
+	if err := add(baseDir, &cfg.Commands); err != nil {
+		return err
+	}
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		if svc.Dir == "" || svc.Dir == "." {
+			continue
+		}
+		if err := add(filepath.Join(baseDir, svc.Dir), &svc.Commands); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findFile(dir string, names []string) string {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// runnerDescription is the target's description followed by its dependencies
+func runnerDescription(t runnerTarget) string {
+	if len(t.Deps) == 0 {
+		return t.Description
+	}
+	deps := "depends on " + strings.Join(t.Deps, ", ")
+	if t.Description == "" {
+		return deps
+	}
+	return t.Description + " (" + deps + ")"
+}
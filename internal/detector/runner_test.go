@@ -0,0 +1,199 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/config/schema"
+)
+
+func TestParseMakefile(t *testing.T) {
+	tmpDir := t.TempDir()
+	makefile := `GO ?= go
+VERSION := 1.0
+.PHONY: build test clean release.notes
+
+## Build the binary
+build: generate
+	$(GO) build ./...
+
+test: build lint ## Run the tests
+	$(GO) test ./...
+
+generate:
+	$(GO) generate ./...
+
+lint: export CGO_ENABLED=0
+lint:
+	golangci-lint run
+
+clean::
+	rm -rf bin
+
+release.notes:
+	./notes.sh
+
+bin/app: main.go
+	$(GO) build -o $@
+
+%.o: %.c
+	cc -c $<
+
+.DEFAULT_GOAL := build
+`
+	path := filepath.Join(tmpDir, "Makefile")
+	os.WriteFile(path, []byte(makefile), 0644)
+
+	targets, err := parseMakefile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []runnerTarget{
+		{Name: "build", Description: "Build the binary", Deps: []string{"generate"}},
+		{Name: "test", Description: "Run the tests", Deps: []string{"build", "lint"}},
+		{Name: "generate"},
+		{Name: "lint"},
+		{Name: "clean"},
+		{Name: "release.notes"},
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("expected %+v, got %+v", expected, targets)
+	}
+}
+
+func TestParseJustfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	justfile := `set shell := ["bash", "-c"]
+version := "1.0"
+alias b := build
+
+# Build the binary
+build:
+    go build ./...
+
+# Run the tests
+[group('dev')]
+test filter="": build (lint "strict") && notify
+    go test -run '{{filter}}' ./...
+
+[doc('Serve on a port')]
+@serve port="http://localhost:8080":
+    ./serve {{port}}
+
+lint mode:
+    golangci-lint run
+
+_notify:
+    echo done
+
+[private]
+notify:
+    echo done
+`
+	path := filepath.Join(tmpDir, "justfile")
+	os.WriteFile(path, []byte(justfile), 0644)
+
+	targets, err := parseJustfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []runnerTarget{
+		{Name: "build", Description: "Build the binary"},
+		{Name: "test", Description: "Run the tests", Deps: []string{"build", "lint"}},
+		{Name: "serve", Description: "Serve on a port"},
+		{Name: "lint"},
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("expected %+v, got %+v", expected, targets)
+	}
+}
+
+func TestParseTaskfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	taskfile := `version: '3'
+tasks:
+  build:
+    desc: Build the binary
+    cmds:
+      - go build ./...
+  test:
+    desc: Run the tests
+    deps: [build, {task: lint, vars: {STRICT: "1"}}]
+    cmds:
+      - go test ./...
+  lint: golangci-lint run
+  db:migrate:
+    cmds: [./migrate]
+  setup:
+    internal: true
+    cmds: [./setup]
+`
+	path := filepath.Join(tmpDir, "Taskfile.yml")
+	os.WriteFile(path, []byte(taskfile), 0644)
+
+	targets, err := parseTaskfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []runnerTarget{
+		{Name: "build", Description: "Build the binary"},
+		{Name: "test", Description: "Run the tests", Deps: []string{"build", "lint"}},
+		{Name: "lint"},
+		{Name: "db:migrate"},
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("expected %+v, got %+v", expected, targets)
+	}
+}
+
+func TestRunnerDetectors(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "Makefile"), []byte("## Build it\nbuild:\n\tgo build\n\ntest: build\n\tgo test\n"), 0644)
+	os.MkdirAll(filepath.Join(tmpDir, "api"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "api", "Taskfile.yml"), []byte("version: '3'\ntasks:\n  db:migrate:\n    desc: Migrate\n    cmds: [./migrate]\n"), 0644)
+
+	cfg := &schema.Config{
+		Commands: []schema.CommandConfig{{Name: "build", Run: schema.CommandRun{Args: []string{"./build.sh"}}}},
+		Services: []schema.ServiceConfig{{Name: "api", Dir: "api"}},
+	}
+	for _, d := range []Detector{&MakeDetector{}, &JustDetector{}, &TaskfileDetector{}} {
+		if err := d.Detect(tmpDir, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// build is already defined in cleat.yaml and is kept
+	if len(cfg.Commands) != 2 {
+		t.Fatalf("expected 2 commands, got %+v", cfg.Commands)
+	}
+	test := cfg.Commands[1]
+	if test.Name != "test" || test.Description != "depends on build" {
+		t.Errorf("unexpected command %+v", test)
+	}
+	if !reflect.DeepEqual(test.Run.Args, []string{"make", "test"}) || test.Dir != tmpDir {
+		t.Errorf("expected make test in %s, got %v in %s", tmpDir, test.Run.Args, test.Dir)
+	}
+
+	svcCmds := cfg.Services[0].Commands
+	if len(svcCmds) != 1 {
+		t.Fatalf("expected 1 service command, got %+v", svcCmds)
+	}
+	migrate := svcCmds[0]
+	if migrate.Name != "db-migrate" || migrate.Description != "Migrate" {
+		t.Errorf("unexpected command %+v", migrate)
+	}
+	if !reflect.DeepEqual(migrate.Run.Args, []string{"task", "db:migrate"}) || migrate.Dir != filepath.Join(tmpDir, "api") {
+		t.Errorf("expected task db:migrate in api, got %v in %s", migrate.Run.Args, migrate.Dir)
+	}
+}
+
+func TestRunnerDetectors_ParseError(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "Taskfile.yml"), []byte("tasks: [unclosed"), 0644)
+
+	if err := (&TaskfileDetector{}).Detect(tmpDir, &schema.Config{}); err == nil {
+		t.Error("expected an error for an invalid Taskfile")
+	}
+}
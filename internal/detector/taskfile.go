@@ -0,0 +1,76 @@
+package detector
+
+import (
+	"os"
+
+	"github.com/madewithfuture/cleat/internal/config/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// TaskfileDetector exposes Taskfile tasks as commands
+type TaskfileDetector struct{}
+
+// taskfiles are the names task looks for, in order
+var taskfiles = []string{"Taskfile.yml", "taskfile.yml", "Taskfile.yaml", "taskfile.yaml", "Taskfile.dist.yml", "Taskfile.dist.yaml"}
+
+func (d *TaskfileDetector) Detect(baseDir string, cfg *schema.Config) error {
+	return addRunnerCommands(baseDir, cfg, taskfiles, "task", parseTaskfile)
+}
+
+func (d *TaskfileDetector) Name() string { return "task" }
+
+func (d *TaskfileDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	return markerFile(baseDir, svc, taskfiles...)
+}
+
+type taskfileTask struct {
+	Desc     string      `yaml:"desc"`
+	Deps     []yaml.Node `yaml:"deps"`
+	Internal bool        `yaml:"internal"`
+}
+
+// parseTaskfile lists the tasks of a Taskfile in the order they are written,
+// leaving out internal ones. Descriptions come from desc:.
+func parseTaskfile(path string) ([]runnerTarget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	var targets []runnerTarget
+	for i := 0; i+1 < len(file.Tasks.Content); i += 2 {
+		name, value := file.Tasks.Content[i].Value, file.Tasks.Content[i+1]
+		var t taskfileTask
+		if value.Kind == yaml.MappingNode {
+			// Tasks can also be written as just a command or a list of commands
+			if err := value.Decode(&t); err != nil {
+				return nil, err
+			}
+		}
+		if t.Internal {
+			continue
+		}
+
+		var deps []string
+		for _, dep := range t.Deps {
+			if dep.Kind == yaml.ScalarNode {
+				deps = append(deps, dep.Value)
+				continue
+			}
+			var call struct {
+				Task string `yaml:"task"`
+			}
+			if err := dep.Decode(&call); err == nil && call.Task != "" {
+				deps = append(deps, call.Task)
+			}
+		}
+		targets = append(targets, runnerTarget{Name: name, Description: t.Desc, Deps: deps})
+	}
+	return targets, nil
+}
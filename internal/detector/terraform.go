@@ -114,3 +114,12 @@ func (d *TerraformDetector) Detect(baseDir string, cfg *schema.Config) error {
 
 	return nil
 }
+
+func (d *TerraformDetector) Name() string { return "terraform" }
+
+func (d *TerraformDetector) Trigger(baseDir string, cfg *schema.Config, svc *schema.ServiceConfig, field string) string {
+	if cfg.Terraform != nil && cfg.Terraform.Dir != "" {
+		return markerFile(baseDir, nil, cfg.Terraform.Dir)
+	}
+	return markerFile(baseDir, nil, ".iac")
+}
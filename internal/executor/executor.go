@@ -2,29 +2,130 @@ package executor
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/madewithfuture/cleat/internal/logger"
+	"golang.org/x/term"
 )
 
+// DefaultGracePeriod is how long a signalled process group may take to exit before it is killed
+const DefaultGracePeriod = 10 * time.Second
+
 // Executor abstracts command execution for testability
 type Executor interface {
-	Run(name string, args ...string) error
-	RunWithDir(dir string, name string, args ...string) error
+	Run(ctx context.Context, name string, args ...string) error
+	RunWithDir(ctx context.Context, dir string, name string, args ...string) error
 	Prompt(message string, defaultValue string) (string, error)
 }
 
-// ShellExecutor runs real shell commands
-type ShellExecutor struct{}
+// PromptSpec describes a typed prompt
+type PromptSpec struct {
+	Message string
+	Default string
+	// Choices, when set, restricts the answer to one of these values
+	Choices []string
+	// Confirm asks a yes/no question; the answer is "true" or "false"
+	Confirm bool
+	// Secret hides the answer while it is typed
+	Secret bool
+	// Parse validates and normalises an answer; invalid answers are asked again
+	Parse func(value string) (string, error)
+}
+
+// TypedPrompter is implemented by executors that can render choices,
+// confirmations, masked secrets and validation feedback
+type TypedPrompter interface {
+	PromptTyped(spec PromptSpec) (string, error)
+}
+
+// EnvRunner is implemented by executors that can add environment variables
+// (KEY=value) to the command's inherited environment
+type EnvRunner interface {
+	RunWithEnv(ctx context.Context, dir string, env []string, name string, args ...string) error
+}
+
+// InterruptedError is returned (and used as the context cause) when execution
+// is stopped by a signal. It matches context.Canceled with errors.Is.
+type InterruptedError struct {
+	Signal os.Signal
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("interrupted by %s", e.Signal)
+}
+
+func (e *InterruptedError) Is(target error) bool {
+	return target == context.Canceled
+}
+
+// IsCancelled reports whether err means execution was cancelled rather than failed
+func IsCancelled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// CancelCause returns why ctx was cancelled. Custom causes are wrapped so that
+// IsCancelled still recognises them; deadlines are left as failures.
+func CancelCause(ctx context.Context) error {
+	cause := context.Cause(ctx)
+	if cause == nil || errors.Is(cause, context.Canceled) || errors.Is(cause, context.DeadlineExceeded) {
+		return cause
+	}
+	return fmt.Errorf("%w: %w", context.Canceled, cause)
+}
+
+// NotifyContext returns a context that is cancelled with an *InterruptedError
+// cause when the process receives SIGINT or SIGTERM. After the first signal
+// the handler is removed, so a second one terminates cleat as usual.
+func NotifyContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-sigCh:
+			logger.Warn("received signal, cancelling", map[string]interface{}{"signal": sig.String()})
+			signal.Stop(sigCh)
+			cancel(&InterruptedError{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigCh)
+		cancel(context.Canceled)
+	}
+}
+
+// ShellExecutor runs real shell commands. Each command runs in its own
+// process group so cancellation reaches every process it spawned.
+type ShellExecutor struct {
+	// GracePeriod between forwarding the signal and killing the group; DefaultGracePeriod when zero
+	GracePeriod time.Duration
+}
+
+func (e *ShellExecutor) Run(ctx context.Context, name string, args ...string) error {
+	return e.RunWithDir(ctx, "", name, args...)
+}
 
-func (e *ShellExecutor) Run(name string, args ...string) error {
-	return e.RunWithDir("", name, args...)
+func (e *ShellExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
+	return e.RunWithEnv(ctx, dir, nil, name, args...)
 }
 
-func (e *ShellExecutor) RunWithDir(dir string, name string, args ...string) error {
+// RunWithEnv runs the command in dir with env added to the current environment
+func (e *ShellExecutor) RunWithEnv(ctx context.Context, dir string, env []string, name string, args ...string) error {
+	if ctx.Err() != nil {
+		return CancelCause(ctx)
+	}
+
 	logger.Debug("executing command", map[string]interface{}{
 		"dir":  dir,
 		"name": name,
@@ -38,20 +139,94 @@ func (e *ShellExecutor) RunWithDir(dir string, name string, args ...string) erro
 	}
 	cmd := exec.Command(name, args...)
 	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
-	err := cmd.Run()
-	if err != nil {
-		logger.Error("command execution failed", err, map[string]interface{}{
-			"dir":  dir,
-			"name": name,
-			"args": args,
+	foreground := setProcessGroup(cmd)
+
+	err := e.wait(ctx, cmd)
+	if foreground {
+		releaseForeground()
+	}
+	if err == nil {
+		return nil
+	}
+
+	if sig, ok := interruptedBy(cmd.ProcessState, foreground); ok && ctx.Err() == nil {
+		// Ctrl-C went straight to the child's foreground process group
+		err = &InterruptedError{Signal: sig}
+	}
+	if IsCancelled(err) {
+		logger.Warn("command cancelled", map[string]interface{}{
+			"dir":    dir,
+			"name":   name,
+			"args":   args,
+			"reason": err.Error(),
 		})
+		return err
 	}
+
+	logger.Error("command execution failed", err, map[string]interface{}{
+		"dir":  dir,
+		"name": name,
+		"args": args,
+	})
 	return err
 }
 
+// wait runs cmd until it exits or ctx is cancelled. On cancellation the
+// signal that caused it (SIGINT by default) is forwarded to the process
+// group, which is killed if it is still running after the grace period.
+func (e *ShellExecutor) wait(ctx context.Context, cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	var sig os.Signal = os.Interrupt
+	var interrupted *InterruptedError
+	if errors.As(context.Cause(ctx), &interrupted) {
+		sig = interrupted.Signal
+	}
+
+	grace := e.GracePeriod
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+	logger.Debug("forwarding signal to process group", map[string]interface{}{
+		"pid":    cmd.Process.Pid,
+		"signal": sig.String(),
+		"grace":  grace.String(),
+	})
+	if err := signalGroup(cmd, sig); err != nil {
+		logger.Warn("failed to signal process group", map[string]interface{}{"pid": cmd.Process.Pid, "error": err.Error()})
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		logger.Warn("process group did not exit within grace period, killing", map[string]interface{}{"pid": cmd.Process.Pid})
+		if err := killGroup(cmd); err != nil {
+			logger.Warn("failed to kill process group", map[string]interface{}{"pid": cmd.Process.Pid, "error": err.Error()})
+		}
+		<-done
+	}
+	return CancelCause(ctx)
+}
+
 func (e *ShellExecutor) Prompt(message string, defaultValue string) (string, error) {
 	if defaultValue != "" {
 		fmt.Printf("%s [%s]: ", message, defaultValue)
@@ -71,5 +246,73 @@ func (e *ShellExecutor) Prompt(message string, defaultValue string) (string, err
 	return input, nil
 }
 
+// PromptTyped renders spec on the terminal, asking again until the answer parses
+func (e *ShellExecutor) PromptTyped(spec PromptSpec) (string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		input, err := e.readAnswer(reader, spec)
+		if err != nil {
+			logger.Error("failed to read user input", err, nil)
+			return "", err
+		}
+		if input == "" {
+			input = spec.Default
+		}
+		if spec.Parse == nil {
+			return input, nil
+		}
+		val, err := spec.Parse(input)
+		if err == nil {
+			return val, nil
+		}
+		fmt.Printf("Invalid answer: %v\n", err)
+	}
+}
+
+func (e *ShellExecutor) readAnswer(reader *bufio.Reader, spec PromptSpec) (string, error) {
+	switch {
+	case len(spec.Choices) > 0:
+		fmt.Println(spec.Message + ":")
+		for i, c := range spec.Choices {
+			fmt.Printf("  %d) %s\n", i+1, c)
+		}
+		if spec.Default != "" {
+			fmt.Printf("Select [%s]: ", spec.Default)
+		} else {
+			fmt.Print("Select: ")
+		}
+	case spec.Confirm:
+		hint := "y/n"
+		switch spec.Default {
+		case "true":
+			hint = "Y/n"
+		case "false":
+			hint = "y/N"
+		}
+		fmt.Printf("%s [%s]: ", spec.Message, hint)
+	case spec.Default != "" && !spec.Secret:
+		fmt.Printf("%s [%s]: ", spec.Message, spec.Default)
+	default:
+		fmt.Printf("%s: ", spec.Message)
+	}
+
+	if spec.Secret {
+		if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+			b, err := term.ReadPassword(fd)
+			fmt.Println()
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(string(b)), nil
+		}
+	}
+
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(input), nil
+}
+
 // Default is the production executor
 var Default Executor = &ShellExecutor{}
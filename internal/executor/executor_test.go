@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"testing"
 )
@@ -10,13 +12,13 @@ func TestShellExecutor_Run(t *testing.T) {
 
 	// Test basic command (using 'true' as a reliable cross-platform-ish NOOP command on Unix)
 	// On Windows this might fail, but we're on Linux as per prompt.
-	err := e.Run("true")
+	err := e.Run(context.Background(), "true")
 	if err != nil {
 		t.Errorf("expected no error running 'true', got %v", err)
 	}
 
 	// Test failing command
-	err = e.Run("false")
+	err = e.Run(context.Background(), "false")
 	if err == nil {
 		t.Error("expected error running 'false', got nil")
 	}
@@ -29,7 +31,7 @@ func TestShellExecutor_RunWithDir(t *testing.T) {
 
 	// Test command in specific dir
 	// We'll use 'ls' and check output if we could capture it, but for now we just check error.
-	err := e.RunWithDir(tmpDir, "ls")
+	err := e.RunWithDir(context.Background(), tmpDir, "ls")
 	if err != nil {
 		t.Errorf("expected no error running 'ls' in tmpDir, got %v", err)
 	}
@@ -37,7 +39,7 @@ func TestShellExecutor_RunWithDir(t *testing.T) {
 
 func TestShellExecutor_RunWithDir_Error(t *testing.T) {
 	e := &ShellExecutor{}
-	err := e.RunWithDir("/non/existent/path/for/cleat", "ls")
+	err := e.RunWithDir(context.Background(), "/non/existent/path/for/cleat", "ls")
 	if err == nil {
 		t.Error("expected error for non-existent directory, got nil")
 	}
@@ -116,8 +118,80 @@ func TestShellExecutor_Prompt(t *testing.T) {
 	})
 }
 
+func TestShellExecutor_PromptTyped(t *testing.T) {
+	e := &ShellExecutor{}
+	withStdin := func(t *testing.T, input string) {
+		oldStdin := os.Stdin
+		r, w, _ := os.Pipe()
+		os.Stdin = r
+		w.Write([]byte(input))
+		w.Close()
+		t.Cleanup(func() { os.Stdin = oldStdin })
+	}
+	choices := []string{"dev", "prod"}
+	parseChoice := func(v string) (string, error) {
+		for i, c := range choices {
+			if v == c || v == fmt.Sprint(i+1) {
+				return c, nil
+			}
+		}
+		return "", fmt.Errorf("not a choice")
+	}
+
+	t.Run("ChoiceByIndex", func(t *testing.T) {
+		withStdin(t, "2\n")
+		val, err := e.PromptTyped(PromptSpec{Message: "env", Choices: choices, Parse: parseChoice})
+		if err != nil {
+			t.Fatalf("PromptTyped failed: %v", err)
+		}
+		if val != "prod" {
+			t.Errorf("expected 'prod', got %q", val)
+		}
+	})
+
+	t.Run("RetriesInvalidAnswer", func(t *testing.T) {
+		withStdin(t, "staging\ndev\n")
+		val, err := e.PromptTyped(PromptSpec{Message: "env", Choices: choices, Parse: parseChoice})
+		if err != nil {
+			t.Fatalf("PromptTyped failed: %v", err)
+		}
+		if val != "dev" {
+			t.Errorf("expected 'dev' after retry, got %q", val)
+		}
+	})
+
+	t.Run("DefaultValue", func(t *testing.T) {
+		withStdin(t, "\n")
+		val, err := e.PromptTyped(PromptSpec{Message: "proceed", Confirm: true, Default: "true"})
+		if err != nil {
+			t.Fatalf("PromptTyped failed: %v", err)
+		}
+		if val != "true" {
+			t.Errorf("expected default 'true', got %q", val)
+		}
+	})
+
+	t.Run("EOFAfterInvalidAnswer", func(t *testing.T) {
+		withStdin(t, "staging\n")
+		if _, err := e.PromptTyped(PromptSpec{Message: "env", Choices: choices, Parse: parseChoice}); err == nil {
+			t.Error("expected error once input is exhausted")
+		}
+	})
+}
+
 func TestDefaultExecutor(t *testing.T) {
 	if Default == nil {
 		t.Error("Default executor should not be nil")
 	}
 }
+
+func TestShellExecutor_RunCancelledBeforeStart(t *testing.T) {
+	e := &ShellExecutor{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := e.Run(ctx, "true")
+	if !IsCancelled(err) {
+		t.Errorf("expected cancellation error, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build !unix
+
+package executor
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op where process groups are unavailable
+func setProcessGroup(cmd *exec.Cmd) bool {
+	return false
+}
+
+func releaseForeground() {}
+
+func signalGroup(cmd *exec.Cmd, sig os.Signal) error {
+	if err := cmd.Process.Signal(sig); err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
+
+func killGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+func interruptedBy(state *os.ProcessState, foreground bool) (os.Signal, bool) {
+	return nil, false
+}
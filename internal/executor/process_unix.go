@@ -0,0 +1,87 @@
+//go:build unix
+
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// foregroundMu guards the terminal: only one child group may own it at a time
+var (
+	foregroundMu    sync.Mutex
+	foregroundTaken bool
+)
+
+// setProcessGroup starts cmd in a new process group. When stdin is a terminal
+// and no other command owns it, the group is also made the terminal's
+// foreground group so interactive programs can read input and Ctrl-C reaches
+// them directly. It reports whether foreground was claimed.
+func setProcessGroup(cmd *exec.Cmd) bool {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return false
+	}
+
+	foregroundMu.Lock()
+	defer foregroundMu.Unlock()
+	if foregroundTaken {
+		return false
+	}
+	foregroundTaken = true
+	cmd.SysProcAttr.Foreground = true
+	cmd.SysProcAttr.Ctty = fd
+	return true
+}
+
+// releaseForeground hands the terminal back to cleat's own process group
+func releaseForeground() {
+	foregroundMu.Lock()
+	defer foregroundMu.Unlock()
+	foregroundTaken = false
+
+	// tcsetpgrp from a background group raises SIGTTOU, which would stop us
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	_ = unix.IoctlSetPointerInt(int(os.Stdin.Fd()), unix.TIOCSPGRP, unix.Getpgrp())
+}
+
+func signalGroup(cmd *exec.Cmd, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		s = syscall.SIGINT
+	}
+	return syscall.Kill(-cmd.Process.Pid, s)
+}
+
+func killGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// interruptedBy reports whether the process was stopped by an interactive
+// interrupt. Exit status 130 (128+SIGINT) only counts when the child owned
+// the terminal, since that is the only way a Ctrl-C could have reached it.
+func interruptedBy(state *os.ProcessState, foreground bool) (os.Signal, bool) {
+	if state == nil {
+		return nil, false
+	}
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if !ok {
+		return nil, false
+	}
+	if ws.Signaled() && (ws.Signal() == syscall.SIGINT || ws.Signal() == syscall.SIGTERM) {
+		return ws.Signal(), true
+	}
+	if foreground && ws.Exited() && ws.ExitStatus() == 130 {
+		return syscall.SIGINT, true
+	}
+	return nil, false
+}
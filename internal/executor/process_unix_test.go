@@ -0,0 +1,89 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShellExecutor_CancelSignalsProcessGroup(t *testing.T) {
+	e := &ShellExecutor{GracePeriod: 5 * time.Second}
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	// The grandchild sleep only dies if the whole process group is signalled
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	go func() {
+		for i := 0; i < 100; i++ {
+			if _, err := os.Stat(pidFile); err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		cancel(&InterruptedError{Signal: syscall.SIGTERM})
+	}()
+
+	start := time.Now()
+	err := e.Run(ctx, "sh", "-c", "sleep 30 & echo $! > "+pidFile+"; wait")
+	if time.Since(start) > 4*time.Second {
+		t.Errorf("expected command to stop promptly, took %v", time.Since(start))
+	}
+
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) || interrupted.Signal != syscall.SIGTERM {
+		t.Errorf("expected InterruptedError for SIGTERM, got %v", err)
+	}
+	if !IsCancelled(err) {
+		t.Error("expected InterruptedError to count as cancelled")
+	}
+
+	data, readErr := os.ReadFile(pidFile)
+	if readErr != nil {
+		t.Fatalf("failed to read grandchild pid: %v", readErr)
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	time.Sleep(100 * time.Millisecond)
+	if processAlive(pid) {
+		syscall.Kill(pid, syscall.SIGKILL)
+		t.Error("expected grandchild process to be terminated with its group")
+	}
+}
+
+// processAlive reports whether pid is running; reparented zombies count as dead
+func processAlive(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat))
+	return len(fields) < 3 || fields[2] != "Z"
+}
+
+func TestShellExecutor_KillsAfterGracePeriod(t *testing.T) {
+	e := &ShellExecutor{GracePeriod: 200 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	// Ignore SIGINT so only the kill after the grace period can stop it
+	err := e.Run(ctx, "sh", "-c", "trap '' INT; sleep 30")
+	if !IsCancelled(err) {
+		t.Errorf("expected cancellation error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected kill after grace period, took %v", elapsed)
+	}
+}
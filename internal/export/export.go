@@ -0,0 +1,228 @@
+// Package export writes resolved cleat commands as plain files for machines
+// without cleat: a Makefile, a justfile, a GitHub Actions workflow or a
+// GitLab CI configuration.
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/strategy"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats lists the supported export formats
+var Formats = []string{"makefile", "justfile", "github-actions", "gitlab-ci"}
+
+// Target is a named sequence of commands: a make target, a just recipe or a CI job
+type Target struct {
+	Name        string
+	Description string
+	Commands    []strategy.PlannedCommand
+}
+
+const header = "Generated by `cleat export` from cleat.yaml. Change cleat.yaml and export again instead of editing this file."
+const inputsNote = "Inputs without a value are read from CLEAT_INPUT_<KEY> environment variables."
+
+var nonName = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// TargetName turns a command or workflow name into a name every format accepts
+func TargetName(name string) string {
+	n := strings.Trim(nonName.ReplaceAllString(name, "-"), "-")
+	if n == "" || (n[0] >= '0' && n[0] <= '9') {
+		n = "_" + n
+	}
+	return n
+}
+
+// Render writes targets to w in format
+func Render(w io.Writer, format string, targets []Target) error {
+	switch format {
+	case "makefile":
+		return renderMakefile(w, targets)
+	case "justfile":
+		return renderJustfile(w, targets)
+	case "github-actions":
+		return renderYAML(w, githubActions(targets))
+	case "gitlab-ci":
+		return renderYAML(w, gitlabCI(targets))
+	default:
+		return fmt.Errorf("invalid export format '%s', must be one of: %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// shellLine renders c as one shell command, changing into its directory first
+func shellLine(c strategy.PlannedCommand) string {
+	if c.Dir == "" {
+		return c.String()
+	}
+	return strategy.PlannedCommand{Args: []string{"cd", c.Dir}}.String() + " && " + c.String()
+}
+
+// multiline reports whether a command of t spans several lines, which make
+// and just recipes cannot express
+func multiline(t Target) bool {
+	for _, c := range t.Commands {
+		if strings.Contains(shellLine(c), "\n") {
+			return true
+		}
+	}
+	return false
+}
+
+func renderMakefile(w io.Writer, targets []Target) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n# %s\n", header, inputsNote)
+
+	var phony []string
+	for _, t := range targets {
+		if !multiline(t) {
+			phony = append(phony, t.Name)
+		}
+	}
+	if len(phony) > 0 {
+		fmt.Fprintf(&b, "\n.PHONY: %s\n", strings.Join(phony, " "))
+	}
+
+	for _, t := range targets {
+		b.WriteString("\n")
+		if multiline(t) {
+			fmt.Fprintf(&b, "# %s: skipped, a command spans several lines\n", t.Name)
+			continue
+		}
+		if t.Description != "" {
+			fmt.Fprintf(&b, "# %s\n", strategy.ShowReferences(t.Description))
+		}
+		fmt.Fprintf(&b, "%s:\n", t.Name)
+		for _, c := range t.Commands {
+			fmt.Fprintf(&b, "\t%s\n", strings.ReplaceAll(shellLine(c), "$", "$$"))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func renderJustfile(w io.Writer, targets []Target) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n# %s\n", header, inputsNote)
+
+	for _, t := range targets {
+		b.WriteString("\n")
+		if multiline(t) {
+			fmt.Fprintf(&b, "# %s: skipped, a command spans several lines\n", t.Name)
+			continue
+		}
+		if t.Description != "" {
+			fmt.Fprintf(&b, "# %s\n", strategy.ShowReferences(t.Description))
+		}
+		fmt.Fprintf(&b, "%s:\n", t.Name)
+		for _, c := range t.Commands {
+			fmt.Fprintf(&b, "    %s\n", strings.ReplaceAll(shellLine(c), "{{", "{{{{"))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// githubActions builds a workflow that is started by hand for one target
+func githubActions(targets []Target) yamlMap {
+	var names []string
+	for _, t := range targets {
+		names = append(names, t.Name)
+	}
+
+	jobs := yamlMap{}
+	for _, t := range targets {
+		steps := []interface{}{yamlMap{{"uses", "actions/checkout@v4"}}}
+		for _, c := range t.Commands {
+			step := yamlMap{{"name", strategy.ShowReferences(c.Task)}}
+			switch {
+			case c.Dir == "":
+				step = append(step, yamlItem{"run", c.String()})
+			case strategy.ShowReferences(c.Dir) != c.Dir:
+				// working-directory is not expanded by a shell
+				step = append(step, yamlItem{"run", shellLine(c)})
+			default:
+				step = append(step, yamlItem{"run", c.String()}, yamlItem{"working-directory", c.Dir})
+			}
+			steps = append(steps, step)
+		}
+		job := yamlMap{}
+		if t.Description != "" {
+			job = append(job, yamlItem{"name", strategy.ShowReferences(t.Description)})
+		}
+		job = append(job,
+			yamlItem{"if", fmt.Sprintf("inputs.target == '%s'", t.Name)},
+			yamlItem{"runs-on", "ubuntu-latest"},
+			yamlItem{"steps", steps},
+		)
+		jobs = append(jobs, yamlItem{t.Name, job})
+	}
+
+	dispatch := yamlMap{{"inputs", yamlMap{{"target", yamlMap{
+		{"description", "Command or workflow to run"},
+		{"required", true},
+		{"type", "choice"},
+		{"options", names},
+	}}}}}
+	return yamlMap{
+		{"name", "cleat"},
+		{"on", yamlMap{{"workflow_dispatch", dispatch}}},
+		{"jobs", jobs},
+	}
+}
+
+// gitlabCI builds a manual job for each target
+func gitlabCI(targets []Target) yamlMap {
+	jobs := yamlMap{}
+	for _, t := range targets {
+		var script []string
+		for _, c := range t.Commands {
+			line := c.String()
+			if c.Dir != "" {
+				// Script lines share a shell, so stay in the project root
+				line = "(" + shellLine(c) + ")"
+			}
+			script = append(script, line)
+		}
+		jobs = append(jobs, yamlItem{t.Name, yamlMap{
+			{"when", "manual"},
+			{"script", script},
+		}})
+	}
+	return jobs
+}
+
+func renderYAML(w io.Writer, doc yamlMap) error {
+	if _, err := fmt.Fprintf(w, "# %s\n# %s\n", header, inputsNote); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return enc.Close()
+}
+
+// yamlMap is a YAML mapping that keeps its keys in order
+type yamlMap []yamlItem
+
+type yamlItem struct {
+	Key   string
+	Value interface{}
+}
+
+func (m yamlMap) MarshalYAML() (interface{}, error) {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for _, item := range m {
+		v := &yaml.Node{}
+		if err := v.Encode(item.Value); err != nil {
+			return nil, err
+		}
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item.Key}, v)
+	}
+	return n, nil
+}
@@ -0,0 +1,180 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/strategy"
+	"gopkg.in/yaml.v3"
+)
+
+var testTargets = []Target{
+	{
+		Name: "build",
+		Commands: []strategy.PlannedCommand{
+			{Task: "npm:run:build", Dir: "web", Args: []string{"npm", "run", "build"}},
+			{Task: "go:build", Args: []string{"op", "run", "--", "go", "build", "./..."}},
+		},
+	},
+	{
+		Name:        "seed",
+		Description: "Load fixtures",
+		Commands:    []strategy.PlannedCommand{{Task: "command:seed", Args: []string{"sh", "-c", `echo "$HOME" {{x}}`}}},
+	},
+	{
+		Name:     "script",
+		Commands: []strategy.PlannedCommand{{Task: "command:script", Args: []string{"sh", "-c", "echo one\necho two"}}},
+	},
+}
+
+func TestRenderMakefile(t *testing.T) {
+	var b strings.Builder
+	if err := Render(&b, "makefile", testTargets); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		".PHONY: build seed\n",
+		"build:\n\tcd web && npm run build\n\top run -- go build ./...\n",
+		"# Load fixtures\nseed:\n\tsh -c 'echo \"$$HOME\" {{x}}'\n",
+		"# script: skipped, a command spans several lines\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected Makefile to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderJustfile(t *testing.T) {
+	var b strings.Builder
+	if err := Render(&b, "justfile", testTargets); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"build:\n    cd web && npm run build\n    op run -- go build ./...\n",
+		"seed:\n    sh -c 'echo \"$HOME\" {{{{x}}'\n",
+		"# script: skipped",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected justfile to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderGitHubActions(t *testing.T) {
+	var b strings.Builder
+	if err := Render(&b, "github-actions", testTargets); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc struct {
+		On struct {
+			WorkflowDispatch struct {
+				Inputs struct {
+					Target struct {
+						Options []string `yaml:"options"`
+					} `yaml:"target"`
+				} `yaml:"inputs"`
+			} `yaml:"workflow_dispatch"`
+		} `yaml:"on"`
+		Jobs map[string]struct {
+			If    string `yaml:"if"`
+			Steps []struct {
+				Uses             string `yaml:"uses"`
+				Run              string `yaml:"run"`
+				WorkingDirectory string `yaml:"working-directory"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal([]byte(b.String()), &doc); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, b.String())
+	}
+	if strings.Join(doc.On.WorkflowDispatch.Inputs.Target.Options, ",") != "build,seed,script" {
+		t.Errorf("unexpected target options %v", doc.On.WorkflowDispatch.Inputs.Target.Options)
+	}
+	build := doc.Jobs["build"]
+	if build.If != "inputs.target == 'build'" || len(build.Steps) != 3 {
+		t.Fatalf("unexpected build job %+v", build)
+	}
+	if build.Steps[1].Run != "npm run build" || build.Steps[1].WorkingDirectory != "web" {
+		t.Errorf("expected npm step to run in web, got %+v", build.Steps[1])
+	}
+	if doc.Jobs["script"].Steps[1].Run != "sh -c 'echo one\necho two'" {
+		t.Errorf("expected multi-line command to be kept, got %q", doc.Jobs["script"].Steps[1].Run)
+	}
+}
+
+func TestRenderGitLabCI(t *testing.T) {
+	var b strings.Builder
+	if err := Render(&b, "gitlab-ci", testTargets); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var doc map[string]struct {
+		When   string   `yaml:"when"`
+		Script []string `yaml:"script"`
+	}
+	if err := yaml.Unmarshal([]byte(b.String()), &doc); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, b.String())
+	}
+	build := doc["build"]
+	if build.When != "manual" || len(build.Script) != 2 || build.Script[0] != "(cd web && npm run build)" {
+		t.Errorf("unexpected build job %+v", build)
+	}
+}
+
+func TestRenderInvalidFormat(t *testing.T) {
+	if err := Render(&strings.Builder{}, "ant", nil); err == nil || !strings.Contains(err.Error(), "must be one of") {
+		t.Errorf("expected invalid format error, got %v", err)
+	}
+}
+
+func TestTargetName(t *testing.T) {
+	tests := map[string]string{
+		"build":        "build",
+		"web:seed":     "web-seed",
+		"Deploy Prod!": "Deploy-Prod",
+		"1st":          "_1st",
+	}
+	for in, want := range tests {
+		if got := TargetName(in); got != want {
+			t.Errorf("TargetName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRenderInputReferences(t *testing.T) {
+	// PlanExport leaves unset inputs as references delimited by NUL bytes
+	ref := "\x00CLEAT_INPUT_ENV\x00"
+	targets := []Target{{
+		Name:        "deploy",
+		Description: "Deploy to " + ref,
+		Commands: []strategy.PlannedCommand{
+			{Task: "shell:echo " + ref, Args: []string{"sh", "-c", "echo " + ref}},
+			{Task: "command:push", Dir: "deploy/" + ref, Env: []string{"CLEAT_INPUT_ENV=" + ref}, Args: []string{"./push"}},
+		},
+	}}
+	for _, format := range Formats {
+		var b strings.Builder
+		if err := Render(&b, format, targets); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if strings.Contains(b.String(), "\x00") {
+			t.Errorf("%s: expected no NUL byte in the output, got:\n%q", format, b.String())
+		}
+		if !strings.Contains(b.String(), "${CLEAT_INPUT_ENV}") {
+			t.Errorf("%s: expected the input read from the environment, got:\n%s", format, b.String())
+		}
+	}
+
+	var b strings.Builder
+	Render(&b, "github-actions", targets)
+	for _, want := range []string{
+		"name: shell:echo ${CLEAT_INPUT_ENV}\n",
+		`run: cd 'deploy/'"${CLEAT_INPUT_ENV}" && ./push`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected the workflow to contain %q, got:\n%s", want, b.String())
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/madewithfuture/cleat/internal/config"
 	"github.com/madewithfuture/cleat/internal/logger"
+	"github.com/madewithfuture/cleat/internal/settings"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,15 +17,15 @@ type HistoryEntry struct {
 	Command       string            `json:"command"`
 	Inputs        map[string]string `json:"inputs,omitempty"`
 	Success       bool              `json:"success"`
+	Cancelled     bool              `json:"cancelled,omitempty"`
 	WorkflowRunID string            `json:"workflow_run_id,omitempty"`
+	// Step is set on the entries of a workflow's steps: 1 for its first
+	// step, counting on through its finally steps
+	Step int `json:"step,omitempty"`
 }
 
 var UserHomeDir = os.UserHomeDir
 
-const (
-	maxHistorySize = 50
-)
-
 func getFilePath(suffix string) (string, error) {
 	home, err := UserHomeDir()
 	if err != nil {
@@ -50,9 +51,9 @@ func Save(entry HistoryEntry) error {
 	// Prepend new entry
 	entries = append([]HistoryEntry{entry}, entries...)
 
-	// Limit to maxHistorySize
-	if len(entries) > maxHistorySize {
-		entries = entries[:maxHistorySize]
+	// Limit to the history size of the user's settings
+	if size := settings.Current().History.Size; len(entries) > size {
+		entries = entries[:size]
 	}
 
 	data, err := yaml.Marshal(entries)
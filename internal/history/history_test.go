@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/settings"
 )
 
 func TestHistory(t *testing.T) {
@@ -58,8 +59,19 @@ func TestHistory(t *testing.T) {
 	}
 
 	entries, _ = Load()
-	if len(entries) > maxHistorySize {
-		t.Errorf("Expected max %d entries, got %d", maxHistorySize, len(entries))
+	if size := settings.Current().History.Size; len(entries) != size {
+		t.Errorf("Expected max %d entries, got %d", size, len(entries))
+	}
+
+	// Test the history size of the settings
+	s := settings.Default()
+	s.History.Size = 5
+	settings.Set(s)
+	defer settings.Set(nil)
+	Save(HistoryEntry{Command: "cmd", Timestamp: time.Now()})
+	entries, _ = Load()
+	if len(entries) != 5 {
+		t.Errorf("Expected the configured 5 entries, got %d", len(entries))
 	}
 
 	// Test Clear
@@ -284,7 +296,7 @@ func TestWorkflows(t *testing.T) {
 
 	wf := config.Workflow{
 		Name:     "deploy",
-		Commands: []string{"build", "gcp app-engine deploy"},
+		Commands: config.PlainSteps("build", "gcp app-engine deploy"),
 	}
 
 	t.Run("SaveToProject", func(t *testing.T) {
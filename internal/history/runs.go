@@ -0,0 +1,69 @@
+package history
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkflowRun is a workflow run pieced together from its history entries
+type WorkflowRun struct {
+	ID string
+	// Workflow is the workflow's ID or name, as it was run
+	Workflow  string
+	Inputs    map[string]string
+	Success   bool
+	Cancelled bool
+	// Succeeded maps the index of each step that last succeeded to its command
+	Succeeded map[int]string
+}
+
+// NewWorkflowRunID returns a unique ID for a workflow run
+func NewWorkflowRunID() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// FindWorkflowRun returns the workflow run with the given ID from entries
+// (newest first), or the most recent unsuccessful run when id is empty
+func FindWorkflowRun(entries []HistoryEntry, id string) (*WorkflowRun, error) {
+	var run *WorkflowRun
+	for _, e := range entries {
+		if e.WorkflowRunID == "" || e.Step != 0 || !strings.HasPrefix(e.Command, "workflow:") {
+			continue
+		}
+		if (id == "" && !e.Success) || e.WorkflowRunID == id {
+			run = &WorkflowRun{
+				ID:        e.WorkflowRunID,
+				Workflow:  strings.TrimPrefix(e.Command, "workflow:"),
+				Inputs:    e.Inputs,
+				Success:   e.Success,
+				Cancelled: e.Cancelled,
+				Succeeded: make(map[int]string),
+			}
+			break
+		}
+	}
+	if run == nil {
+		if id == "" {
+			return nil, fmt.Errorf("no failed workflow run in history")
+		}
+		return nil, fmt.Errorf("no workflow run '%s' in history", id)
+	}
+
+	// The newest entry of a step says how it last ended
+	seen := make(map[int]bool)
+	for _, e := range entries {
+		if e.WorkflowRunID != run.ID || e.Step == 0 || seen[e.Step] {
+			continue
+		}
+		seen[e.Step] = true
+		if e.Success {
+			run.Succeeded[e.Step-1] = e.Command
+		}
+	}
+	return run, nil
+}
@@ -0,0 +1,49 @@
+package history
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindWorkflowRun(t *testing.T) {
+	// Newest first, as Load returns them
+	entries := []HistoryEntry{
+		{Command: "workflow:deploy", WorkflowRunID: "run-2", Success: false, Inputs: map[string]string{"env": "prod"}},
+		{Command: "echo push", WorkflowRunID: "run-2", Step: 2, Success: true},
+		{Command: "workflow:deploy", WorkflowRunID: "run-2", Success: false},
+		{Command: "echo push", WorkflowRunID: "run-2", Step: 2, Success: false},
+		{Command: "echo build", WorkflowRunID: "run-2", Step: 1, Success: true},
+		{Command: "build"},
+		{Command: "workflow:lint", WorkflowRunID: "run-1", Success: false},
+	}
+
+	run, err := FindWorkflowRun(entries, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if run.ID != "run-2" || run.Workflow != "deploy" || run.Inputs["env"] != "prod" {
+		t.Errorf("expected the latest failed run, got %+v", run)
+	}
+	if len(run.Succeeded) != 2 || run.Succeeded[0] != "echo build" || run.Succeeded[1] != "echo push" {
+		t.Errorf("expected both steps to count as succeeded, got %v", run.Succeeded)
+	}
+
+	run, err = FindWorkflowRun(entries, "run-1")
+	if err != nil || run.Workflow != "lint" || len(run.Succeeded) != 0 {
+		t.Errorf("expected run-1 with no succeeded steps, got %+v (%v)", run, err)
+	}
+
+	if _, err := FindWorkflowRun(entries, "missing"); err == nil || !strings.Contains(err.Error(), "no workflow run 'missing'") {
+		t.Errorf("expected missing run error, got %v", err)
+	}
+	if _, err := FindWorkflowRun([]HistoryEntry{{Command: "workflow:ok", WorkflowRunID: "run-3", Success: true}}, ""); err == nil {
+		t.Error("expected an error when no run failed")
+	}
+}
+
+func TestNewWorkflowRunID(t *testing.T) {
+	a, b := NewWorkflowRunID(), NewWorkflowRunID()
+	if a == b {
+		t.Errorf("expected unique run IDs, got %s twice", a)
+	}
+}
@@ -32,10 +32,29 @@ func Slugify(name string) string {
 	return s
 }
 
+// ReservedWorkflowNames are the subcommands of cleat workflow. A workflow
+// named or identified like one could not be run from the command line.
+var ReservedWorkflowNames = []string{"add", "list", "remove", "resume", "rm", "show", "validate"}
+
+// IsReservedWorkflowName reports whether name is a subcommand of cleat workflow
+func IsReservedWorkflowName(name string) bool {
+	for _, reserved := range ReservedWorkflowNames {
+		if name == reserved {
+			return true
+		}
+	}
+	return false
+}
+
 func ValidateWorkflowName(name string) error {
 	if strings.TrimSpace(name) == "" {
 		return fmt.Errorf("workflow name cannot be empty")
 	}
+	for _, n := range []string{name, Slugify(name)} {
+		if IsReservedWorkflowName(n) {
+			return fmt.Errorf("workflow name '%s' is reserved for the 'cleat workflow %s' command", name, n)
+		}
+	}
 	return nil
 }
 
@@ -150,19 +169,52 @@ func SaveWorkflowToUser(workflow config.Workflow) error {
 	})
 }
 
+// Workflow sources, from lowest to highest precedence
+const (
+	SourceConfig  = "config"
+	SourceProject = "project"
+	SourceUser    = "user"
+)
+
+// SourcedWorkflow is a workflow together with the file that defines it
+type SourcedWorkflow struct {
+	config.Workflow
+	// Source is SourceConfig, SourceProject or SourceUser
+	Source string
+	// Path is the defining file; empty for an auto-detected config
+	Path string
+}
+
 func LoadWorkflows(cfg *config.Config) ([]config.Workflow, error) {
-	workflowsMap := make(map[string]config.Workflow)
+	sourced, err := LoadWorkflowSources(cfg)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]config.Workflow, 0, len(sourced))
+	for _, w := range sourced {
+		res = append(res, w.Workflow)
+	}
+	return res, nil
+}
 
-	// 1. Load from cleat.yaml/cleat.yml (if available in cfg)
-	if cfg != nil {
-		for _, w := range cfg.Workflows {
+// LoadWorkflowSources loads the merged workflows like LoadWorkflows, keeping
+// track of where each one was defined
+func LoadWorkflowSources(cfg *config.Config) ([]SourcedWorkflow, error) {
+	workflowsMap := make(map[string]SourcedWorkflow)
+	add := func(workflows []config.Workflow, source, path string) {
+		for _, w := range workflows {
 			if w.ID == "" {
 				w.ID = Slugify(w.Name)
 			}
-			workflowsMap[w.ID] = w
+			workflowsMap[w.ID] = SourcedWorkflow{Workflow: w, Source: source, Path: path}
 		}
 	}
 
+	// 1. Load from cleat.yaml/cleat.yml (if available in cfg)
+	if cfg != nil {
+		add(cfg.Workflows, SourceConfig, cfg.SourcePath)
+	}
+
 	// 2. Load from cleat.workflows.yaml or .yml in project root
 	root := config.FindProjectRoot()
 	projectFiles := []string{
@@ -173,12 +225,7 @@ func LoadWorkflows(cfg *config.Config) ([]config.Workflow, error) {
 		if data, err := os.ReadFile(projectFile); err == nil {
 			var projectWorkflows []config.Workflow
 			if err := yaml.Unmarshal(data, &projectWorkflows); err == nil {
-				for _, w := range projectWorkflows {
-					if w.ID == "" {
-						w.ID = Slugify(w.Name)
-					}
-					workflowsMap[w.ID] = w
-				}
+				add(projectWorkflows, SourceProject, projectFile)
 			} else {
 				return nil, fmt.Errorf("failed to parse project workflows in %s: %w", projectFile, err)
 			}
@@ -190,12 +237,7 @@ func LoadWorkflows(cfg *config.Config) ([]config.Workflow, error) {
 		if data, err := os.ReadFile(userFile); err == nil {
 			var userWorkflows []config.Workflow
 			if err := yaml.Unmarshal(data, &userWorkflows); err == nil {
-				for _, w := range userWorkflows {
-					if w.ID == "" {
-						w.ID = Slugify(w.Name)
-					}
-					workflowsMap[w.ID] = w
-				}
+				add(userWorkflows, SourceUser, userFile)
 			} else {
 				return nil, fmt.Errorf("failed to parse user workflows in %s: %w", userFile, err)
 			}
@@ -203,7 +245,7 @@ func LoadWorkflows(cfg *config.Config) ([]config.Workflow, error) {
 	}
 
 	// Convert map back to slice and validate
-	res := make([]config.Workflow, 0, len(workflowsMap))
+	res := make([]SourcedWorkflow, 0, len(workflowsMap))
 	for _, w := range workflowsMap {
 		if w.Name == "" {
 			logger.Warn("skipping workflow with empty name", nil)
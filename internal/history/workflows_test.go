@@ -26,7 +26,7 @@ func TestLoadWorkflows_Validation(t *testing.T) {
 	invalidWf := []config.Workflow{
 		{
 			Name:     "invalid-wf",
-			Commands: []string{}, // Empty commands
+			Commands: config.PlainSteps(), // Empty commands
 		},
 	}
 	data, _ := yaml.Marshal(invalidWf)
@@ -92,7 +92,7 @@ func TestSaveWorkflowToProject(t *testing.T) {
 
 	wf := config.Workflow{
 		Name:     "test-wf",
-		Commands: []string{"echo hello"},
+		Commands: config.PlainSteps("echo hello"),
 	}
 
 	// 1. Save new workflow
@@ -116,14 +116,14 @@ func TestSaveWorkflowToProject(t *testing.T) {
 	}
 
 	// 3. Update existing
-	wf.Commands = []string{"echo updated"}
+	wf.Commands = config.PlainSteps("echo updated")
 	err = SaveWorkflowToProject(wf)
 	if err != nil {
 		t.Fatalf("SaveWorkflowToProject update failed: %v", err)
 	}
 
 	workflows, _ = LoadWorkflows(nil)
-	if len(workflows) != 1 || workflows[0].Commands[0] != "echo updated" {
+	if len(workflows) != 1 || workflows[0].Commands[0].Run != "echo updated" {
 		t.Fatalf("Expected updated workflow, got %v", workflows)
 	}
 
@@ -167,7 +167,7 @@ func TestDeleteWorkflow(t *testing.T) {
 	os.Chdir(tmpDir)
 	defer os.Chdir(oldWd)
 
-	wf := config.Workflow{Name: "to-delete", Commands: []string{"ls"}}
+	wf := config.Workflow{Name: "to-delete", Commands: config.PlainSteps("ls")}
 	SaveWorkflowToProject(wf)
 
 	err = DeleteWorkflow("to-delete")
@@ -197,7 +197,7 @@ func TestSaveWorkflowToUser(t *testing.T) {
 
 	wf := config.Workflow{
 		Name:     "user-wf",
-		Commands: []string{"echo user"},
+		Commands: config.PlainSteps("echo user"),
 	}
 
 	err = SaveWorkflowToUser(wf)
@@ -223,7 +223,7 @@ func TestSaveWorkflowToUser(t *testing.T) {
 	}
 
 	// 3. Update existing user workflow
-	wf.Commands = []string{"echo user-updated"}
+	wf.Commands = config.PlainSteps("echo user-updated")
 	err = SaveWorkflowToUser(wf)
 	if err != nil {
 		t.Fatalf("SaveWorkflowToUser update failed: %v", err)
@@ -232,7 +232,7 @@ func TestSaveWorkflowToUser(t *testing.T) {
 	workflows, _ = LoadWorkflows(nil)
 	found = false
 	for _, w := range workflows {
-		if w.Name == "user-wf" && w.Commands[0] == "echo user-updated" {
+		if w.Name == "user-wf" && w.Commands[0].Run == "echo user-updated" {
 			found = true
 			break
 		}
@@ -257,7 +257,7 @@ func TestSaveWorkflow_IDBasedUpsert(t *testing.T) {
 	wf1 := config.Workflow{
 		ID:       "my-workflow",
 		Name:     "My Workflow",
-		Commands: []string{"echo 1"},
+		Commands: config.PlainSteps("echo 1"),
 	}
 	err = SaveWorkflowToProject(wf1)
 	if err != nil {
@@ -268,7 +268,7 @@ func TestSaveWorkflow_IDBasedUpsert(t *testing.T) {
 	wf2 := config.Workflow{
 		ID:       "my-workflow",
 		Name:     "Updated Name",
-		Commands: []string{"echo 2"},
+		Commands: config.PlainSteps("echo 2"),
 	}
 	err = SaveWorkflowToProject(wf2)
 	if err != nil {
@@ -390,6 +390,9 @@ func TestValidateWorkflowName(t *testing.T) {
 		{"valid", "Valid Name", false},
 		{"empty", "", true},
 		{"spaces only", "   ", true},
+		{"subcommand", "list", true},
+		{"subcommand once slugified", "Resume", true},
+		{"subcommand in a longer name", "list files", false},
 	}
 
 	for _, tt := range tests {
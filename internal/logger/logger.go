@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/madewithfuture/cleat/internal/settings"
 	"github.com/rs/zerolog"
 )
 
@@ -58,6 +59,13 @@ func Init(logPath string, level string, context map[string]interface{}) error {
 	return nil
 }
 
+// InitFromSettings initializes the global logger at the level and path of
+// the user's settings
+func InitFromSettings(context map[string]interface{}) error {
+	s := settings.Current()
+	return Init(s.Log.Path, s.Log.Level, context)
+}
+
 // Debug logs a debug message
 func Debug(msg string, fields map[string]interface{}) {
 	e := log.Debug()
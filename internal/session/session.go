@@ -1,6 +1,8 @@
 package session
 
 import (
+	"context"
+
 	"github.com/madewithfuture/cleat/internal/config/schema"
 	"github.com/madewithfuture/cleat/internal/executor"
 )
@@ -11,6 +13,14 @@ type Session struct {
 	Inputs        map[string]string
 	Exec          executor.Executor
 	WorkflowStack []string // Track active workflows during resolution to detect cycles
+	Jobs          int      // Maximum number of tasks run concurrently by parallel strategies
+	NoInput       bool     // Fail instead of prompting when a required input is missing
+	CheckOnly     bool     // Formatters report problems instead of fixing them
+	Fix           bool     // Linters fix the problems they can instead of only reporting them
+	Services      []string // Services selected with --service/--exclude-service; nil when none were filtered out
+
+	ctx     context.Context
+	secrets map[string]bool
 }
 
 // NewSession creates a new session with the provided configuration and executor
@@ -19,5 +29,54 @@ func NewSession(cfg *schema.Config, exec executor.Executor) *Session {
 		Config: cfg,
 		Inputs: make(map[string]string),
 		Exec:   exec,
+		Jobs:   1,
+		ctx:    context.Background(),
+	}
+}
+
+// Context returns the context that cancels this session's commands
+func (s *Session) Context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// SetContext replaces the session's cancellation context
+func (s *Session) SetContext(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// MarkSecret records that the input key holds a secret
+func (s *Session) MarkSecret(key string) {
+	if s.secrets == nil {
+		s.secrets = make(map[string]bool)
 	}
+	s.secrets[key] = true
+}
+
+// IsSecret reports whether the input key was marked as a secret
+func (s *Session) IsSecret(key string) bool {
+	return s.secrets[key]
+}
+
+// PublicInputs returns a copy of inputs without secret values, safe to persist or print
+func (s *Session) PublicInputs(inputs map[string]string) map[string]string {
+	if inputs == nil {
+		return nil
+	}
+	public := make(map[string]string, len(inputs))
+	for k, v := range inputs {
+		if !s.IsSecret(k) {
+			public[k] = v
+		}
+	}
+	return public
+}
+
+// WithContext returns a shallow copy of the session bound to ctx
+func (s *Session) WithContext(ctx context.Context) *Session {
+	c := *s
+	c.ctx = ctx
+	return &c
 }
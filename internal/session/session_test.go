@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"testing"
 
 	"github.com/madewithfuture/cleat/internal/config/schema"
@@ -27,3 +28,50 @@ func TestNewSession(t *testing.T) {
 		t.Error("expected inputs map to be initialized")
 	}
 }
+
+func TestSessionContext(t *testing.T) {
+	sess := NewSession(&schema.Config{}, &mockExecutor{})
+	if sess.Context() == nil {
+		t.Fatal("expected a default context")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	child := sess.WithContext(ctx)
+	if child.Context() != ctx {
+		t.Error("expected WithContext to bind the new context")
+	}
+	if sess.Context() == ctx {
+		t.Error("expected WithContext to leave the original session unchanged")
+	}
+
+	sess.SetContext(ctx)
+	if sess.Context() != ctx {
+		t.Error("expected SetContext to replace the context")
+	}
+
+	var zero Session
+	if zero.Context() == nil {
+		t.Error("expected zero-value session to fall back to a background context")
+	}
+}
+
+func TestSessionSecrets(t *testing.T) {
+	sess := NewSession(&schema.Config{}, &mockExecutor{})
+	sess.MarkSecret("token")
+
+	if !sess.IsSecret("token") || sess.IsSecret("user") {
+		t.Error("expected only 'token' to be secret")
+	}
+
+	public := sess.PublicInputs(map[string]string{"token": "s3cret", "user": "me"})
+	if _, ok := public["token"]; ok {
+		t.Error("expected secret input to be removed")
+	}
+	if public["user"] != "me" {
+		t.Errorf("expected public input to be kept, got %v", public)
+	}
+	if sess.PublicInputs(nil) != nil {
+		t.Error("expected nil inputs to stay nil")
+	}
+}
@@ -0,0 +1,279 @@
+package settings
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the user's settings file within ~/.cleat
+const FileName = "config.yaml"
+
+// Settings are the user's global preferences, read from ~/.cleat/config.yaml.
+// Every field is optional; one the file leaves out keeps its default.
+type Settings struct {
+	Log              Log         `yaml:"log"`
+	History          History     `yaml:"history"`
+	Editor           string      `yaml:"editor"`
+	Theme            Theme       `yaml:"theme"`
+	Keybindings      Keybindings `yaml:"keybindings"`
+	Confirm          Confirm     `yaml:"confirm"`
+	ContainerRuntime string      `yaml:"container_runtime"`
+}
+
+// Log configures the log file shared by every project
+type Log struct {
+	// Level is one of debug, info, warn or error
+	Level string `yaml:"level"`
+	// Path may start with ~/ for the home directory
+	Path string `yaml:"path"`
+}
+
+// History configures the per-project command history
+type History struct {
+	// Size is how many entries are kept
+	Size int `yaml:"size"`
+}
+
+// Theme overrides the colors of the interface. A color is an ANSI color
+// number from 0 to 255 or a hex color such as #ff79c6; an empty one keeps the
+// default, which follows the terminal's own palette.
+type Theme struct {
+	Purple  string `yaml:"purple"`
+	Cyan    string `yaml:"cyan"`
+	Comment string `yaml:"comment"`
+	Green   string `yaml:"green"`
+	Red     string `yaml:"red"`
+	Orange  string `yaml:"orange"`
+	FG      string `yaml:"fg"`
+	White   string `yaml:"white"`
+}
+
+// Keybindings are the single keys of the interface's actions
+type Keybindings struct {
+	Quit           string `yaml:"quit"`
+	Filter         string `yaml:"filter"`
+	Help           string `yaml:"help"`
+	Config         string `yaml:"config"`
+	ExpandAll      string `yaml:"expand_all"`
+	CollapseAll    string `yaml:"collapse_all"`
+	Tasks          string `yaml:"tasks"`
+	ClearHistory   string `yaml:"clear_history"`
+	NewWorkflow    string `yaml:"new_workflow"`
+	Resume         string `yaml:"resume"`
+	DeleteWorkflow string `yaml:"delete_workflow"`
+}
+
+// Confirm chooses which actions ask before they go ahead
+type Confirm struct {
+	// ClearHistory asks before the interface clears the history
+	ClearHistory bool `yaml:"clear_history"`
+	// DeleteWorkflow asks before the interface deletes a workflow
+	DeleteWorkflow bool `yaml:"delete_workflow"`
+	// Init asks whether to keep each detected service and module in cleat init
+	Init bool `yaml:"init"`
+}
+
+// ContainerRuntimes are the programs that can run compose commands
+var ContainerRuntimes = []string{"docker", "podman"}
+
+// logLevels are the levels the logger knows
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// reservedKeys are the keys that move through the interface, which cannot
+// be bound to an action
+const reservedKeys = "jkg123456789"
+
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5])$`)
+
+// UserHomeDir is a mockable version of os.UserHomeDir
+var UserHomeDir = os.UserHomeDir
+
+var (
+	mu      sync.RWMutex
+	current *Settings
+)
+
+// Default returns the settings used when the file leaves them out
+func Default() *Settings {
+	return &Settings{
+		Log:     Log{Level: "debug", Path: "~/.cleat/cleat.log"},
+		History: History{Size: 50},
+		Keybindings: Keybindings{
+			Quit:           "q",
+			Filter:         "/",
+			Help:           "?",
+			Config:         "c",
+			ExpandAll:      "e",
+			CollapseAll:    "C",
+			Tasks:          "t",
+			ClearHistory:   "x",
+			NewWorkflow:    "w",
+			Resume:         "r",
+			DeleteWorkflow: "d",
+		},
+		Confirm:          Confirm{ClearHistory: true, DeleteWorkflow: true, Init: true},
+		ContainerRuntime: "docker",
+	}
+}
+
+// Path returns the location of the settings file
+func Path() (string, error) {
+	home, err := UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home dir: %w", err)
+	}
+	return filepath.Join(home, ".cleat", FileName), nil
+}
+
+// Load reads the settings file over the defaults. A missing file gives the
+// defaults.
+func Load() (*Settings, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file %s: %w", path, err)
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse decodes settings over the defaults and checks them. Unknown fields
+// are errors.
+func Parse(data []byte) (*Settings, error) {
+	s := Default()
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate returns every problem with the settings
+func (s *Settings) Validate() error {
+	var errs []error
+	if !contains(logLevels, strings.ToLower(s.Log.Level)) {
+		errs = append(errs, fmt.Errorf("log.level '%s' must be one of: %s", s.Log.Level, strings.Join(logLevels, ", ")))
+	}
+	if s.Log.Path == "" {
+		errs = append(errs, errors.New("log.path cannot be empty"))
+	}
+	if s.History.Size < 1 {
+		errs = append(errs, fmt.Errorf("history.size must be at least 1, got %d", s.History.Size))
+	}
+	if !contains(ContainerRuntimes, s.ContainerRuntime) {
+		errs = append(errs, fmt.Errorf("container_runtime '%s' must be one of: %s", s.ContainerRuntime, strings.Join(ContainerRuntimes, ", ")))
+	}
+	for _, c := range s.Theme.colors() {
+		if *c.value != "" && !colorPattern.MatchString(*c.value) {
+			errs = append(errs, fmt.Errorf("theme.%s '%s' must be an ANSI color number or a hex color such as #ff79c6", c.name, *c.value))
+		}
+	}
+	bound := make(map[string]string)
+	for _, b := range s.Keybindings.bindings() {
+		key := *b.value
+		switch {
+		case len([]rune(key)) != 1 || strings.TrimSpace(key) == "":
+			errs = append(errs, fmt.Errorf("keybindings.%s '%s' must be a single character", b.name, key))
+		case strings.Contains(reservedKeys, key):
+			errs = append(errs, fmt.Errorf("keybindings.%s '%s' is used to move around and cannot be bound", b.name, key))
+		case bound[key] != "":
+			errs = append(errs, fmt.Errorf("keybindings.%s '%s' is already bound to %s", b.name, key, bound[key]))
+		default:
+			bound[key] = b.name
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EditorCommand returns the editor to open files with and its arguments:
+// the editor setting, else $EDITOR, else vi
+func (s *Settings) EditorCommand() []string {
+	for _, editor := range []string{s.Editor, os.Getenv("EDITOR")} {
+		if fields := strings.Fields(editor); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// named is a setting with its name in the file
+type named struct {
+	name  string
+	value *string
+}
+
+func (t *Theme) colors() []named {
+	return []named{
+		{"purple", &t.Purple}, {"cyan", &t.Cyan}, {"comment", &t.Comment}, {"green", &t.Green},
+		{"red", &t.Red}, {"orange", &t.Orange}, {"fg", &t.FG}, {"white", &t.White},
+	}
+}
+
+func (k *Keybindings) bindings() []named {
+	return []named{
+		{"quit", &k.Quit}, {"filter", &k.Filter}, {"help", &k.Help}, {"config", &k.Config},
+		{"expand_all", &k.ExpandAll}, {"collapse_all", &k.CollapseAll}, {"tasks", &k.Tasks},
+		{"clear_history", &k.ClearHistory}, {"new_workflow", &k.NewWorkflow},
+		{"resume", &k.Resume}, {"delete_workflow", &k.DeleteWorkflow},
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Init loads the settings file once, at startup, and makes it Current. When
+// it cannot be loaded, Current keeps the defaults and the error is returned.
+func Init() error {
+	s, err := Load()
+	if err != nil {
+		Set(Default())
+		return err
+	}
+	Set(s)
+	return nil
+}
+
+// Current returns the loaded settings, or the defaults before Init
+func Current() *Settings {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current == nil {
+		return Default()
+	}
+	return current
+}
+
+// Set replaces the current settings; tests use it to change them
+func Set(s *Settings) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = s
+}
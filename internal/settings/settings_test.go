@@ -0,0 +1,126 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldUserHomeDir := UserHomeDir
+	UserHomeDir = func() (string, error) { return tmpDir, nil }
+	defer func() { UserHomeDir = oldUserHomeDir }()
+
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed without a file: %v", err)
+	}
+	if !reflect.DeepEqual(s, Default()) {
+		t.Errorf("expected the defaults without a file, got %+v", s)
+	}
+
+	path := filepath.Join(tmpDir, ".cleat", FileName)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte(`log:
+  level: warn
+history:
+  size: 10
+editor: code --wait
+theme:
+  purple: "#ff79c6"
+keybindings:
+  quit: Q
+confirm:
+  clear_history: false
+container_runtime: podman
+`), 0644)
+
+	s, err = Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := Default()
+	want.Log.Level = "warn"
+	want.History.Size = 10
+	want.Editor = "code --wait"
+	want.Theme.Purple = "#ff79c6"
+	want.Keybindings.Quit = "Q"
+	want.Confirm.ClearHistory = false
+	want.ContainerRuntime = "podman"
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Load() = %+v, want %+v", s, want)
+	}
+
+	t.Run("Init", func(t *testing.T) {
+		defer Set(nil)
+		os.WriteFile(path, []byte("histroy:\n  size: 10\n"), 0644)
+		err := Init()
+		if err == nil || !strings.Contains(err.Error(), path) || !strings.Contains(err.Error(), "field histroy not found") {
+			t.Errorf("expected the unknown field with the file's path, got %v", err)
+		}
+		if !reflect.DeepEqual(Current(), Default()) {
+			t.Errorf("expected the defaults after a bad file, got %+v", Current())
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr []string
+	}{
+		{"Empty", "", nil},
+		{"Colors", "theme:\n  comment: \"244\"\n  red: \"#f00\"\n", nil},
+		{"LogLevel", "log:\n  level: verbose\n", []string{"log.level 'verbose' must be one of: debug, info, warn, error"}},
+		{"HistorySize", "history:\n  size: 0\n", []string{"history.size must be at least 1, got 0"}},
+		{"Runtime", "container_runtime: nerdctl\n", []string{"container_runtime 'nerdctl' must be one of: docker, podman"}},
+		{"Color", "theme:\n  cyan: teal\n  green: \"256\"\n", []string{"theme.cyan 'teal' must be", "theme.green '256' must be"}},
+		{"Keys", "keybindings:\n  quit: x\n  help: ctrl+h\n  filter: j\n", []string{
+			"keybindings.help 'ctrl+h' must be a single character",
+			"keybindings.filter 'j' is used to move around and cannot be bound",
+			"keybindings.clear_history 'x' is already bound to quit",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Parse failed: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected %q in %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		editor string
+		env    string
+		want   []string
+	}{
+		{"code --wait", "nano", []string{"code", "--wait"}},
+		{"", "nano", []string{"nano"}},
+		{"", "", []string{"vi"}},
+	}
+	for _, tt := range tests {
+		t.Setenv("EDITOR", tt.env)
+		s := &Settings{Editor: tt.editor}
+		if got := s.EditorCommand(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("EditorCommand() with %q and $EDITOR %q = %v, want %v", tt.editor, tt.env, got, tt.want)
+		}
+	}
+}
@@ -29,6 +29,13 @@ func NewBuildStrategy(cfg *config.Config) Strategy {
 			}
 		}
 
+		// Stack tasks may run inside the freshly built images, so they wait for
+		// the docker build when it runs. The dependency is ignored otherwise.
+		var dockerDeps []string
+		if dockerAdded {
+			dockerDeps = []string{tasks[0].Name()}
+		}
+
 		// Add NPM build tasks
 		for i := range cfg.Services {
 			svc := &cfg.Services[i]
@@ -37,7 +44,9 @@ func NewBuildStrategy(cfg *config.Config) Strategy {
 				if mod.Npm != nil {
 					for _, s := range mod.Npm.Scripts {
 						if s == "build" {
-							tasks = append(tasks, task.NewNpmRun(svc, mod.Npm, "build"))
+							t := task.NewNpmRun(svc, mod.Npm, "build")
+							t.TaskDeps = dockerDeps
+							tasks = append(tasks, t)
 						}
 					}
 				}
@@ -50,7 +59,9 @@ func NewBuildStrategy(cfg *config.Config) Strategy {
 			for j := range svc.Modules {
 				mod := &svc.Modules[j]
 				if mod.Go != nil {
-					tasks = append(tasks, task.NewGoAction(svc, mod.Go, "build"))
+					t := task.NewGoAction(svc, mod.Go, "build")
+					t.TaskDeps = dockerDeps
+					tasks = append(tasks, t)
 				}
 			}
 		}
@@ -61,7 +72,9 @@ func NewBuildStrategy(cfg *config.Config) Strategy {
 			for j := range svc.Modules {
 				mod := &svc.Modules[j]
 				if mod.Python != nil {
-					tasks = append(tasks, task.NewDjangoCollectStatic(svc))
+					t := task.NewDjangoCollectStatic(svc)
+					t.TaskDeps = dockerDeps
+					tasks = append(tasks, t)
 				}
 			}
 		}
@@ -72,7 +85,9 @@ func NewBuildStrategy(cfg *config.Config) Strategy {
 			for j := range svc.Modules {
 				mod := &svc.Modules[j]
 				if mod.Ruby != nil && mod.Ruby.Rails {
-					tasks = append(tasks, task.NewRubyAction(svc, mod.Ruby, "assets:precompile"))
+					t := task.NewRubyAction(svc, mod.Ruby, "assets:precompile")
+					t.TaskDeps = dockerDeps
+					tasks = append(tasks, t)
 				}
 			}
 		}
@@ -82,5 +97,5 @@ func NewBuildStrategy(cfg *config.Config) Strategy {
 		tasks = append(tasks, task.NewDockerBuild(nil))
 	}
 
-	return NewBaseStrategy("build", tasks)
+	return NewParallelStrategy("build", tasks)
 }
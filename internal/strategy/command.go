@@ -0,0 +1,56 @@
+package strategy
+
+import (
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/task"
+)
+
+// UserCommandProvider handles user-defined commands from the commands sections
+// of the configuration, addressed as "command:<name>" or "command:<service>:<name>"
+type UserCommandProvider struct{}
+
+func (p *UserCommandProvider) CanHandle(command string) bool {
+	return strings.HasPrefix(command, task.CommandPrefix)
+}
+
+func (p *UserCommandProvider) GetStrategy(command string, sess *session.Session) Strategy {
+	if sess == nil || sess.Config == nil {
+		return nil
+	}
+
+	ref := strings.TrimPrefix(command, task.CommandPrefix)
+	svc, cmd, ok := sess.Config.FindCommand(nil, ref)
+	if !ok {
+		return nil
+	}
+	return NewCommandStrategy(sess.Config, svc, cmd)
+}
+
+// NewCommandStrategy creates a strategy that runs cmd after the commands it
+// depends on, directly or transitively. Independent dependencies may run in
+// parallel with --jobs.
+func NewCommandStrategy(cfg *config.Config, svc *config.ServiceConfig, cmd *config.CommandConfig) Strategy {
+	var tasks []task.Task
+	seen := make(map[string]bool)
+
+	var visit func(svc *config.ServiceConfig, cmd *config.CommandConfig)
+	visit = func(svc *config.ServiceConfig, cmd *config.CommandConfig) {
+		t := task.NewCommandTask(cfg, svc, cmd)
+		if seen[t.Name()] {
+			return
+		}
+		seen[t.Name()] = true
+		tasks = append(tasks, t)
+		for _, ref := range cmd.DependsOn {
+			if depSvc, depCmd, ok := cfg.FindCommand(svc, ref); ok {
+				visit(depSvc, depCmd)
+			}
+		}
+	}
+	visit(svc, cmd)
+
+	return NewParallelStrategy(task.CommandName(svc, cmd), tasks)
+}
@@ -0,0 +1,196 @@
+package strategy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// stepState is what workflow step conditions are evaluated against
+type stepState struct {
+	inputs map[string]string
+	// outcomes holds "success", "failure" or "skipped" by step ID
+	outcomes map[string]string
+	// failed is set once a step fails without continue_on_error
+	failed bool
+}
+
+// evalCondition evaluates an if: expression. An expression is a chain of
+// terms joined by && and || (&& binds tighter), each optionally negated with
+// !. A term is a function (success(), failure(), always()), a comparison of
+// two operands with == or !=, or a single operand that is true when it is
+// non-empty and not "false". Operands are env.NAME, inputs.KEY,
+// steps.ID.outcome, or literals in single or double quotes. As in GitHub
+// Actions, an expression that checks none of success(), failure() and
+// always() only holds while no step has failed: it means success() && (expr).
+func evalCondition(expr string, state *stepState) (bool, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return false, fmt.Errorf("invalid condition '%s': %w", expr, err)
+	}
+	if len(tokens) == 0 {
+		return !state.failed, nil
+	}
+	// Every term is evaluated so a malformed one is reported whatever the values
+	result := false
+	for _, alt := range splitTokens(tokens, "||") {
+		all := true
+		for _, term := range splitTokens(alt, "&&") {
+			ok, err := evalTerm(term, state)
+			if err != nil {
+				return false, fmt.Errorf("invalid condition '%s': %w", expr, err)
+			}
+			all = all && ok
+		}
+		result = result || all
+	}
+	if !checksStatus(tokens) {
+		result = result && !state.failed
+	}
+	return result, nil
+}
+
+// token is an operator, a word such as inputs.env or success(), or a quoted
+// literal, which holds its text without the quotes
+type token struct {
+	text    string
+	op      bool
+	literal bool
+}
+
+var operators = []string{"&&", "||", "==", "!=", "!"}
+
+// tokenize splits expr into tokens. Operators inside quotes are part of the
+// literal.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+			continue
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated literal %s", expr[i:])
+			}
+			tokens = append(tokens, token{text: expr[i+1 : i+1+end], literal: true})
+			i += end + 2
+			continue
+		}
+		if op := operatorAt(expr, i); op != "" {
+			tokens = append(tokens, token{text: op, op: true})
+			i += len(op)
+			continue
+		}
+		start := i
+		for i < len(expr) && expr[i] != ' ' && expr[i] != '\t' && expr[i] != '\'' && expr[i] != '"' && operatorAt(expr, i) == "" {
+			i++
+		}
+		tokens = append(tokens, token{text: expr[start:i]})
+	}
+	return tokens, nil
+}
+
+func operatorAt(expr string, i int) string {
+	for _, op := range operators {
+		if strings.HasPrefix(expr[i:], op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// splitTokens splits tokens at each operator op
+func splitTokens(tokens []token, op string) [][]token {
+	parts := [][]token{nil}
+	for _, t := range tokens {
+		if t.op && t.text == op {
+			parts = append(parts, nil)
+			continue
+		}
+		parts[len(parts)-1] = append(parts[len(parts)-1], t)
+	}
+	return parts
+}
+
+// checksStatus reports whether tokens call success(), failure() or always()
+func checksStatus(tokens []token) bool {
+	for _, t := range tokens {
+		if !t.op && !t.literal && isStatusFunction(t.text) {
+			return true
+		}
+	}
+	return false
+}
+
+func isStatusFunction(s string) bool {
+	return s == "success()" || s == "failure()" || s == "always()"
+}
+
+func evalTerm(term []token, state *stepState) (bool, error) {
+	if len(term) > 0 && term[0].op && term[0].text == "!" {
+		ok, err := evalTerm(term[1:], state)
+		return !ok, err
+	}
+
+	switch {
+	case len(term) == 0:
+		return false, fmt.Errorf("empty term")
+	case len(term) == 1 && !term[0].literal && isStatusFunction(term[0].text):
+		switch term[0].text {
+		case "success()":
+			return !state.failed, nil
+		case "failure()":
+			return state.failed, nil
+		}
+		return true, nil
+	case len(term) == 3 && term[1].op && (term[1].text == "==" || term[1].text == "!="):
+		left, err := operand(term[0], state)
+		if err != nil {
+			return false, err
+		}
+		right, err := operand(term[2], state)
+		if err != nil {
+			return false, err
+		}
+		return (left == right) == (term[1].text == "=="), nil
+	case len(term) == 1:
+		val, err := operand(term[0], state)
+		if err != nil {
+			return false, err
+		}
+		return val != "" && val != "false", nil
+	}
+	var texts []string
+	for _, t := range term {
+		texts = append(texts, t.text)
+	}
+	return false, fmt.Errorf("unexpected term '%s'", strings.Join(texts, " "))
+}
+
+func operand(t token, state *stepState) (string, error) {
+	if t.literal {
+		return t.text, nil
+	}
+	s := t.text
+	switch {
+	case t.op:
+		return "", fmt.Errorf("unexpected operator '%s'", s)
+	case strings.HasPrefix(s, "env."):
+		return os.Getenv(strings.TrimPrefix(s, "env.")), nil
+	case strings.HasPrefix(s, "inputs."):
+		return state.inputs[strings.TrimPrefix(s, "inputs.")], nil
+	case strings.HasPrefix(s, "steps.") && strings.HasSuffix(s, ".outcome"):
+		id := strings.TrimSuffix(strings.TrimPrefix(s, "steps."), ".outcome")
+		if outcome, ok := state.outcomes[id]; ok {
+			return outcome, nil
+		}
+		return "skipped", nil
+	case s == "true" || s == "false":
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown operand '%s'", s)
+}
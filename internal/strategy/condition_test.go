@@ -0,0 +1,68 @@
+package strategy
+
+import "testing"
+
+func TestEvalCondition(t *testing.T) {
+	t.Setenv("CLEAT_TEST_CI", "true")
+	t.Setenv("CLEAT_TEST_EQ", "a==b")
+	state := &stepState{
+		inputs:   map[string]string{"env": "prod", "msg": "a && b"},
+		outcomes: map[string]string{"tests": "failure"},
+	}
+
+	tests := []struct {
+		expr    string
+		failed  bool
+		want    bool
+		wantErr bool
+	}{
+		{expr: "", want: true},
+		{expr: "", failed: true, want: false},
+		{expr: "success()", failed: true, want: false},
+		{expr: "failure()", failed: true, want: true},
+		{expr: "always()", failed: true, want: true},
+		{expr: "inputs.env == 'prod'", want: true},
+		{expr: "inputs.env != \"prod\"", want: false},
+		{expr: "env.CLEAT_TEST_CI", want: true},
+		{expr: "!env.CLEAT_TEST_UNSET", want: true},
+		{expr: "steps.tests.outcome == 'failure'", want: true},
+		{expr: "steps.unknown.outcome == 'skipped'", want: true},
+		{expr: "inputs.env == 'dev' || env.CLEAT_TEST_CI == 'true'", want: true},
+		{expr: "inputs.env == 'prod' && failure()", want: false},
+		// Without a status function, a condition also needs every step to have succeeded
+		{expr: "inputs.env == 'prod'", failed: true, want: false},
+		{expr: "inputs.env == 'dev' || inputs.env == 'prod'", failed: true, want: false},
+		{expr: "failure() && inputs.env == 'prod'", failed: true, want: true},
+		{expr: "always() && steps.tests.outcome == 'failure'", failed: true, want: true},
+		// Operators inside quotes are part of the literal
+		{expr: "inputs.msg == 'a && b'", want: true},
+		{expr: "inputs.msg != \"a || b\" && inputs.msg == 'a && b'", want: true},
+		{expr: "env.CLEAT_TEST_EQ == 'a==b'", want: true},
+		{expr: "inputs.env == '!prod'", want: false},
+		{expr: "inputs.env == 'success()'", failed: true, want: false},
+		{expr: "inputs.env == 'prod", wantErr: true},
+		{expr: "inputs.env == 'prod' 'dev'", wantErr: true},
+		{expr: "inputs.env ==", wantErr: true},
+		{expr: "bogus == 'x'", wantErr: true},
+		{expr: "always() || nonsense", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			state.failed = tt.failed
+			got, err := evalCondition(tt.expr, state)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
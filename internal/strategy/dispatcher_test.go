@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/config/schema"
+	"github.com/madewithfuture/cleat/internal/session"
 )
 
 func TestRegistryProvider(t *testing.T) {
@@ -37,3 +39,59 @@ func TestRegistryProvider(t *testing.T) {
 		t.Errorf("expected nil strategy for unknown command, got %v", s)
 	}
 }
+
+func TestUserCommandProvider(t *testing.T) {
+	cfg := &config.Config{
+		Commands: []config.CommandConfig{
+			{Name: "ci", Run: schema.CommandRun{Shell: "echo ci"}, DependsOn: []string{"lint", "web:test"}},
+			{Name: "lint", Run: schema.CommandRun{Shell: "echo lint"}, DependsOn: []string{"deps"}},
+			{Name: "deps", Run: schema.CommandRun{Shell: "echo deps"}},
+			{Name: "loop-a", Run: schema.CommandRun{Shell: "a"}, DependsOn: []string{"loop-b"}},
+			{Name: "loop-b", Run: schema.CommandRun{Shell: "b"}, DependsOn: []string{"loop-a"}},
+		},
+		Services: []config.ServiceConfig{
+			{Name: "web", Commands: []config.CommandConfig{
+				{Name: "test", Run: schema.CommandRun{Args: []string{"npm", "test"}}, DependsOn: []string{"deps"}},
+			}},
+		},
+	}
+	sess := session.NewSession(cfg, nil)
+	provider := &UserCommandProvider{}
+
+	if !provider.CanHandle("command:ci") || provider.CanHandle("ci") {
+		t.Error("expected provider to handle only command: prefixed commands")
+	}
+	if provider.GetStrategy("command:missing", sess) != nil {
+		t.Error("expected nil strategy for an unknown command")
+	}
+
+	s := GetStrategyForCommand("command:ci", sess)
+	if s == nil || s.Name() != "command:ci" {
+		t.Fatalf("expected command:ci strategy, got %v", s)
+	}
+	tasks, err := s.ResolveTasks(sess)
+	if err != nil {
+		t.Fatalf("ResolveTasks failed: %v", err)
+	}
+	pos := make(map[string]int)
+	for i, tk := range tasks {
+		pos[tk.Name()] = i
+	}
+	if len(tasks) != 4 {
+		t.Fatalf("expected ci and its 3 transitive dependencies, got %d tasks", len(tasks))
+	}
+	for _, edge := range [][2]string{
+		{"command:deps", "command:lint"},
+		{"command:lint", "command:ci"},
+		{"command:deps", "command:web:test"},
+		{"command:web:test", "command:ci"},
+	} {
+		if pos[edge[0]] > pos[edge[1]] {
+			t.Errorf("expected %s to run before %s, got order %v", edge[0], edge[1], pos)
+		}
+	}
+
+	if _, err := ResolveCommandTasks("command:loop-a", sess); err == nil {
+		t.Error("expected circular dependency error")
+	}
+}
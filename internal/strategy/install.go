@@ -0,0 +1,40 @@
+package strategy
+
+import (
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/task"
+)
+
+func init() {
+	Register("install", NewInstallStrategy)
+}
+
+// NewInstallStrategy creates the install command strategy, installing the
+// dependencies of every module in every service
+func NewInstallStrategy(cfg *config.Config) Strategy {
+	var tasks []task.Task
+	if cfg == nil {
+		return NewParallelStrategy("install", tasks)
+	}
+
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		for j := range svc.Modules {
+			mod := &svc.Modules[j]
+			if mod.Npm != nil {
+				tasks = append(tasks, task.NewNpmInstall(svc, mod.Npm))
+			}
+			if mod.Ruby != nil {
+				tasks = append(tasks, task.NewRubyInstall(svc, mod.Ruby))
+			}
+			if mod.Python != nil {
+				tasks = append(tasks, task.NewPythonInstall(svc))
+			}
+			if mod.Go != nil {
+				tasks = append(tasks, task.NewGoAction(svc, mod.Go, "mod-download"))
+			}
+		}
+	}
+
+	return NewParallelStrategy("install", tasks)
+}
@@ -0,0 +1,44 @@
+package strategy
+
+import (
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/task"
+)
+
+func init() {
+	Register("lint", NewLintStrategy)
+	Register("fmt", NewFmtStrategy)
+}
+
+// NewLintStrategy creates the lint command strategy, running the linters
+// configured for every service
+func NewLintStrategy(cfg *config.Config) Strategy {
+	var tasks []task.Task
+	if cfg == nil {
+		return NewParallelStrategy("lint", tasks)
+	}
+
+	for i := range cfg.Services {
+		tasks = append(tasks, task.NewLintTasks(&cfg.Services[i])...)
+	}
+
+	return NewParallelStrategy("lint", tasks)
+}
+
+// NewFmtStrategy creates the fmt command strategy, running the formatters
+// configured for every service and Terraform
+func NewFmtStrategy(cfg *config.Config) Strategy {
+	var tasks []task.Task
+	if cfg == nil {
+		return NewParallelStrategy("fmt", tasks)
+	}
+
+	for i := range cfg.Services {
+		tasks = append(tasks, task.NewFmtTasks(&cfg.Services[i])...)
+	}
+	if cfg.Terraform != nil {
+		tasks = append(tasks, task.NewTerraformFmt(cfg))
+	}
+
+	return NewParallelStrategy("fmt", tasks)
+}
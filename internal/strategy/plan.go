@@ -0,0 +1,254 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/executor"
+	"github.com/madewithfuture/cleat/internal/logger"
+	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/task"
+)
+
+// PlannedCommand is a single command a strategy would execute
+type PlannedCommand struct {
+	Task string   `json:"task"`
+	Dir  string   `json:"dir,omitempty"`
+	Env  []string `json:"env,omitempty"`
+	Args []string `json:"args"`
+}
+
+// String renders the command as it would be typed in a shell. Inputs left
+// to the environment by PlanExport become ${CLEAT_INPUT_...} expansions.
+func (c PlannedCommand) String() string {
+	var quoted []string
+	for _, e := range c.Env {
+		name, value, _ := strings.Cut(e, "=")
+		if value == inputRefMark+name+inputRefMark {
+			// The variable is already read from the environment
+			continue
+		}
+		quoted = append(quoted, name+"="+quoteArg(value))
+	}
+	for _, a := range c.Args {
+		quoted = append(quoted, quoteArg(a))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ShowReferences renders the input references PlanExport leaves in s, such
+// as in a task name, as ${CLEAT_INPUT_...}, for text that is not run by a shell
+func ShowReferences(s string) string {
+	if !strings.Contains(s, inputRefMark) {
+		return s
+	}
+	parts := strings.Split(s, inputRefMark)
+	for i := 1; i < len(parts); i += 2 {
+		parts[i] = "${" + parts[i] + "}"
+	}
+	return strings.Join(parts, "")
+}
+
+// quoteArg quotes s for a shell, expanding the input references in it
+func quoteArg(s string) string {
+	if !strings.Contains(s, inputRefMark) {
+		return shellQuote(s)
+	}
+	parts := strings.Split(s, inputRefMark)
+	var b strings.Builder
+	for i, part := range parts {
+		if i%2 == 1 {
+			b.WriteString(`"${` + part + `}"`)
+		} else if part != "" {
+			b.WriteString("'" + strings.ReplaceAll(part, "'", `'\''`) + "'")
+		}
+	}
+	return b.String()
+}
+
+// Plan collects the strategy's parameters, resolves its tasks, prompts for
+// missing inputs, and returns the commands they would run in order, without
+// executing anything. Each task's Run is driven against a recording executor
+// so the plan reflects the exact arguments, working directories and op run
+// wrapping.
+func Plan(s Strategy, sess *session.Session) ([]PlannedCommand, error) {
+	logger.Info("planning strategy", map[string]interface{}{"strategy": s.Name()})
+
+	if err := CollectInputs(sess, Parameters(s, sess)); err != nil {
+		return nil, err
+	}
+	tasks, err := s.ResolveTasks(sess)
+	if err != nil {
+		return nil, err
+	}
+	if err := promptMissingInputs(sess, tasks); err != nil {
+		return nil, err
+	}
+	return record(tasks, sess, sess.Exec)
+}
+
+// PlanExport resolves s like Plan, but never prompts: inputs and parameters
+// without a value are recorded as references to their CLEAT_INPUT_
+// environment variable, which PlannedCommand.String renders for a shell.
+// Workflows with conditions, continue_on_error or finally steps are refused,
+// as their commands alone do not say when each one runs.
+func PlanExport(s Strategy, sess *session.Session) ([]PlannedCommand, error) {
+	logger.Info("planning strategy for export", map[string]interface{}{"strategy": s.Name()})
+
+	if w, ok := s.(*WorkflowStrategy); ok {
+		if err := w.exportable(sess); err != nil {
+			return nil, err
+		}
+	}
+	if err := referenceInputs(sess, Parameters(s, sess)); err != nil {
+		return nil, err
+	}
+	tasks, err := s.ResolveTasks(sess)
+	if err != nil {
+		return nil, err
+	}
+	var reqs []task.InputRequirement
+	for _, t := range tasks {
+		reqs = append(reqs, t.Requirements(sess)...)
+	}
+	if err := referenceInputs(sess, reqs); err != nil {
+		return nil, err
+	}
+	return record(tasks, sess, nil)
+}
+
+// inputRefMark delimits an input reference inside a planned argument
+const inputRefMark = "\x00"
+
+// referenceInputs validates the inputs of reqs that have a value and stands
+// a reference to the environment in for the others
+func referenceInputs(sess *session.Session, reqs []task.InputRequirement) error {
+	for _, req := range reqs {
+		if val, ok := sess.Inputs[req.Key]; ok {
+			if strings.Contains(val, inputRefMark) {
+				continue
+			}
+			parsed, err := req.Parse(val)
+			if err != nil {
+				return fmt.Errorf("invalid input: %w", err)
+			}
+			sess.Inputs[req.Key] = parsed
+			continue
+		}
+		ref := task.InputEnvVar(req.Key)
+		if req.Default != "" {
+			ref += ":-" + req.Default
+		}
+		sess.Inputs[req.Key] = inputRefMark + ref + inputRefMark
+	}
+	return nil
+}
+
+// referencedInputs returns the keys of the inputs whose references to the
+// environment, made by referenceInputs, appear in command
+func referencedInputs(command string, sess *session.Session) []string {
+	if !strings.Contains(command, inputRefMark) {
+		return nil
+	}
+	var keys []string
+	for key, val := range sess.Inputs {
+		if strings.HasPrefix(val, inputRefMark) && strings.Contains(command, val) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// record runs tasks against a recording executor and returns their commands.
+// Prompts go to prompter; without one, tasks cannot prompt.
+func record(tasks []task.Task, sess *session.Session, prompter executor.Executor) ([]PlannedCommand, error) {
+	rec := &recordingExecutor{prompter: prompter, sess: sess}
+	planSess := sess.WithContext(sess.Context())
+	planSess.Exec = rec
+
+	out := task.Output
+	task.Output = io.Discard
+	defer func() { task.Output = out }()
+
+	for _, t := range tasks {
+		rec.task = t.Name()
+		before := len(rec.commands)
+		if err := t.Run(planSess); err != nil {
+			return nil, fmt.Errorf("failed to plan task '%s': %w", t.Name(), err)
+		}
+		if len(rec.commands) == before {
+			// Tasks that do not go through the executor still describe their commands
+			for _, args := range t.Commands(planSess) {
+				rec.commands = append(rec.commands, PlannedCommand{Task: t.Name(), Args: args})
+			}
+		}
+	}
+	return rec.commands, nil
+}
+
+// recordingExecutor captures commands instead of running them
+type recordingExecutor struct {
+	prompter executor.Executor
+	sess     *session.Session
+	task     string
+	commands []PlannedCommand
+}
+
+func (e *recordingExecutor) Run(ctx context.Context, name string, args ...string) error {
+	return e.RunWithDir(ctx, "", name, args...)
+}
+
+func (e *recordingExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
+	return e.RunWithEnv(ctx, dir, nil, name, args...)
+}
+
+func (e *recordingExecutor) RunWithEnv(ctx context.Context, dir string, env []string, name string, args ...string) error {
+	e.commands = append(e.commands, PlannedCommand{
+		Task: e.task,
+		Dir:  dir,
+		Env:  e.maskSecrets(env),
+		Args: append([]string{name}, args...),
+	})
+	return nil
+}
+
+// maskSecrets hides the values of CLEAT_INPUT_ variables that carry secret inputs
+func (e *recordingExecutor) maskSecrets(env []string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	masked := make([]string, len(env))
+	for i, kv := range env {
+		masked[i] = kv
+		name, _, _ := strings.Cut(kv, "=")
+		for key := range e.sess.Inputs {
+			if e.sess.IsSecret(key) && task.InputEnvVar(key) == name {
+				masked[i] = name + "=***"
+				break
+			}
+		}
+	}
+	return masked
+}
+
+func (e *recordingExecutor) Prompt(message string, defaultValue string) (string, error) {
+	if e.prompter == nil {
+		return "", fmt.Errorf("cannot prompt for '%s' while planning", message)
+	}
+	return e.prompter.Prompt(message, defaultValue)
+}
+
+// shellQuote single-quotes s when it contains characters a shell would interpret
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(s, " \t\n'\"\\$`|&;<>()*?[]{}~#!") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
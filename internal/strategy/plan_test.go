@@ -0,0 +1,229 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/config/schema"
+	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/task"
+)
+
+func TestPlan_RecordsCommandsWithoutExecuting(t *testing.T) {
+	cfg := &config.Config{
+		Services: []config.ServiceConfig{
+			{
+				Name: "api",
+				Dir:  "api",
+				Modules: []config.ModuleConfig{
+					{Go: &config.GoConfig{}},
+				},
+			},
+		},
+		Workflows: []config.Workflow{
+			{Name: "ci", Commands: config.PlainSteps("go build:api", "echo 'all done'")},
+		},
+	}
+	mock := &mockExecutor{}
+	sess := session.NewSession(cfg, mock)
+
+	s := GetStrategyForCommand("workflow:ci", sess)
+	if s == nil {
+		t.Fatal("expected workflow strategy")
+	}
+	cmds, err := Plan(s, sess)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []PlannedCommand{
+		{Task: "go:build", Dir: "api", Args: []string{"go", "build", "./..."}},
+		{Task: "shell:run", Args: []string{"sh", "-c", "echo 'all done'"}},
+	}
+	if !reflect.DeepEqual(cmds, expected) {
+		t.Errorf("expected %+v, got %+v", expected, cmds)
+	}
+	if len(mock.commands) != 0 {
+		t.Errorf("expected nothing to be executed, got %v", mock.commands)
+	}
+}
+
+func TestPlan_PromptsForInputs(t *testing.T) {
+	tsk := &mockTaskWithReqs{
+		name: "needs-input",
+		reqs: []task.InputRequirement{{Key: "env", Prompt: "Environment", Default: "dev"}},
+	}
+	sess := session.NewSession(&config.Config{}, &mockExecutor{})
+
+	if _, err := Plan(NewBaseStrategy("test", []task.Task{tsk}), sess); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sess.Inputs["env"] != "dev" {
+		t.Errorf("expected input 'env' to be collected, got %q", sess.Inputs["env"])
+	}
+}
+
+func TestPlannedCommand_String(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"go", "build", "./..."}, "go build ./..."},
+		{[]string{"sh", "-c", "echo 'hi there'"}, `sh -c 'echo '\''hi there'\'''`},
+		{[]string{"op", "run", "--env-file=.envs/dev.env", "--", "docker", "compose", "up"}, "op run --env-file=.envs/dev.env -- docker compose up"},
+		{[]string{"echo", ""}, "echo ''"},
+	}
+	for _, tt := range tests {
+		if got := (PlannedCommand{Args: tt.args}).String(); got != tt.expected {
+			t.Errorf("String(%v) = %q, want %q", tt.args, got, tt.expected)
+		}
+	}
+}
+
+func TestPlan_UserCommandEnvMasksSecrets(t *testing.T) {
+	cfg := &config.Config{
+		Commands: []config.CommandConfig{{
+			Name:   "deploy",
+			Run:    schema.CommandRun{Args: []string{"./deploy"}},
+			Env:    map[string]string{"STAGE": "prod"},
+			Inputs: []config.CommandInput{{Key: "token", Type: "secret"}},
+		}},
+	}
+	sess := session.NewSession(cfg, &mockExecutor{})
+	sess.Inputs["token"] = "s3cret"
+
+	cmds, err := Plan(GetStrategyForCommand("command:deploy", sess), sess)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []PlannedCommand{{
+		Task: "command:deploy",
+		Env:  []string{"STAGE=prod", "CLEAT_INPUT_TOKEN=***"},
+		Args: []string{"./deploy"},
+	}}
+	if !reflect.DeepEqual(cmds, expected) {
+		t.Errorf("expected %+v, got %+v", expected, cmds)
+	}
+	if got := cmds[0].String(); got != "STAGE=prod CLEAT_INPUT_TOKEN='***' ./deploy" {
+		t.Errorf("unexpected rendering %q", got)
+	}
+}
+
+func TestPlanExport_LeavesMissingInputsToTheEnvironment(t *testing.T) {
+	cfg := &config.Config{
+		Commands: []config.CommandConfig{{
+			Name:   "greet",
+			Run:    schema.CommandRun{Shell: "echo hello"},
+			Inputs: []config.CommandInput{{Key: "who"}, {Key: "greeting", Default: "hi"}, {Key: "lang"}},
+		}},
+		Workflows: []config.Workflow{{
+			Name:     "release",
+			Params:   []config.WorkflowParam{{Name: "version"}},
+			Commands: config.PlainSteps("echo releasing ${version}"),
+		}},
+	}
+	sess := session.NewSession(cfg, &errorPromptExecutor{})
+	sess.Inputs["lang"] = "en"
+
+	cmds, err := PlanExport(GetStrategyForCommand("command:greet", sess), sess)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `CLEAT_INPUT_GREETING="${CLEAT_INPUT_GREETING:-hi}" CLEAT_INPUT_LANG=en sh -c 'echo hello'`
+	if got := cmds[0].String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	cmds, err = PlanExport(GetStrategyForCommand("workflow:release", sess), sess)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = `sh -c 'echo releasing '"${CLEAT_INPUT_VERSION}"`
+	if got := cmds[0].String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestPlanExport_RefusesWorkflowsItCannotExpress(t *testing.T) {
+	steps := config.PlainSteps("echo build")
+	tests := []struct {
+		name    string
+		wf      config.Workflow
+		wantErr string
+	}{
+		{
+			name:    "condition on an unset input",
+			wf:      config.Workflow{ID: "deploy", Name: "deploy", Commands: append(steps, config.WorkflowStep{Run: "echo prod", If: "inputs.env == 'prod'"})},
+			wantErr: "workflow 'deploy' step 'echo prod' has an if condition, which cannot be exported",
+		},
+		{
+			name:    "continue on error",
+			wf:      config.Workflow{ID: "deploy", Name: "deploy", Commands: append(steps, config.WorkflowStep{Run: "echo lint", ContinueOnError: true})},
+			wantErr: "workflow 'deploy' step 'echo lint' has continue_on_error, which cannot be exported",
+		},
+		{
+			name:    "finally",
+			wf:      config.Workflow{ID: "deploy", Name: "deploy", Commands: steps, Finally: config.PlainSteps("echo cleanup")},
+			wantErr: "workflow 'deploy' has finally steps, which cannot be exported",
+		},
+		{
+			name:    "nested workflow",
+			wf:      config.Workflow{ID: "deploy", Name: "deploy", Commands: config.PlainSteps("workflow:cleanup")},
+			wantErr: "workflow 'cleanup' has finally steps, which cannot be exported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Workflows: []config.Workflow{
+				tt.wf,
+				{ID: "cleanup", Name: "cleanup", Commands: steps, Finally: config.PlainSteps("echo cleanup")},
+			}}
+			sess := session.NewSession(cfg, &errorPromptExecutor{})
+			_, err := PlanExport(GetStrategyForCommand("workflow:deploy", sess), sess)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("expected %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPlanExport_ParameterThatChoosesTheTasks(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, ".envs"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".envs", "staging.env"), []byte("DB_PASSWORD=op://vault/db/password\n"), 0644)
+	oldLookPath := task.LookPath
+	task.LookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	defer func() { task.LookPath = oldLookPath }()
+
+	cfg := &config.Config{
+		SourcePath: filepath.Join(tmpDir, "cleat.yaml"),
+		Envs:       []string{"staging"},
+		Terraform:  &config.TerraformConfig{},
+		Workflows: []config.Workflow{{
+			Name:     "deploy",
+			Params:   []config.WorkflowParam{{Name: "env"}},
+			Commands: config.PlainSteps("terraform plan:${env}", "echo deployed ${env}"),
+		}},
+	}
+
+	sess := session.NewSession(cfg, &errorPromptExecutor{})
+	_, err := PlanExport(GetStrategyForCommand("workflow:deploy", sess), sess)
+	want := "workflow 'deploy' step 'terraform plan:${env}' cannot be exported without a value for env, which decides what it runs (use --input env=...)"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+
+	sess = session.NewSession(cfg, &errorPromptExecutor{})
+	sess.Inputs["env"] = "staging"
+	cmds, err := PlanExport(GetStrategyForCommand("workflow:deploy", sess), sess)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cmds) != 2 || !strings.HasPrefix(cmds[0].String(), "op run --env-file=") {
+		t.Errorf("expected terraform plan wrapped in op run, got %v", cmds)
+	}
+}
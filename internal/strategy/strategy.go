@@ -1,10 +1,15 @@
 package strategy
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/executor"
 	"github.com/madewithfuture/cleat/internal/logger"
 	"github.com/madewithfuture/cleat/internal/session"
 	"github.com/madewithfuture/cleat/internal/task"
@@ -62,8 +67,8 @@ type ExecutionMode int
 const (
 	// Serial runs tasks one at a time
 	Serial ExecutionMode = iota
-	// Parallel runs independent tasks concurrently (future)
-	// Parallel
+	// Parallel runs independent tasks concurrently, bounded by Session.Jobs
+	Parallel
 )
 
 // BaseStrategy provides common execution logic
@@ -81,8 +86,16 @@ func NewBaseStrategy(name string, tasks []task.Task) *BaseStrategy {
 	}
 }
 
-func (s *BaseStrategy) Name() string       { return s.name }
-func (s *BaseStrategy) Tasks() []task.Task { return s.tasks }
+// NewParallelStrategy creates a strategy whose independent tasks may run concurrently
+func NewParallelStrategy(name string, tasks []task.Task) *BaseStrategy {
+	s := NewBaseStrategy(name, tasks)
+	s.mode = Parallel
+	return s
+}
+
+func (s *BaseStrategy) Name() string        { return s.name }
+func (s *BaseStrategy) Tasks() []task.Task  { return s.tasks }
+func (s *BaseStrategy) Mode() ExecutionMode { return s.mode }
 
 func (s *BaseStrategy) ResolveTasks(sess *session.Session) ([]task.Task, error) {
 	return s.buildExecutionPlan(sess)
@@ -104,37 +117,280 @@ func (s *BaseStrategy) Execute(sess *session.Session) error {
 		return nil
 	}
 
-	// Collect requirements from all tasks
-	requirements := make(map[string]task.InputRequirement)
-	for _, t := range plan {
-		for _, req := range t.Requirements(sess) {
-			requirements[req.Key] = req
+	if err := promptMissingInputs(sess, plan); err != nil {
+		return err
+	}
+
+	// Execute tasks
+	if s.mode == Parallel && sess.Jobs > 1 {
+		if err := s.executeParallel(sess, plan, sess.Jobs); err != nil {
+			return err
+		}
+	} else {
+		for _, t := range plan {
+			if sess.Context().Err() != nil {
+				logger.Warn("strategy cancelled", map[string]interface{}{"strategy": s.name, "next_task": t.Name()})
+				return fmt.Errorf("%s cancelled before task '%s': %w", s.name, t.Name(), executor.CancelCause(sess.Context()))
+			}
+			logger.Debug("running task", map[string]interface{}{"task": t.Name()})
+			if err := t.Run(sess); err != nil {
+				if executor.IsCancelled(err) {
+					logger.Warn("task cancelled", map[string]interface{}{"task": t.Name(), "strategy": s.name})
+					return fmt.Errorf("task '%s' cancelled: %w", t.Name(), err)
+				}
+				logger.Error("task execution failed", err, map[string]interface{}{"task": t.Name(), "strategy": s.name})
+				return fmt.Errorf("task '%s' failed: %w", t.Name(), err)
+			}
 		}
 	}
 
-	// Prompt for missing inputs
-	for key, req := range requirements {
+	logger.Info("strategy completed successfully", map[string]interface{}{"strategy": s.name})
+	task.PrintStep(fmt.Sprintf("%s completed successfully", s.name))
+	return nil
+}
+
+// promptMissingInputs collects the requirements of tasks and fills in any
+// input not already present in the session, in key order. Values come from
+// CLEAT_INPUT_<KEY> environment variables first, then from prompting. With
+// Session.NoInput set, defaults are used and every key without one is
+// reported instead of prompting. Supplied values are validated against
+// their requirement and secret keys are marked on the session.
+func promptMissingInputs(sess *session.Session, tasks []task.Task) error {
+	var reqs []task.InputRequirement
+	for _, t := range tasks {
+		reqs = append(reqs, t.Requirements(sess)...)
+	}
+	return CollectInputs(sess, reqs)
+}
+
+// InterpolateConfig collects the inputs that the session's config values
+// refer to, then replaces its config with a copy whose references are
+// expanded. It is done once per session, before strategies are resolved, so
+// their tasks are planned from the expanded values and running them again
+// leaves the config as it is.
+func InterpolateConfig(sess *session.Session) error {
+	if err := CollectInputs(sess, valueRequirements(sess)); err != nil {
+		return err
+	}
+	return interpolate(sess)
+}
+
+// InterpolateConfigForExport is InterpolateConfig for PlanExport: inputs
+// without a value are never prompted for, but become references to their
+// CLEAT_INPUT_ environment variable
+func InterpolateConfigForExport(sess *session.Session) error {
+	if err := referenceInputs(sess, valueRequirements(sess)); err != nil {
+		return err
+	}
+	return interpolate(sess)
+}
+
+// valueRequirements asks for the inputs that config values refer to
+func valueRequirements(sess *session.Session) []task.InputRequirement {
+	var reqs []task.InputRequirement
+	for _, key := range config.ValueInputs(sess.Config) {
 		if _, ok := sess.Inputs[key]; !ok {
-			val, err := sess.Exec.Prompt(req.Prompt, req.Default)
-			if err != nil {
-				return fmt.Errorf("failed to get input for %s: %w", key, err)
+			reqs = append(reqs, task.InputRequirement{Key: key, Prompt: key})
+		}
+	}
+	return reqs
+}
+
+// interpolate expands the references in the session's config values, once
+// the inputs they may refer to are known
+func interpolate(sess *session.Session) error {
+	cfg, err := config.Interpolate(sess.Config, sess.Inputs)
+	if err != nil {
+		return fmt.Errorf("failed to interpolate config: %w", err)
+	}
+	sess.Config = cfg
+	return nil
+}
+
+// CollectInputs fills sess.Inputs for reqs the same way strategies do before
+// running their tasks
+func CollectInputs(sess *session.Session, reqs []task.InputRequirement) error {
+	requirements := make(map[string]task.InputRequirement)
+	for _, req := range reqs {
+		requirements[req.Key] = req
+	}
+
+	var keys []string
+	for k := range requirements {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var missing []task.InputRequirement
+	for _, key := range keys {
+		req := requirements[key]
+		if req.IsSecret() {
+			sess.MarkSecret(key)
+		}
+		val, ok := sess.Inputs[key]
+		if !ok {
+			val, ok = os.LookupEnv(task.InputEnvVar(key))
+		}
+		if !ok {
+			missing = append(missing, req)
+			continue
+		}
+		parsed, err := req.Parse(val)
+		if err != nil {
+			return fmt.Errorf("invalid input: %w", err)
+		}
+		sess.Inputs[key] = parsed
+	}
+
+	if sess.NoInput {
+		// Without a terminal, defaults stand in for the answer a user would accept
+		var unresolved []task.InputRequirement
+		for _, req := range missing {
+			if req.Default != "" {
+				parsed, err := req.Parse(req.Default)
+				if err != nil {
+					return fmt.Errorf("invalid default: %w", err)
+				}
+				sess.Inputs[req.Key] = parsed
+				continue
 			}
-			sess.Inputs[key] = val
+			unresolved = append(unresolved, req)
+		}
+		if len(unresolved) > 0 {
+			return &MissingInputsError{Requirements: unresolved}
 		}
+		return nil
 	}
 
-	// Execute tasks
+	for _, req := range missing {
+		val, err := task.PromptFor(sess.Exec, req)
+		if err != nil {
+			return fmt.Errorf("failed to get input for %s: %w", req.Key, err)
+		}
+		sess.Inputs[req.Key] = val
+	}
+	return nil
+}
+
+// MissingInputsError lists the inputs that could not be prompted for in --no-input mode
+type MissingInputsError struct {
+	Requirements []task.InputRequirement
+}
+
+func (e *MissingInputsError) Error() string {
+	var b strings.Builder
+	b.WriteString("missing required inputs (--no-input is set):")
+	for _, req := range e.Requirements {
+		fmt.Fprintf(&b, "\n  %s: %s", req.Key, req.Prompt)
+		switch req.Kind {
+		case task.InputChoice:
+			fmt.Fprintf(&b, " [one of: %s]", strings.Join(req.Choices, ", "))
+		case task.InputConfirm:
+			b.WriteString(" [yes/no]")
+		}
+		fmt.Fprintf(&b, " (use --input %s=<value> or %s)", req.Key, task.InputEnvVar(req.Key))
+	}
+	return b.String()
+}
+
+// errSiblingFailed cancels the remaining tasks of a parallel run after a failure
+var errSiblingFailed = errors.New("stopped after another task failed")
+
+// executeParallel runs the plan with at most jobs tasks in flight. A task is
+// started only once every task it depends on has finished successfully. After
+// the first failure no new tasks are started and running siblings are
+// cancelled; every real failure is reported.
+func (s *BaseStrategy) executeParallel(sess *session.Session, plan []task.Task, jobs int) error {
+	logger.Info("executing strategy in parallel", map[string]interface{}{"strategy": s.name, "jobs": jobs})
+
+	ctx, cancel := context.WithCancelCause(sess.Context())
+	defer cancel(nil)
+	runSess := sess.WithContext(ctx)
+
+	// Task names are not unique (e.g. go:build for several services), so a
+	// dependency is satisfied once every planned task with that name is done.
+	pending := make(map[string]int)
+	pendingServices := make(map[string]int)
 	for _, t := range plan {
-		logger.Debug("running task", map[string]interface{}{"task": t.Name()})
-		if err := t.Run(sess); err != nil {
-			logger.Error("task execution failed", err, map[string]interface{}{"task": t.Name(), "strategy": s.name})
-			return fmt.Errorf("task '%s' failed: %w", t.Name(), err)
+		pending[t.Name()]++
+		pendingServices[serviceOf(t)]++
+	}
+
+	ready := func(t task.Task) bool {
+		for _, dep := range t.Dependencies() {
+			if pending[dep] > 0 {
+				return false
+			}
+		}
+		for _, dep := range serviceDependencies(sess, t) {
+			if pendingServices[dep] > 0 {
+				return false
+			}
 		}
+		return true
 	}
 
-	logger.Info("strategy completed successfully", map[string]interface{}{"strategy": s.name})
-	task.PrintStep(fmt.Sprintf("%s completed successfully", s.name))
-	return nil
+	type result struct {
+		idx int
+		err error
+	}
+	results := make(chan result)
+	started := make([]bool, len(plan))
+	running := 0
+	var errs []error
+
+	for {
+		if ctx.Err() == nil {
+			for i, t := range plan {
+				if running >= jobs {
+					break
+				}
+				if started[i] || !ready(t) {
+					continue
+				}
+				started[i] = true
+				running++
+				logger.Debug("running task", map[string]interface{}{"task": t.Name()})
+				go func(idx int, t task.Task) {
+					results <- result{idx: idx, err: t.Run(runSess)}
+				}(i, t)
+			}
+		}
+
+		if running == 0 {
+			break
+		}
+
+		r := <-results
+		running--
+		t := plan[r.idx]
+		pending[t.Name()]--
+		pendingServices[serviceOf(t)]--
+		switch {
+		case r.err == nil:
+		case executor.IsCancelled(r.err) && errors.Is(context.Cause(ctx), errSiblingFailed):
+			logger.Warn("task stopped after sibling failure", map[string]interface{}{"task": t.Name(), "strategy": s.name})
+		case executor.IsCancelled(r.err):
+			logger.Warn("task cancelled", map[string]interface{}{"task": t.Name(), "strategy": s.name})
+			errs = append(errs, fmt.Errorf("task '%s' cancelled: %w", t.Name(), r.err))
+			cancel(r.err)
+		default:
+			logger.Error("task execution failed", r.err, map[string]interface{}{"task": t.Name(), "strategy": s.name})
+			errs = append(errs, fmt.Errorf("task '%s' failed: %w", t.Name(), r.err))
+			cancel(errSiblingFailed)
+		}
+	}
+
+	for i, t := range plan {
+		if !started[i] {
+			logger.Warn("task skipped", map[string]interface{}{"task": t.Name(), "strategy": s.name})
+		}
+	}
+
+	if len(errs) == 0 && sess.Context().Err() != nil {
+		return fmt.Errorf("%s cancelled: %w", s.name, executor.CancelCause(sess.Context()))
+	}
+	return errors.Join(errs...)
 }
 
 // buildExecutionPlan returns tasks in dependency order, filtering by ShouldRun
@@ -154,7 +410,102 @@ func (s *BaseStrategy) buildExecutionPlan(sess *session.Session) ([]task.Task, e
 	}
 
 	// Topological sort for dependency order
-	return topologicalSort(candidates, taskMap, sess)
+	plan, err := topologicalSort(candidates, taskMap, sess)
+	if err != nil {
+		return nil, err
+	}
+	return orderByServiceDependencies(plan, sess)
+}
+
+// serviceOf returns the name of the service t acts on, or "" for project-wide tasks
+func serviceOf(t task.Task) string {
+	if scoped, ok := t.(task.ServiceScoped); ok {
+		if svc := scoped.TargetService(); svc != nil {
+			return svc.Name
+		}
+	}
+	return ""
+}
+
+// serviceDependencies returns the services whose tasks must finish before t runs
+func serviceDependencies(sess *session.Session, t task.Task) []string {
+	name := serviceOf(t)
+	if name == "" || sess.Config == nil {
+		return nil
+	}
+	return sess.Config.ServiceDependencies(name)
+}
+
+// orderByServiceDependencies moves the tasks of each service after those of
+// the services it depends on (services.depends_on), otherwise keeping the
+// order of plan
+func orderByServiceDependencies(plan []task.Task, sess *session.Session) ([]task.Task, error) {
+	waits := make([][]int, len(plan))
+	ordered := true
+	for j, t := range plan {
+		deps := make(map[string]bool)
+		for _, dep := range serviceDependencies(sess, t) {
+			deps[dep] = true
+		}
+		for i, other := range plan {
+			if i == j {
+				continue
+			}
+			if deps[serviceOf(other)] || dependsOnTask(t, other) {
+				waits[j] = append(waits[j], i)
+				if i > j {
+					ordered = false
+				}
+			}
+		}
+	}
+	if ordered {
+		return plan, nil
+	}
+
+	// Kahn's algorithm, always taking the earliest ready task to stay close to plan
+	var result []task.Task
+	placed := make([]bool, len(plan))
+	for len(result) < len(plan) {
+		next := -1
+		for j := range plan {
+			if placed[j] {
+				continue
+			}
+			ready := true
+			for _, i := range waits[j] {
+				if !placed[i] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = j
+				break
+			}
+		}
+		if next == -1 {
+			var names []string
+			for j, t := range plan {
+				if !placed[j] {
+					names = append(names, fmt.Sprintf("%s (%s)", t.Name(), serviceOf(t)))
+				}
+			}
+			return nil, fmt.Errorf("circular dependency detected between service tasks: %s", strings.Join(names, ", "))
+		}
+		placed[next] = true
+		result = append(result, plan[next])
+	}
+	return result, nil
+}
+
+func dependsOnTask(t task.Task, other task.Task) bool {
+	for _, dep := range t.Dependencies() {
+		if dep == other.Name() {
+			return true
+		}
+	}
+	return false
 }
 
 // topologicalSort orders tasks respecting dependencies
@@ -222,10 +573,10 @@ func topologicalSort(tasks []task.Task, allTasks map[string]task.Task, sess *ses
 		for _, depName := range dependents[t.Name()] {
 			inDegree[depName]--
 			if inDegree[depName] == 0 {
+				// Several tasks may share a name (one per service), release them all
 				for _, candidate := range tasks {
 					if candidate.Name() == depName {
 						queue = append(queue, candidate)
-						break
 					}
 				}
 			}
@@ -282,6 +633,7 @@ func GetStrategyForCommand(command string, sess *session.Session) Strategy {
 func GetProviders() []CommandProvider {
 	return []CommandProvider{
 		&WorkflowProvider{},
+		&UserCommandProvider{},
 		&NpmProvider{},
 		&GoProvider{},
 		&DockerProvider{},
@@ -299,8 +651,8 @@ func GetProviders() []CommandProvider {
 type PassthroughProvider struct{}
 
 func (p *PassthroughProvider) CanHandle(command string) bool {
-	// Don't handle internal workflow commands that failed to match a known workflow
-	if strings.HasPrefix(command, "workflow:") {
+	// Don't handle references that failed to match a known workflow or command
+	if strings.HasPrefix(command, "workflow:") || strings.HasPrefix(command, task.CommandPrefix) {
 		return false
 	}
 	return true // Can handle any other command not caught by others
@@ -1,7 +1,11 @@
 package strategy
 
 import (
+	"context"
 	"errors"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/madewithfuture/cleat/internal/config"
@@ -17,12 +21,12 @@ type mockExecutor struct {
 	err             error
 }
 
-func (m *mockExecutor) Run(name string, args ...string) error {
+func (m *mockExecutor) Run(ctx context.Context, name string, args ...string) error {
 	m.commands = append(m.commands, name)
 	return m.err
 }
 
-func (m *mockExecutor) RunWithDir(dir string, name string, args ...string) error {
+func (m *mockExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
 	m.commands = append(m.commands, name)
 	return m.err
 }
@@ -501,6 +505,125 @@ func TestBuildStrategy(t *testing.T) {
 	}
 }
 
+func TestTestStrategy(t *testing.T) {
+	cfg := &config.Config{
+		Services: []config.ServiceConfig{
+			{
+				Name: "api",
+				Modules: []config.ModuleConfig{
+					{Python: &config.PythonConfig{Django: true}},
+					{Go: &config.GoConfig{}},
+				},
+			},
+			{
+				Name: "web",
+				Modules: []config.ModuleConfig{
+					{Npm: &config.NpmConfig{Scripts: []string{"build", "test"}}},
+					{Ruby: &config.RubyConfig{Rails: true}},
+				},
+			},
+			{
+				Name: "docs",
+				Modules: []config.ModuleConfig{
+					{Npm: &config.NpmConfig{Scripts: []string{"build"}}},
+				},
+			},
+		},
+	}
+	s, ok := Get("test", cfg)
+	if !ok {
+		t.Fatal("expected test strategy to be registered")
+	}
+
+	var names []string
+	for _, tk := range s.Tasks() {
+		names = append(names, tk.Name())
+	}
+	expected := []string{"python:test", "go:test", "npm:run:test", "ruby:test"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected tasks %v, got %v", expected, names)
+	}
+
+	if bs, ok := s.(*BaseStrategy); !ok || bs.Mode() != Parallel {
+		t.Error("expected test strategy to run in parallel mode")
+	}
+}
+
+func TestInstallStrategy(t *testing.T) {
+	cfg := &config.Config{
+		Services: []config.ServiceConfig{
+			{
+				Name: "api",
+				Modules: []config.ModuleConfig{
+					{Python: &config.PythonConfig{PackageManager: "poetry"}},
+					{Go: &config.GoConfig{}},
+				},
+			},
+			{
+				Name: "web",
+				Modules: []config.ModuleConfig{
+					{Npm: &config.NpmConfig{}},
+					{Ruby: &config.RubyConfig{}},
+				},
+			},
+		},
+	}
+	s, ok := Get("install", cfg)
+	if !ok {
+		t.Fatal("expected install strategy to be registered")
+	}
+
+	sess := session.NewSession(cfg, &mockExecutor{})
+	cmds, err := Plan(s, sess)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, c := range cmds {
+		got = append(got, c.Task+": "+c.String())
+	}
+	expected := []string{
+		"python:install: poetry install",
+		"go:mod-download: go mod download",
+		"npm:install: npm install",
+		"ruby:install: bundle install",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestFmtStrategyCheck(t *testing.T) {
+	cfg := &config.Config{
+		Terraform: &config.TerraformConfig{},
+		Services: []config.ServiceConfig{
+			{Name: "api", Dir: t.TempDir(), Modules: []config.ModuleConfig{{Go: &config.GoConfig{}}}},
+		},
+	}
+	s, ok := Get("fmt", cfg)
+	if !ok {
+		t.Fatal("expected fmt strategy to be registered")
+	}
+	if _, ok := Get("lint", cfg); !ok {
+		t.Fatal("expected lint strategy to be registered")
+	}
+
+	sess := session.NewSession(cfg, &mockExecutor{})
+	sess.CheckOnly = true
+	cmds, err := Plan(s, sess)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, c := range cmds {
+		got = append(got, c.Task+": "+c.Args[0])
+	}
+	expected := []string{"fmt:gofmt: sh", "fmt:terraform: terraform"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
 func TestRunStrategy(t *testing.T) {
 	cfg := &config.Config{
 		Services: []config.ServiceConfig{
@@ -621,10 +744,10 @@ func TestResolveCommandTasks(t *testing.T) {
 	cfg.Workflows = []config.Workflow{
 		{
 			Name: "test-workflow",
-			Commands: []string{
+			Commands: config.PlainSteps(
 				"docker:up",
 				"django:migrate",
-			},
+			),
 		},
 	}
 	sess := session.NewSession(cfg, &mockExecutor{})
@@ -653,3 +776,379 @@ func TestResolveCommandTasks(t *testing.T) {
 		})
 	}
 }
+
+// blockingTask waits on gate before finishing so tests can observe concurrency
+type blockingTask struct {
+	mockTask
+	started chan string
+	gate    chan struct{}
+}
+
+func (t *blockingTask) Run(sess *session.Session) error {
+	t.started <- t.Name()
+	<-t.gate
+	return t.runErr
+}
+
+func TestParallelExecution_RunsIndependentTasksConcurrently(t *testing.T) {
+	started := make(chan string, 3)
+	gate := make(chan struct{})
+	a := &blockingTask{mockTask: mockTask{BaseTask: task.BaseTask{TaskName: "a"}, shouldRun: true}, started: started, gate: gate}
+	b := &blockingTask{mockTask: mockTask{BaseTask: task.BaseTask{TaskName: "b"}, shouldRun: true}, started: started, gate: gate}
+	c := &blockingTask{mockTask: mockTask{BaseTask: task.BaseTask{TaskName: "c", TaskDeps: []string{"a", "b"}}, shouldRun: true}, started: started, gate: gate}
+
+	s := NewParallelStrategy("test", []task.Task{a, b, c})
+	sess := session.NewSession(&config.Config{}, &mockExecutor{})
+	sess.Jobs = 2
+
+	done := make(chan error)
+	go func() { done <- s.Execute(sess) }()
+
+	// a and b must both start before either is released
+	first, second := <-started, <-started
+	if first == "c" || second == "c" {
+		t.Fatalf("dependent task started before its dependencies: %s, %s", first, second)
+	}
+	close(gate)
+
+	if got := <-started; got != "c" {
+		t.Errorf("expected c to start last, got %q", got)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParallelExecution_ReportsAllFailures(t *testing.T) {
+	started := make(chan string, 3)
+	gate := make(chan struct{})
+	a := &blockingTask{mockTask: mockTask{BaseTask: task.BaseTask{TaskName: "a"}, shouldRun: true, runErr: errors.New("a broke")}, started: started, gate: gate}
+	b := &blockingTask{mockTask: mockTask{BaseTask: task.BaseTask{TaskName: "b"}, shouldRun: true, runErr: errors.New("b broke")}, started: started, gate: gate}
+	c := &mockTask{BaseTask: task.BaseTask{TaskName: "c", TaskDeps: []string{"a"}}, shouldRun: true}
+
+	s := NewParallelStrategy("test", []task.Task{a, b, c})
+	sess := session.NewSession(&config.Config{}, &mockExecutor{})
+	sess.Jobs = 4
+
+	done := make(chan error)
+	go func() { done <- s.Execute(sess) }()
+	<-started
+	<-started
+	close(gate)
+
+	err := <-done
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	for _, want := range []string{"a broke", "b broke"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %v", want, err)
+		}
+	}
+	if c.runCalled {
+		t.Error("expected c NOT to run after its dependency failed")
+	}
+}
+
+func TestParallelStrategy_SerialWhenSingleJob(t *testing.T) {
+	executionOrder := []string{}
+	task1 := &orderTrackingTask{mockTask: mockTask{BaseTask: task.BaseTask{TaskName: "task1"}, shouldRun: true}, order: &executionOrder}
+	task2 := &orderTrackingTask{mockTask: mockTask{BaseTask: task.BaseTask{TaskName: "task2"}, shouldRun: true}, order: &executionOrder}
+
+	s := NewParallelStrategy("test", []task.Task{task1, task2})
+	if s.Mode() != Parallel {
+		t.Errorf("expected Parallel mode, got %v", s.Mode())
+	}
+	sess := session.NewSession(&config.Config{}, &mockExecutor{})
+
+	if err := s.Execute(sess); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(executionOrder) != 2 || executionOrder[0] != "task1" || executionOrder[1] != "task2" {
+		t.Errorf("expected serial order [task1 task2], got %v", executionOrder)
+	}
+}
+
+func TestTopologicalSort_DuplicateNamesWithDependencies(t *testing.T) {
+	dockerBuild := &mockTask{BaseTask: task.BaseTask{TaskName: "docker:build"}, shouldRun: true}
+	goWeb := &mockTask{BaseTask: task.BaseTask{TaskName: "go:build", TaskDeps: []string{"docker:build"}}, shouldRun: true}
+	goAPI := &mockTask{BaseTask: task.BaseTask{TaskName: "go:build", TaskDeps: []string{"docker:build"}}, shouldRun: true}
+
+	s := NewBaseStrategy("test", []task.Task{dockerBuild, goWeb, goAPI})
+	plan, err := s.ResolveTasks(session.NewSession(&config.Config{}, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan) != 3 {
+		t.Fatalf("expected 3 tasks in plan, got %d", len(plan))
+	}
+	if plan[0] != dockerBuild || plan[1] != goWeb || plan[2] != goAPI {
+		t.Errorf("unexpected plan order: %v", plan)
+	}
+}
+
+func TestBuildStrategy_ServiceDependencyOrder(t *testing.T) {
+	cfg := &config.Config{
+		Services: []config.ServiceConfig{
+			{Name: "web", DependsOn: []string{"api"}, Modules: []config.ModuleConfig{{Npm: &config.NpmConfig{Scripts: []string{"build"}}}}},
+			{Name: "api", DependsOn: []string{"lib"}, Modules: []config.ModuleConfig{{Go: &config.GoConfig{}}}},
+			{Name: "lib", Modules: []config.ModuleConfig{{Go: &config.GoConfig{}}}},
+		},
+	}
+	s := NewBuildStrategy(cfg)
+	plan, err := s.ResolveTasks(session.NewSession(cfg, &mockExecutor{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, tsk := range plan {
+		got = append(got, tsk.Name()+"@"+serviceOf(tsk))
+	}
+	expected := []string{"go:build@lib", "go:build@api", "npm:run:build@web"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+// serviceTask is a blockingTask that belongs to a service
+type serviceTask struct {
+	blockingTask
+	service *config.ServiceConfig
+}
+
+func (t *serviceTask) TargetService() *config.ServiceConfig { return t.service }
+
+func TestParallelExecution_WaitsForDependedOnServices(t *testing.T) {
+	cfg := &config.Config{Services: []config.ServiceConfig{{Name: "lib"}, {Name: "api", DependsOn: []string{"lib"}}, {Name: "web"}}}
+	started := make(chan string, 3)
+	gate := make(chan struct{})
+	newTask := func(name string, svc *config.ServiceConfig) *serviceTask {
+		return &serviceTask{
+			blockingTask: blockingTask{mockTask: mockTask{BaseTask: task.BaseTask{TaskName: name}, shouldRun: true}, started: started, gate: gate},
+			service:      svc,
+		}
+	}
+	api := newTask("api:build", &cfg.Services[1])
+	lib := newTask("lib:build", &cfg.Services[0])
+	web := newTask("web:build", &cfg.Services[2])
+
+	s := NewParallelStrategy("build", []task.Task{api, lib, web})
+	sess := session.NewSession(cfg, &mockExecutor{})
+	sess.Jobs = 3
+
+	done := make(chan error)
+	go func() { done <- s.Execute(sess) }()
+
+	first, second := <-started, <-started
+	if first == "api:build" || second == "api:build" {
+		t.Fatalf("api started before the lib service finished: %s, %s", first, second)
+	}
+	close(gate)
+	if got := <-started; got != "api:build" {
+		t.Errorf("expected api to start last, got %q", got)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// ctxTask blocks until its session context is cancelled
+type ctxTask struct {
+	mockTask
+	started chan string
+}
+
+func (t *ctxTask) Run(sess *session.Session) error {
+	t.runCalled = true
+	t.started <- t.Name()
+	<-sess.Context().Done()
+	return executor.CancelCause(sess.Context())
+}
+
+func TestBaseStrategy_CancelledContextStopsExecution(t *testing.T) {
+	task1 := &mockTask{BaseTask: task.BaseTask{TaskName: "task1"}, shouldRun: true}
+	s := NewBaseStrategy("test", []task.Task{task1})
+	sess := session.NewSession(&config.Config{}, &mockExecutor{})
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(&executor.InterruptedError{Signal: os.Interrupt})
+	sess.SetContext(ctx)
+
+	err := s.Execute(sess)
+	if !executor.IsCancelled(err) {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+	if task1.runCalled {
+		t.Error("expected task NOT to run after cancellation")
+	}
+}
+
+func TestBaseStrategy_TaskCancelledIsReportedAsCancelled(t *testing.T) {
+	task1 := &mockTask{BaseTask: task.BaseTask{TaskName: "task1"}, shouldRun: true, runErr: &executor.InterruptedError{Signal: os.Interrupt}}
+	task2 := &mockTask{BaseTask: task.BaseTask{TaskName: "task2"}, shouldRun: true}
+	s := NewBaseStrategy("test", []task.Task{task1, task2})
+
+	err := s.Execute(session.NewSession(&config.Config{}, &mockExecutor{}))
+	if !executor.IsCancelled(err) {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("expected error to mention cancellation, got %v", err)
+	}
+	if task2.runCalled {
+		t.Error("expected task2 NOT to run after task1 was cancelled")
+	}
+}
+
+func TestParallelExecution_FailureCancelsRunningSiblings(t *testing.T) {
+	started := make(chan string, 2)
+	gate := make(chan struct{})
+	failing := &blockingTask{mockTask: mockTask{BaseTask: task.BaseTask{TaskName: "failing"}, shouldRun: true, runErr: errors.New("boom")}, started: started, gate: gate}
+	sibling := &ctxTask{mockTask: mockTask{BaseTask: task.BaseTask{TaskName: "sibling"}, shouldRun: true}, started: started}
+
+	s := NewParallelStrategy("test", []task.Task{failing, sibling})
+	sess := session.NewSession(&config.Config{}, &mockExecutor{})
+	sess.Jobs = 2
+
+	done := make(chan error)
+	go func() { done <- s.Execute(sess) }()
+	<-started
+	<-started
+	close(gate)
+
+	err := <-done
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected failure to be reported, got %v", err)
+	}
+	if strings.Contains(err.Error(), "sibling") {
+		t.Errorf("expected stopped sibling not to be reported as a failure, got %v", err)
+	}
+	if sess.Context().Err() != nil {
+		t.Error("expected caller's session context to stay usable")
+	}
+}
+
+func TestPromptMissingInputs_EnvironmentVariables(t *testing.T) {
+	t.Setenv("CLEAT_INPUT_GCP_ACCOUNT", "ci@example.com")
+	tsk := &mockTaskWithReqs{
+		name: "gcp",
+		reqs: []task.InputRequirement{{Key: "gcp:account", Prompt: "Enter GCP account email"}},
+	}
+	mock := &mockExecutorWithPrompts{}
+	sess := session.NewSession(&config.Config{}, mock)
+
+	if err := promptMissingInputs(sess, []task.Task{tsk}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sess.Inputs["gcp:account"] != "ci@example.com" {
+		t.Errorf("expected input from environment, got %q", sess.Inputs["gcp:account"])
+	}
+	if len(mock.promptsCalled) != 0 {
+		t.Errorf("expected no prompts, got %v", mock.promptsCalled)
+	}
+}
+
+func TestPromptMissingInputs_NoInput(t *testing.T) {
+	tsk := &mockTaskWithReqs{
+		name: "multi",
+		reqs: []task.InputRequirement{
+			{Key: "gcp:version", Prompt: "Enter version"},
+			{Key: "install_path", Prompt: "Installation path", Default: "/usr/local/bin"},
+			{Key: "gcp:account", Prompt: "Enter GCP account email"},
+			{Key: "supplied", Prompt: "Already supplied"},
+		},
+	}
+	mock := &mockExecutorWithPrompts{}
+	sess := session.NewSession(&config.Config{}, mock)
+	sess.NoInput = true
+	sess.Inputs["supplied"] = "yes"
+
+	err := promptMissingInputs(sess, []task.Task{tsk})
+	var missing *MissingInputsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected MissingInputsError, got %v", err)
+	}
+	if len(missing.Requirements) != 2 {
+		t.Fatalf("expected 2 missing inputs, got %+v", missing.Requirements)
+	}
+	for _, want := range []string{"gcp:account: Enter GCP account email", "gcp:version: Enter version", "CLEAT_INPUT_GCP_VERSION"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got:\n%v", want, err)
+		}
+	}
+	if sess.Inputs["install_path"] != "/usr/local/bin" {
+		t.Errorf("expected default to be used for install_path, got %q", sess.Inputs["install_path"])
+	}
+	if len(mock.promptsCalled) != 0 {
+		t.Errorf("expected no prompts in no-input mode, got %v", mock.promptsCalled)
+	}
+}
+
+func TestCollectInputs_Typed(t *testing.T) {
+	reqs := []task.InputRequirement{
+		{Key: "token", Prompt: "API token", Kind: task.InputSecret},
+		{Key: "env", Prompt: "Environment", Kind: task.InputChoice, Choices: []string{"dev", "prod"}},
+		{Key: "deploy", Prompt: "Deploy now?", Kind: task.InputConfirm},
+	}
+
+	t.Run("PromptsAndParses", func(t *testing.T) {
+		mock := &mockExecutorWithPrompts{promptResponses: map[string]string{
+			"API token":              "s3cret",
+			"Environment (dev/prod)": "2",
+			"Deploy now? (y/n)":      "y",
+		}}
+		sess := session.NewSession(&config.Config{}, mock)
+
+		if err := CollectInputs(sess, reqs); err != nil {
+			t.Fatalf("CollectInputs failed: %v", err)
+		}
+		want := map[string]string{"token": "s3cret", "env": "prod", "deploy": "true"}
+		for k, v := range want {
+			if sess.Inputs[k] != v {
+				t.Errorf("expected %s=%q, got %q", k, v, sess.Inputs[k])
+			}
+		}
+		if !sess.IsSecret("token") {
+			t.Error("expected token to be marked secret")
+		}
+		if _, ok := sess.PublicInputs(sess.Inputs)["token"]; ok {
+			t.Error("expected token to be excluded from public inputs")
+		}
+	})
+
+	t.Run("InvalidSuppliedValue", func(t *testing.T) {
+		sess := session.NewSession(&config.Config{}, &mockExecutorWithPrompts{})
+		sess.Inputs["env"] = "staging"
+
+		err := CollectInputs(sess, reqs)
+		if err == nil || !strings.Contains(err.Error(), "not one of: dev, prod") {
+			t.Errorf("expected invalid choice error, got %v", err)
+		}
+	})
+
+	t.Run("InvalidEnvironmentValue", func(t *testing.T) {
+		t.Setenv("CLEAT_INPUT_DEPLOY", "perhaps")
+		sess := session.NewSession(&config.Config{}, &mockExecutorWithPrompts{})
+
+		err := CollectInputs(sess, reqs)
+		if err == nil || !strings.Contains(err.Error(), "deploy: answer yes or no") {
+			t.Errorf("expected invalid confirm error, got %v", err)
+		}
+	})
+
+	t.Run("NoInputListsChoices", func(t *testing.T) {
+		sess := session.NewSession(&config.Config{}, &mockExecutorWithPrompts{})
+		sess.NoInput = true
+
+		err := CollectInputs(sess, reqs)
+		if err == nil {
+			t.Fatal("expected missing inputs error")
+		}
+		for _, want := range []string{"[one of: dev, prod]", "[yes/no]"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("expected error to contain %q, got:\n%v", want, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package strategy
+
+import (
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/task"
+)
+
+func init() {
+	Register("test", NewTestStrategy)
+}
+
+// NewTestStrategy creates the test command strategy, running the test suite
+// of every stack in every service
+func NewTestStrategy(cfg *config.Config) Strategy {
+	var tasks []task.Task
+	if cfg == nil {
+		return NewParallelStrategy("test", tasks)
+	}
+
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		for j := range svc.Modules {
+			mod := &svc.Modules[j]
+			if mod.Go != nil {
+				tasks = append(tasks, task.NewGoAction(svc, mod.Go, "test"))
+			}
+			if mod.Python != nil {
+				tasks = append(tasks, task.NewPythonTest(svc))
+			}
+			if mod.Ruby != nil {
+				tasks = append(tasks, task.NewRubyAction(svc, mod.Ruby, "test"))
+			}
+			if mod.Npm != nil {
+				for _, s := range mod.Npm.Scripts {
+					if s == "test" {
+						tasks = append(tasks, task.NewNpmRun(svc, mod.Npm, "test"))
+					}
+				}
+			}
+		}
+	}
+
+	return NewParallelStrategy("test", tasks)
+}
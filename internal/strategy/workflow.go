@@ -1,10 +1,14 @@
 package strategy
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"sort"
+	"regexp"
 	"strings"
 
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/executor"
 	"github.com/madewithfuture/cleat/internal/logger"
 	"github.com/madewithfuture/cleat/internal/session"
 	"github.com/madewithfuture/cleat/internal/task"
@@ -25,37 +29,241 @@ func (p *WorkflowProvider) GetStrategy(command string, sess *session.Session) St
 	wfKey := strings.TrimPrefix(command, "workflow:")
 	for _, wf := range sess.Config.Workflows {
 		if wf.ID == wfKey || wf.Name == wfKey {
-			return NewWorkflowStrategy(wf.Name, wf.Commands)
+			return NewWorkflowStrategyFromConfig(wf)
 		}
 	}
 
 	return nil
 }
 
-// WorkflowStrategy executes a sequence of commands
+// Parameterized is implemented by strategies that take parameters. They are
+// collected as inputs before the strategy's tasks are resolved.
+type Parameterized interface {
+	Params() []task.InputRequirement
+}
+
+// Parameters returns the inputs to collect before the tasks of s resolve:
+// the parameters of a Parameterized strategy and the inputs a workflow's
+// steps refer to
+func Parameters(s Strategy, sess *session.Session) []task.InputRequirement {
+	var reqs []task.InputRequirement
+	if p, ok := s.(Parameterized); ok {
+		reqs = append(reqs, p.Params()...)
+	}
+	if w, ok := s.(*WorkflowStrategy); ok {
+		reqs = append(reqs, w.StepInputs(sess)...)
+	}
+	return reqs
+}
+
+// WorkflowStrategy executes a sequence of steps, then its finally steps
 type WorkflowStrategy struct {
-	name     string
-	commands []string
+	name    string
+	steps   []config.WorkflowStep
+	finally []config.WorkflowStep
+	params  []config.WorkflowParam
+
+	// onStep is told the outcome of every step that was considered
+	onStep func(StepOutcome)
+	// resumed maps the index of a step that already succeeded to its command
+	resumed map[int]string
+}
+
+// StepOutcome reports how a workflow step ended
+type StepOutcome struct {
+	// Index counts the steps and then the finally steps, starting at 0
+	Index   int
+	Command string
+	// Outcome is "success", "failure" or "skipped"
+	Outcome string
+	Err     error
 }
 
 func NewWorkflowStrategy(name string, commands []string) *WorkflowStrategy {
 	return &WorkflowStrategy{
-		name:     name,
-		commands: commands,
+		name:  name,
+		steps: config.PlainSteps(commands...),
 	}
 }
 
+// NewWorkflowStrategyFromConfig creates the strategy of a configured workflow
+func NewWorkflowStrategyFromConfig(wf config.Workflow) *WorkflowStrategy {
+	return &WorkflowStrategy{
+		name:    wf.Name,
+		steps:   wf.Commands,
+		finally: wf.Finally,
+		params:  wf.Params,
+	}
+}
+
+// OnStep registers fn to be called as each step of the workflow ends
+func (s *WorkflowStrategy) OnStep(fn func(StepOutcome)) {
+	s.onStep = fn
+}
+
+// Resume marks steps of an earlier run as done. succeeded maps the index of
+// each step that succeeded to the command it ran; those steps are not run
+// again and count as successful in conditions.
+func (s *WorkflowStrategy) Resume(succeeded map[int]string) {
+	s.resumed = succeeded
+}
+
 func (s *WorkflowStrategy) Name() string {
 	return "workflow:" + s.name
 }
 
+// Params returns an input requirement for each workflow parameter
+func (s *WorkflowStrategy) Params() []task.InputRequirement {
+	var reqs []task.InputRequirement
+	for _, p := range s.params {
+		req := task.InputRequirement{
+			Key:     p.Name,
+			Prompt:  p.Description,
+			Default: p.Default,
+			Choices: p.Choices,
+		}
+		if req.Prompt == "" {
+			req.Prompt = fmt.Sprintf("Value for workflow parameter '%s'", p.Name)
+		}
+		if len(p.Choices) > 0 {
+			req.Kind = task.InputChoice
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
+
+// StepInputs returns a requirement for each ${input:key} without a default
+// in the steps, or in the steps of the workflows they run, that is not a
+// parameter. Steps are expanded as they resolve, so these inputs are needed
+// before the workflow's tasks resolve, like its parameters.
+func (s *WorkflowStrategy) StepInputs(sess *session.Session) []task.InputRequirement {
+	var reqs []task.InputRequirement
+	seen := make(map[string]bool)
+	for _, p := range s.params {
+		seen[p.Name] = true
+	}
+	s.walk(sess, func(w *WorkflowStrategy, step config.WorkflowStep, cmd string, finally bool) bool {
+		for _, key := range config.InputRefs(cmd) {
+			if !seen[key] {
+				seen[key] = true
+				reqs = append(reqs, task.InputRequirement{Key: key, Prompt: key})
+			}
+		}
+		return true
+	})
+	return reqs
+}
+
+// exportable returns an error when a step of the workflow, or of a workflow
+// it runs, has a condition or continue_on_error, or when one of them has
+// finally steps. Exported files run the commands one after another and stop
+// at the first failure, so they cannot do what those steps ask.
+func (s *WorkflowStrategy) exportable(sess *session.Session) error {
+	var err error
+	s.walk(sess, func(w *WorkflowStrategy, step config.WorkflowStep, cmd string, finally bool) bool {
+		switch {
+		case finally:
+			err = fmt.Errorf("workflow '%s' has finally steps, which cannot be exported", w.name)
+		case step.If != "":
+			err = fmt.Errorf("workflow '%s' step '%s' has an if condition, which cannot be exported", w.name, step.Run)
+		case step.ContinueOnError:
+			err = fmt.Errorf("workflow '%s' step '%s' has continue_on_error, which cannot be exported", w.name, step.Run)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// walk calls fn with each step, then each finally step, of the workflow and
+// of the workflows its steps run, with cmd the step's command after its
+// workflow's parameters are expanded. It stops when fn returns false.
+func (s *WorkflowStrategy) walk(sess *session.Session, fn func(w *WorkflowStrategy, step config.WorkflowStep, cmd string, finally bool) bool) {
+	visited := make(map[string]bool)
+	var visit func(w *WorkflowStrategy) bool
+	visit = func(w *WorkflowStrategy) bool {
+		if visited[w.name] {
+			return true
+		}
+		visited[w.name] = true
+		for i, list := range [][]config.WorkflowStep{w.steps, w.finally} {
+			for _, step := range list {
+				cmd := ExpandParams(step.Run, w.params, sess.Inputs)
+				if !fn(w, step, cmd, i == 1) {
+					return false
+				}
+				if !strings.HasPrefix(cmd, "workflow:") {
+					continue
+				}
+				if nested, ok := (&WorkflowProvider{}).GetStrategy(cmd, sess).(*WorkflowStrategy); ok && !visit(nested) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	visit(s)
+}
+
 // Tasks returns nil for WorkflowStrategy as tasks are resolved dynamically.
 // Callers MUST use ResolveTasks(session) to get the task list.
 func (s *WorkflowStrategy) Tasks() []task.Task {
 	return nil
 }
 
+// resolvedStep is a workflow step with its tasks
+type resolvedStep struct {
+	config.WorkflowStep
+	index   int
+	command string
+	tasks   []task.Task
+	finally bool
+	// nested is the workflow a workflow: step runs. It runs as a unit, with
+	// its own conditions and finally steps; tasks lists what it would run.
+	nested *WorkflowStrategy
+}
+
+// done reports whether the step succeeded in the run being resumed
+func (s *WorkflowStrategy) done(st resolvedStep) bool {
+	if st.finally {
+		return false
+	}
+	_, ok := s.resumed[st.index]
+	return ok
+}
+
+// ResolveTasks returns the tasks of the steps that would run if every step
+// succeeded: steps whose condition is false on that path are left out, and
+// finally steps come last.
 func (s *WorkflowStrategy) ResolveTasks(sess *session.Session) ([]task.Task, error) {
+	steps, err := s.resolveSteps(sess)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &stepState{inputs: sess.Inputs, outcomes: make(map[string]string)}
+	var allTasks []task.Task
+	for _, st := range steps {
+		if s.done(st) {
+			state.record(st, "success")
+			continue
+		}
+		run, err := evalCondition(s.condition(st, sess), state)
+		if err != nil {
+			return nil, fmt.Errorf("workflow '%s' step '%s': %w", s.name, st.Run, err)
+		}
+		if !run {
+			state.record(st, "skipped")
+			continue
+		}
+		state.record(st, "success")
+		allTasks = append(allTasks, st.tasks...)
+	}
+	return allTasks, nil
+}
+
+// resolveSteps resolves the tasks of every step, including finally steps
+func (s *WorkflowStrategy) resolveSteps(sess *session.Session) ([]resolvedStep, error) {
 	// 1. Detect cycles
 	for _, name := range sess.WorkflowStack {
 		if name == s.name {
@@ -77,60 +285,294 @@ func (s *WorkflowStrategy) ResolveTasks(sess *session.Session) ([]task.Task, err
 		}
 	}()
 
-	var allTasks []task.Task
-	for _, cmd := range s.commands {
-		tasks, err := ResolveCommandTasks(cmd, sess)
-		if err != nil {
-			return nil, fmt.Errorf("workflow '%s' failed to resolve command '%s': %w", s.name, cmd, err)
+	var missing []string
+	for _, p := range s.params {
+		if _, ok := sess.Inputs[p.Name]; !ok && p.Default == "" {
+			missing = append(missing, p.Name)
 		}
-		allTasks = append(allTasks, tasks...)
 	}
-	return allTasks, nil
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("workflow '%s' needs parameter(s) %s (use --param name=value)", s.name, strings.Join(missing, ", "))
+	}
+
+	var steps []resolvedStep
+	add := func(list []config.WorkflowStep, finally bool) error {
+		for _, step := range list {
+			cmd, err := config.Expand(ExpandParams(step.Run, s.params, sess.Inputs), sess.Config, sess.Inputs)
+			if err != nil {
+				return fmt.Errorf("workflow '%s' step '%s': %w", s.name, step.Run, err)
+			}
+			strat := GetStrategyForCommand(cmd, sess)
+			if strat == nil {
+				return fmt.Errorf("workflow '%s' failed to resolve command '%s': unknown command: %s", s.name, cmd, cmd)
+			}
+			if keys := referencedInputs(cmd, sess); len(keys) > 0 {
+				// Only a shell command can read an input from the environment
+				// when it runs; elsewhere the value chooses the tasks
+				if _, shell := strat.(*PassthroughStrategy); !shell || strings.HasPrefix(cmd, inputRefMark) {
+					return fmt.Errorf("workflow '%s' step '%s' cannot be exported without a value for %s, which decides what it runs (use --input %s=...)", s.name, step.Run, strings.Join(keys, ", "), keys[0])
+				}
+			}
+			tasks, err := strat.ResolveTasks(sess)
+			if err != nil {
+				return fmt.Errorf("workflow '%s' failed to resolve command '%s': %w", s.name, cmd, err)
+			}
+			nested, _ := strat.(*WorkflowStrategy)
+			steps = append(steps, resolvedStep{WorkflowStep: step, index: len(steps), command: cmd, tasks: tasks, finally: finally, nested: nested})
+		}
+		return nil
+	}
+	if err := add(s.steps, false); err != nil {
+		return nil, err
+	}
+	if err := add(s.finally, true); err != nil {
+		return nil, err
+	}
+
+	// A resumed run is only meaningful if its steps are still the same.
+	// Finally steps run again anyway.
+	for idx, cmd := range s.resumed {
+		if idx >= len(steps) || steps[idx].command != cmd {
+			return nil, fmt.Errorf("workflow '%s' has changed since the run being resumed (step %d was '%s')", s.name, idx+1, cmd)
+		}
+	}
+	return steps, nil
+}
+
+// StepError is a problem with one step of a workflow, found by Validate
+type StepError struct {
+	Step config.WorkflowStep
+	// Kind is "command" for the workflow's commands and "finally" for its finally steps
+	Kind  string
+	Index int
+	// Command is the step's command with parameters expanded
+	Command string
+	Err     error
+}
+
+var errNoCommand = errors.New("has no command")
+
+func (e *StepError) Error() string {
+	if e.Err == errNoCommand {
+		return fmt.Sprintf("%s step %d %v", e.Kind, e.Index+1, e.Err)
+	}
+	return fmt.Sprintf("%s step %d '%s': %v", e.Kind, e.Index+1, e.Command, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Validate resolves every step without running anything and returns a
+// *StepError for each step that could not run: unknown commands, workflow
+// cycles and malformed conditions. Parameters without a value keep their
+// ${name} reference, and ${env:VAR} and the like are not expanded.
+func (s *WorkflowStrategy) Validate(sess *session.Session) []error {
+	for _, p := range s.params {
+		if _, ok := sess.Inputs[p.Name]; !ok && p.Default == "" {
+			sess.Inputs[p.Name] = "${" + p.Name + "}"
+		}
+	}
+
+	sess.WorkflowStack = append(sess.WorkflowStack, s.name)
+	defer func() {
+		sess.WorkflowStack = sess.WorkflowStack[:len(sess.WorkflowStack)-1]
+	}()
+
+	var errs []error
+	check := func(list []config.WorkflowStep, finally bool, kind string) {
+		for i, step := range list {
+			if strings.TrimSpace(step.Run) == "" {
+				errs = append(errs, &StepError{Step: step, Kind: kind, Index: i, Err: errNoCommand})
+				continue
+			}
+			cmd := ExpandParams(step.Run, s.params, sess.Inputs)
+			if _, err := ResolveCommandTasks(cmd, sess); err != nil {
+				errs = append(errs, &StepError{Step: step, Kind: kind, Index: i, Command: cmd, Err: err})
+			}
+			cond := s.condition(resolvedStep{WorkflowStep: step, finally: finally}, sess)
+			if _, err := evalCondition(cond, &stepState{}); err != nil {
+				errs = append(errs, &StepError{Step: step, Kind: kind, Index: i, Command: cmd, Err: err})
+			}
+		}
+	}
+	check(s.steps, false, "command")
+	check(s.finally, true, "finally")
+	return errs
+}
+
+// condition returns the step's if: expression with parameters expanded.
+// Finally steps run unless their condition says otherwise: where other steps
+// check success(), they check always().
+func (s *WorkflowStrategy) condition(st resolvedStep, sess *session.Session) string {
+	cond := ExpandParams(st.If, s.params, sess.Inputs)
+	if tokens, err := tokenize(cond); st.finally && err == nil && !checksStatus(tokens) {
+		if len(tokens) == 0 {
+			return "always()"
+		}
+		// && binds tighter than ||, so this is always() && (cond)
+		return "always() && " + cond
+	}
+	return cond
 }
 
 func (s *WorkflowStrategy) Execute(sess *session.Session) error {
 	logger.Info("executing workflow strategy", map[string]interface{}{"workflow": s.name})
 
-	// 1. Resolve all tasks to collect requirements upfront
-	allTasks, err := s.ResolveTasks(sess)
-	if err != nil {
+	// 1. Collect parameters, which the commands need before they resolve
+	if err := CollectInputs(sess, Parameters(s, sess)); err != nil {
 		return err
 	}
 
-	// 2. Collect requirements from all tasks
-	requirements := make(map[string]task.InputRequirement)
-	for _, t := range allTasks {
-		for _, req := range t.Requirements(sess) {
-			requirements[req.Key] = req
+	// 2. Resolve all steps to collect requirements upfront
+	steps, err := s.resolveSteps(sess)
+	if err != nil {
+		return err
+	}
+	var allTasks []task.Task
+	for _, st := range steps {
+		if s.done(st) {
+			continue
 		}
+		// Surface malformed conditions before anything runs
+		if _, err := evalCondition(s.condition(st, sess), &stepState{}); err != nil {
+			return fmt.Errorf("workflow '%s' step '%s': %w", s.name, st.Run, err)
+		}
+		allTasks = append(allTasks, st.tasks...)
 	}
 
-	// 3. Prompt for missing inputs
-	var keys []string
-	for k := range requirements {
-		keys = append(keys, k)
+	// 3. Prompt for inputs required by any task
+	if err := promptMissingInputs(sess, allTasks); err != nil {
+		return err
 	}
-	sort.Strings(keys)
 
-	for _, key := range keys {
-		req := requirements[key]
-		if _, ok := sess.Inputs[key]; !ok {
-			val, err := sess.Exec.Prompt(req.Prompt, req.Default)
-			if err != nil {
-				return fmt.Errorf("failed to get input for %s: %w", key, err)
+	// 4. Execute steps sequentially; after a failure only steps whose
+	// condition allows it run, and finally steps always get their turn
+	state := &stepState{inputs: sess.Inputs, outcomes: make(map[string]string)}
+	var errs []error
+	cancelled := false
+	for _, st := range steps {
+		if s.done(st) {
+			logger.Info("skipping workflow step that already succeeded", map[string]interface{}{"workflow": s.name, "step": st.command})
+			task.PrintStep(fmt.Sprintf("Skipping '%s' (succeeded in the resumed run)", st.command))
+			state.record(st, "success")
+			continue
+		}
+		runSess := sess
+		if st.finally {
+			// Cleanup must still run after Ctrl-C; a second one stops cleat
+			runSess = sess.WithContext(context.WithoutCancel(sess.Context()))
+		} else if cancelled {
+			continue
+		} else if sess.Context().Err() != nil {
+			logger.Warn("workflow cancelled", map[string]interface{}{"workflow": s.name, "next_step": st.Run})
+			errs = append(errs, fmt.Errorf("workflow '%s' cancelled before step '%s': %w", s.name, st.Run, executor.CancelCause(sess.Context())))
+			state.failed = true
+			cancelled = true
+			continue
+		}
+
+		if err := s.runStep(runSess, st, state); err != nil {
+			errs = append(errs, err)
+			if executor.IsCancelled(err) {
+				cancelled = true
 			}
-			sess.Inputs[key] = val
 		}
 	}
 
-	// 4. Execute tasks sequentially
-	for _, t := range allTasks {
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	task.PrintStep(fmt.Sprintf("Workflow '%s' completed successfully", s.name))
+	return nil
+}
+
+// runStep runs the tasks of one step, or the workflow it names, and records
+// its outcome. A failure is returned unless the step continues on error.
+func (s *WorkflowStrategy) runStep(sess *session.Session, st resolvedStep, state *stepState) error {
+	run, err := evalCondition(s.condition(st, sess), state)
+	if err != nil {
+		return fmt.Errorf("workflow '%s' step '%s': %w", s.name, st.Run, err)
+	}
+	if !run {
+		logger.Info("skipping workflow step", map[string]interface{}{"workflow": s.name, "step": st.Run, "if": st.If})
+		task.PrintStep(fmt.Sprintf("Skipping '%s'", st.Run))
+		s.report(state, st, "skipped", nil)
+		return nil
+	}
+
+	if st.nested != nil {
+		logger.Debug("running nested workflow", map[string]interface{}{"workflow": s.name, "nested": st.nested.name})
+		if err := st.nested.Execute(sess); err != nil {
+			return s.stepFailed(st, state, fmt.Sprintf("workflow '%s'", st.nested.name), err)
+		}
+		s.report(state, st, "success", nil)
+		return nil
+	}
+	for _, t := range st.tasks {
 		logger.Debug("running workflow task", map[string]interface{}{"workflow": s.name, "task": t.Name()})
 		if err := t.Run(sess); err != nil {
-			return fmt.Errorf("workflow '%s' task '%s' failed: %w", s.name, t.Name(), err)
+			return s.stepFailed(st, state, fmt.Sprintf("task '%s'", t.Name()), err)
 		}
 	}
-
-	task.PrintStep(fmt.Sprintf("Workflow '%s' completed successfully", s.name))
+	s.report(state, st, "success", nil)
 	return nil
 }
+
+// stepFailed records that what, a task or nested workflow of st, failed with
+// err, and returns the failure unless the step continues on error
+func (s *WorkflowStrategy) stepFailed(st resolvedStep, state *stepState, what string, err error) error {
+	s.report(state, st, "failure", err)
+	if executor.IsCancelled(err) {
+		logger.Warn("workflow step cancelled", map[string]interface{}{"workflow": s.name, "step": st.Run})
+		state.failed = true
+		return fmt.Errorf("workflow '%s' %s cancelled: %w", s.name, what, err)
+	}
+	if st.ContinueOnError {
+		logger.Warn("workflow step failed, continuing", map[string]interface{}{"workflow": s.name, "step": st.Run, "error": err.Error()})
+		task.PrintStep(fmt.Sprintf("'%s' failed, continuing: %v", st.Run, err))
+		return nil
+	}
+	state.failed = true
+	return fmt.Errorf("workflow '%s' %s failed: %w", s.name, what, err)
+}
+
+// report records the outcome of a step that was run or skipped
+func (s *WorkflowStrategy) report(state *stepState, st resolvedStep, outcome string, err error) {
+	state.record(st, outcome)
+	if s.onStep != nil {
+		s.onStep(StepOutcome{Index: st.index, Command: st.command, Outcome: outcome, Err: err})
+	}
+}
+
+func (state *stepState) record(st resolvedStep, outcome string) {
+	if st.ID != "" {
+		state.outcomes[st.ID] = outcome
+	}
+}
+
+var paramRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_-]*)\}`)
+
+// ExpandParams replaces ${name} in command with the value of the workflow
+// parameter name, taken from inputs or else its default. References to
+// unknown or unset parameters are left as they are.
+func ExpandParams(command string, params []config.WorkflowParam, inputs map[string]string) string {
+	if len(params) == 0 {
+		return command
+	}
+	values := make(map[string]string)
+	for _, p := range params {
+		if v, ok := inputs[p.Name]; ok {
+			values[p.Name] = v
+		} else if p.Default != "" {
+			values[p.Name] = p.Default
+		}
+	}
+
+	return paramRef.ReplaceAllStringFunc(command, func(ref string) string {
+		if v, ok := values[paramRef.FindStringSubmatch(ref)[1]]; ok {
+			return v
+		}
+		return ref
+	})
+}
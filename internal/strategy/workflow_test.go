@@ -1,11 +1,13 @@
 package strategy
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
 
 	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/executor"
 	"github.com/madewithfuture/cleat/internal/session"
 	"github.com/madewithfuture/cleat/internal/task"
 )
@@ -17,7 +19,7 @@ type mockWorkflowExecutor struct {
 	failOnCommand    string   // command substring to trigger failure
 }
 
-func (m *mockWorkflowExecutor) Run(name string, args ...string) error {
+func (m *mockWorkflowExecutor) Run(ctx context.Context, name string, args ...string) error {
 	fullCmd := name
 	if len(args) > 0 {
 		fullCmd += " " + strings.Join(args, " ")
@@ -30,8 +32,8 @@ func (m *mockWorkflowExecutor) Run(name string, args ...string) error {
 	return nil
 }
 
-func (m *mockWorkflowExecutor) RunWithDir(dir string, name string, args ...string) error {
-	return m.Run(name, args...)
+func (m *mockWorkflowExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
+	return m.Run(ctx, name, args...)
 }
 
 func (m *mockWorkflowExecutor) Prompt(message string, defaultValue string) (string, error) {
@@ -59,7 +61,7 @@ func TestWorkflowPromptOrder(t *testing.T) {
 	mockExec := &mockWorkflowExecutor{}
 	cfg := &config.Config{
 		Workflows: []config.Workflow{
-			{Name: "order-test", Commands: []string{"cmd-z", "cmd-a", "cmd-m"}},
+			{Name: "order-test", Commands: config.PlainSteps("cmd-z", "cmd-a", "cmd-m")},
 		},
 	}
 	sess := session.NewSession(cfg, mockExec)
@@ -90,7 +92,7 @@ func TestWorkflowSequentialExecution(t *testing.T) {
 		Workflows: []config.Workflow{
 			{
 				Name:     "seq-test",
-				Commands: []string{"echo step1", "echo step2"},
+				Commands: config.PlainSteps("echo step1", "echo step2"),
 			},
 		},
 	}
@@ -127,7 +129,7 @@ func TestWorkflowFailFast(t *testing.T) {
 		Workflows: []config.Workflow{
 			{
 				Name:     "fail-test",
-				Commands: []string{"echo step1", "echo step2"},
+				Commands: config.PlainSteps("echo step1", "echo step2"),
 			},
 		},
 	}
@@ -158,7 +160,7 @@ func TestWorkflowErrorMessage(t *testing.T) {
 		Workflows: []config.Workflow{
 			{
 				Name:     "err-test",
-				Commands: []string{"echo step1"},
+				Commands: config.PlainSteps("echo step1"),
 			},
 		},
 	}
@@ -217,7 +219,7 @@ func TestWorkflowInputPrompting(t *testing.T) {
 		Workflows: []config.Workflow{
 			{
 				Name:     "prompt-test",
-				Commands: []string{"test-req"},
+				Commands: config.PlainSteps("test-req"),
 			},
 		},
 	}
@@ -254,21 +256,50 @@ func TestUnknownWorkflowError(t *testing.T) {
 	}
 }
 
+func TestWorkflowValidate_StepErrors(t *testing.T) {
+	cfg := &config.Config{
+		Commands: []config.CommandConfig{{Name: "hello", Run: config.CommandRun{Shell: "echo hi"}}},
+	}
+	sess := session.NewSession(cfg, &mockWorkflowExecutor{})
+	if strat := GetStrategyForCommand("command:unknown", sess); strat != nil {
+		t.Errorf("Expected nil strategy for unknown command, got %v", strat)
+	}
+
+	steps := config.PlainSteps("command:hello", "command:unknown")
+	steps[1].Line, steps[1].Column = 7, 9
+	s := NewWorkflowStrategyFromConfig(config.Workflow{Name: "ship", Commands: steps, Finally: config.PlainSteps("")})
+
+	errs := s.Validate(sess)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	var stepErr *StepError
+	if !errors.As(errs[0], &stepErr) || stepErr.Kind != "command" || stepErr.Index != 1 || stepErr.Step.Line != 7 {
+		t.Errorf("expected a StepError for command step 2 on line 7, got %#v", errs[0])
+	}
+	if errs[0].Error() != "command step 2 'command:unknown': unknown command: command:unknown" {
+		t.Errorf("unexpected message: %v", errs[0])
+	}
+	if errs[1].Error() != "finally step 1 has no command" {
+		t.Errorf("unexpected message: %v", errs[1])
+	}
+}
+
 func TestWorkflowRecursionLoop(t *testing.T) {
 	mockExec := &mockWorkflowExecutor{}
 	cfg := &config.Config{
 		Workflows: []config.Workflow{
 			{
 				Name:     "loop-a",
-				Commands: []string{"workflow:loop-a"},
+				Commands: config.PlainSteps("workflow:loop-a"),
 			},
 			{
 				Name:     "loop-b-1",
-				Commands: []string{"workflow:loop-b-2"},
+				Commands: config.PlainSteps("workflow:loop-b-2"),
 			},
 			{
 				Name:     "loop-b-2",
-				Commands: []string{"workflow:loop-b-1"},
+				Commands: config.PlainSteps("workflow:loop-b-1"),
 			},
 		},
 	}
@@ -321,7 +352,7 @@ func TestWorkflowDoubleResolution(t *testing.T) {
 		Workflows: []config.Workflow{
 			{
 				Name:     "spy-wf",
-				Commands: []string{"spy-cmd"},
+				Commands: config.PlainSteps("spy-cmd"),
 			},
 		},
 	}
@@ -340,3 +371,381 @@ func TestWorkflowDoubleResolution(t *testing.T) {
 		t.Fatalf("Expected 1 resolution, got %d", spy.resolveCount)
 	}
 }
+
+func TestWorkflowStrategy_CancelledStopsRemainingSteps(t *testing.T) {
+	mock := &mockWorkflowExecutor{}
+	sess := session.NewSession(&config.Config{}, mock)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sess.SetContext(ctx)
+
+	s := NewWorkflowStrategy("wf", []string{"echo one", "echo two"})
+	err := s.Execute(sess)
+	if !executor.IsCancelled(err) {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+	if len(mock.executedCommands) != 0 {
+		t.Errorf("expected no commands to run, got %v", mock.executedCommands)
+	}
+}
+
+func TestWorkflowParams(t *testing.T) {
+	cfg := &config.Config{
+		Workflows: []config.Workflow{
+			{
+				Name: "deploy",
+				Params: []config.WorkflowParam{
+					{Name: "env", Choices: []string{"staging", "prod"}},
+					{Name: "region", Default: "eu"},
+				},
+				Commands: config.PlainSteps("echo deploy ${env} ${region} ${unknown}"),
+			},
+		},
+	}
+
+	t.Run("Missing parameter", func(t *testing.T) {
+		sess := session.NewSession(cfg, &mockWorkflowExecutor{})
+		strat := (&WorkflowProvider{}).GetStrategy("workflow:deploy", sess)
+		_, err := strat.ResolveTasks(sess)
+		if err == nil || !strings.Contains(err.Error(), "needs parameter(s) env") {
+			t.Errorf("expected missing parameter error, got %v", err)
+		}
+	})
+
+	t.Run("Supplied and default values", func(t *testing.T) {
+		mockExec := &mockWorkflowExecutor{}
+		sess := session.NewSession(cfg, mockExec)
+		sess.Inputs["env"] = "prod"
+		strat := (&WorkflowProvider{}).GetStrategy("workflow:deploy", sess)
+		if err := strat.Execute(sess); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mockExec.executedCommands) != 1 || mockExec.executedCommands[0] != "sh -c echo deploy prod eu ${unknown}" {
+			t.Errorf("unexpected commands: %v", mockExec.executedCommands)
+		}
+	})
+
+	t.Run("Invalid choice", func(t *testing.T) {
+		sess := session.NewSession(cfg, &mockWorkflowExecutor{})
+		sess.Inputs["env"] = "dev"
+		strat := (&WorkflowProvider{}).GetStrategy("workflow:deploy", sess)
+		if err := strat.Execute(sess); err == nil {
+			t.Error("expected error for a value outside the parameter's choices")
+		}
+	})
+
+	t.Run("Params as requirements", func(t *testing.T) {
+		sess := session.NewSession(cfg, nil)
+		reqs := (&WorkflowProvider{}).GetStrategy("workflow:deploy", sess).(Parameterized).Params()
+		if len(reqs) != 2 || reqs[0].Kind != task.InputChoice || reqs[1].Default != "eu" {
+			t.Errorf("unexpected requirements: %+v", reqs)
+		}
+	})
+}
+
+func TestWorkflowInterpolation(t *testing.T) {
+	t.Setenv("CLEAT_TEST_REGION", "us")
+	newConfig := func() *config.Config {
+		return &config.Config{
+			GoogleCloudPlatform: &config.GCPConfig{ProjectName: "acme-${input:env}"},
+			Workflows: []config.Workflow{
+				{
+					Name:     "deploy",
+					Params:   []config.WorkflowParam{{Name: "env"}},
+					Commands: config.PlainSteps("echo ${env} ${env:CLEAT_TEST_REGION:-eu} ${config:google_cloud_platform.project_name}"),
+					Finally:  config.PlainSteps("echo ${env:CLEAT_TEST_MISSING}"),
+				},
+			},
+		}
+	}
+
+	cfg := newConfig()
+	cfg.Workflows[0].Finally = nil
+	mockExec := &mockWorkflowExecutor{}
+	sess := session.NewSession(cfg, mockExec)
+	sess.Inputs["env"] = "prod"
+	if err := InterpolateConfig(sess); err != nil {
+		t.Fatalf("InterpolateConfig failed: %v", err)
+	}
+	s := (&WorkflowProvider{}).GetStrategy("workflow:deploy", sess)
+	for run := 0; run < 2; run++ {
+		if err := s.Execute(sess); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(mockExec.executedCommands) != 2 || mockExec.executedCommands[0] != "sh -c echo prod us acme-prod" || mockExec.executedCommands[1] != mockExec.executedCommands[0] {
+		t.Errorf("unexpected commands: %v", mockExec.executedCommands)
+	}
+	if sess.Config.GoogleCloudPlatform.ProjectName != "acme-prod" {
+		t.Errorf("expected the session's config value interpolated, got %q", sess.Config.GoogleCloudPlatform.ProjectName)
+	}
+	if cfg.GoogleCloudPlatform.ProjectName != "acme-${input:env}" {
+		t.Errorf("expected the loaded config left as it is, got %q", cfg.GoogleCloudPlatform.ProjectName)
+	}
+
+	t.Run("Prompted", func(t *testing.T) {
+		mockExec := &answeringExecutor{answers: map[string]string{"env": "staging"}}
+		sess := session.NewSession(newConfig(), mockExec)
+		if err := InterpolateConfig(sess); err != nil {
+			t.Fatalf("InterpolateConfig failed: %v", err)
+		}
+		if len(mockExec.prompts) != 1 || sess.Config.GoogleCloudPlatform.ProjectName != "acme-staging" {
+			t.Errorf("expected env prompted for, got prompts %v and %q", mockExec.prompts, sess.Config.GoogleCloudPlatform.ProjectName)
+		}
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		sess := session.NewSession(newConfig(), nil)
+		if err := InterpolateConfigForExport(sess); err != nil {
+			t.Fatalf("InterpolateConfigForExport failed: %v", err)
+		}
+		if got := (PlannedCommand{Args: []string{sess.Config.GoogleCloudPlatform.ProjectName}}).String(); got != `'acme-'"${CLEAT_INPUT_ENV}"` {
+			t.Errorf("expected a reference to the environment, got %s", got)
+		}
+	})
+
+	sess = session.NewSession(newConfig(), &mockWorkflowExecutor{})
+	sess.Inputs["env"] = "prod"
+	err := (&WorkflowProvider{}).GetStrategy("workflow:deploy", sess).Execute(sess)
+	if err == nil || !strings.Contains(err.Error(), "environment variable CLEAT_TEST_MISSING has no value") {
+		t.Errorf("expected an error for the undefined variable, got %v", err)
+	}
+}
+
+// answeringExecutor answers each prompt with the value of the first key
+// its message contains
+type answeringExecutor struct {
+	mockWorkflowExecutor
+	answers map[string]string
+}
+
+func (m *answeringExecutor) Prompt(message string, defaultValue string) (string, error) {
+	m.prompts = append(m.prompts, message)
+	for key, answer := range m.answers {
+		if strings.Contains(message, key) {
+			return answer, nil
+		}
+	}
+	return defaultValue, nil
+}
+
+func TestWorkflowStepInputs(t *testing.T) {
+	cfg := &config.Config{
+		Workflows: []config.Workflow{
+			{Name: "deploy", Commands: config.PlainSteps("echo ${input:target} ${input:tag:-latest}")},
+			{
+				Name:     "release",
+				Params:   []config.WorkflowParam{{Name: "target", Description: "Where to release"}},
+				Commands: config.PlainSteps("workflow:deploy"),
+				Finally:  config.PlainSteps("echo ${input:notify}"),
+			},
+		},
+	}
+
+	mockExec := &answeringExecutor{answers: map[string]string{"target": "staging", "notify": "ops"}}
+	sess := session.NewSession(cfg, mockExec)
+	s := (&WorkflowProvider{}).GetStrategy("workflow:deploy", sess)
+	if err := s.Execute(sess); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mockExec.prompts) != 1 || len(mockExec.executedCommands) != 1 || mockExec.executedCommands[0] != "sh -c echo staging latest" {
+		t.Errorf("expected a prompt for target before the step ran, got prompts %v and commands %v", mockExec.prompts, mockExec.executedCommands)
+	}
+
+	t.Run("Nested", func(t *testing.T) {
+		s := (&WorkflowProvider{}).GetStrategy("workflow:release", session.NewSession(cfg, nil))
+		var keys []string
+		for _, req := range Parameters(s, session.NewSession(cfg, nil)) {
+			keys = append(keys, req.Key+":"+req.Prompt)
+		}
+		if strings.Join(keys, ",") != "target:Where to release,notify:notify" {
+			t.Errorf("expected the parameter and the inputs of the steps, got %v", keys)
+		}
+
+		mockExec := &answeringExecutor{answers: map[string]string{"Where to release": "prod", "notify": "ops"}}
+		sess := session.NewSession(cfg, mockExec)
+		if err := s.Execute(sess); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Join(mockExec.executedCommands, ",") != "sh -c echo prod latest,sh -c echo ops" {
+			t.Errorf("unexpected commands: %v", mockExec.executedCommands)
+		}
+	})
+}
+
+func TestWorkflowStructuredSteps(t *testing.T) {
+	cfg := &config.Config{
+		Workflows: []config.Workflow{
+			{
+				Name: "integration",
+				Commands: []config.WorkflowStep{
+					{Run: "echo up"},
+					{ID: "lint", Run: "echo lint-broken", ContinueOnError: true},
+					{ID: "tests", Run: "echo tests-broken"},
+					{Run: "echo publish"},
+					{Run: "echo report", If: "failure() && steps.tests.outcome == 'failure' && steps.lint.outcome == 'failure'"},
+					{Run: "echo unreported", If: "steps.tests.outcome == 'failure'"},
+					{Run: "echo prod-only", If: "inputs.env == 'prod'"},
+				},
+				Finally: []config.WorkflowStep{
+					{Run: "echo down"},
+					{Run: "echo notify", If: "success()"},
+				},
+			},
+		},
+	}
+
+	mockExec := &mockWorkflowExecutor{failOnCommand: "broken"}
+	sess := session.NewSession(cfg, mockExec)
+	sess.Inputs["env"] = "prod"
+	strat := (&WorkflowProvider{}).GetStrategy("workflow:integration", sess)
+
+	err := strat.Execute(sess)
+	if err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Fatalf("expected the failing tests step to fail the workflow, got %v", err)
+	}
+
+	expected := []string{
+		"sh -c echo up",
+		"sh -c echo lint-broken",
+		"sh -c echo tests-broken",
+		"sh -c echo report",
+		"sh -c echo down",
+	}
+	// echo prod-only is skipped although env is prod: its condition holds only after success
+	if strings.Join(mockExec.executedCommands, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v, got %v", expected, mockExec.executedCommands)
+	}
+}
+
+func TestWorkflowNestedRunsAsAUnit(t *testing.T) {
+	cfg := &config.Config{
+		Workflows: []config.Workflow{
+			{
+				Name:     "release",
+				Commands: config.PlainSteps("workflow:deploy", "echo announce"),
+				Finally:  config.PlainSteps("echo release-done"),
+			},
+			{
+				Name: "deploy",
+				Commands: []config.WorkflowStep{
+					{Run: "echo lint-broken", ContinueOnError: true},
+					{Run: "echo push-broken"},
+					{Run: "echo verify"},
+					{Run: "echo rollback", If: "failure()"},
+				},
+				Finally: config.PlainSteps("echo cleanup"),
+			},
+		},
+	}
+
+	mockExec := &mockWorkflowExecutor{failOnCommand: "broken"}
+	sess := session.NewSession(cfg, mockExec)
+	err := (&WorkflowProvider{}).GetStrategy("workflow:release", sess).Execute(sess)
+	if err == nil || !strings.Contains(err.Error(), "workflow 'release' workflow 'deploy' failed") {
+		t.Fatalf("expected the nested workflow to fail the release, got %v", err)
+	}
+
+	expected := []string{
+		"sh -c echo lint-broken",
+		"sh -c echo push-broken",
+		"sh -c echo rollback",
+		"sh -c echo cleanup",
+		"sh -c echo release-done",
+	}
+	if strings.Join(mockExec.executedCommands, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v, got %v", expected, mockExec.executedCommands)
+	}
+}
+
+func TestWorkflowStructuredSteps_ResolveTasksAssumesSuccess(t *testing.T) {
+	cfg := &config.Config{
+		Workflows: []config.Workflow{
+			{
+				Name: "deploy",
+				Commands: []config.WorkflowStep{
+					{Run: "echo build"},
+					{Run: "echo rollback", If: "failure()"},
+				},
+				Finally: config.PlainSteps("echo cleanup"),
+			},
+		},
+	}
+	sess := session.NewSession(cfg, &mockWorkflowExecutor{})
+	tasks, err := (&WorkflowProvider{}).GetStrategy("workflow:deploy", sess).ResolveTasks(sess)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected build and cleanup tasks, got %d", len(tasks))
+	}
+}
+
+func TestWorkflowStructuredSteps_InvalidCondition(t *testing.T) {
+	cfg := &config.Config{
+		Workflows: []config.Workflow{
+			{Name: "bad", Commands: []config.WorkflowStep{{Run: "echo a"}, {Run: "echo b", If: "whatever()"}}},
+		},
+	}
+	mockExec := &mockWorkflowExecutor{}
+	sess := session.NewSession(cfg, mockExec)
+	err := (&WorkflowProvider{}).GetStrategy("workflow:bad", sess).Execute(sess)
+	if err == nil || !strings.Contains(err.Error(), "invalid condition") {
+		t.Fatalf("expected invalid condition error, got %v", err)
+	}
+	if len(mockExec.executedCommands) != 0 {
+		t.Errorf("expected nothing to run, got %v", mockExec.executedCommands)
+	}
+}
+
+func TestWorkflowResume(t *testing.T) {
+	wf := config.Workflow{
+		Name: "deploy",
+		Commands: []config.WorkflowStep{
+			{ID: "build", Run: "echo build"},
+			{Run: "echo push"},
+			{Run: "echo announce", If: "steps.build.outcome == 'success'"},
+		},
+		Finally: config.PlainSteps("echo cleanup"),
+	}
+
+	mockExec := &mockWorkflowExecutor{}
+	sess := session.NewSession(&config.Config{}, mockExec)
+	strat := NewWorkflowStrategyFromConfig(wf)
+	strat.Resume(map[int]string{0: "echo build", 3: "echo cleanup"})
+
+	var outcomes []StepOutcome
+	strat.OnStep(func(o StepOutcome) { outcomes = append(outcomes, o) })
+
+	if err := strat.Execute(sess); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"sh -c echo push", "sh -c echo announce", "sh -c echo cleanup"}
+	if strings.Join(mockExec.executedCommands, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v, got %v", expected, mockExec.executedCommands)
+	}
+	if len(outcomes) != 3 || outcomes[0].Index != 1 || outcomes[0].Command != "echo push" || outcomes[2].Index != 3 {
+		t.Errorf("expected outcomes of the steps that ran, got %+v", outcomes)
+	}
+
+	tasks, err := strat.ResolveTasks(session.NewSession(&config.Config{}, &mockWorkflowExecutor{}))
+	if err != nil || len(tasks) != 3 {
+		t.Errorf("expected resumed steps to be left out of the plan, got %d tasks (%v)", len(tasks), err)
+	}
+}
+
+func TestWorkflowResume_ChangedWorkflow(t *testing.T) {
+	mockExec := &mockWorkflowExecutor{}
+	sess := session.NewSession(&config.Config{}, mockExec)
+	strat := NewWorkflowStrategy("deploy", []string{"echo compile", "echo push"})
+	strat.Resume(map[int]string{0: "echo build"})
+
+	err := strat.Execute(sess)
+	if err == nil || !strings.Contains(err.Error(), "has changed") {
+		t.Fatalf("expected changed workflow error, got %v", err)
+	}
+	if len(mockExec.executedCommands) != 0 {
+		t.Errorf("expected nothing to run, got %v", mockExec.executedCommands)
+	}
+}
@@ -0,0 +1,214 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/executor"
+	"github.com/madewithfuture/cleat/internal/session"
+)
+
+// CommandPrefix starts the command string of every user-defined command
+const CommandPrefix = "command:"
+
+// CommandName returns the command string that runs cmd: "command:<name>" for
+// top-level commands and "command:<service>:<name>" for service commands
+func CommandName(svc *config.ServiceConfig, cmd *config.CommandConfig) string {
+	if svc == nil {
+		return CommandPrefix + cmd.Name
+	}
+	return CommandPrefix + svc.Name + ":" + cmd.Name
+}
+
+// CommandTask runs a command declared in the commands section of cleat.yaml
+type CommandTask struct {
+	BaseTask
+	Service *config.ServiceConfig
+	Command *config.CommandConfig
+}
+
+func (t *CommandTask) TargetService() *config.ServiceConfig { return t.Service }
+
+// NewCommandTask creates the task for cmd, declared at the top level when svc
+// is nil. Its dependencies are the task names of the commands in depends_on.
+func NewCommandTask(cfg *config.Config, svc *config.ServiceConfig, cmd *config.CommandConfig) *CommandTask {
+	var deps []string
+	for _, ref := range cmd.DependsOn {
+		if depSvc, depCmd, ok := cfg.FindCommand(svc, ref); ok {
+			deps = append(deps, CommandName(depSvc, depCmd))
+		}
+	}
+
+	desc := cmd.Description
+	if desc == "" {
+		desc = fmt.Sprintf("Run command: %s", strings.Join(commandArgs(cmd), " "))
+	}
+
+	return &CommandTask{
+		BaseTask: BaseTask{
+			TaskName:        CommandName(svc, cmd),
+			TaskDescription: desc,
+			TaskDeps:        deps,
+		},
+		Service: svc,
+		Command: cmd,
+	}
+}
+
+func (t *CommandTask) ShouldRun(sess *session.Session) bool {
+	return t.Command != nil && !t.Command.Run.IsEmpty()
+}
+
+func (t *CommandTask) Run(sess *session.Session) error {
+	PrintStep(fmt.Sprintf("Running %s", t.Command.Name))
+
+	cmd, err := t.expanded(sess)
+	if err != nil {
+		return fmt.Errorf("command '%s': %w", t.Command.Name, err)
+	}
+	args := commandArgs(cmd.Command)
+	dir := cmd.Dir()
+	env := cmd.Env(sess)
+	switch runner, ok := sess.Exec.(executor.EnvRunner); {
+	case len(env) == 0:
+		err = sess.Exec.RunWithDir(sess.Context(), dir, args[0], args[1:]...)
+	case ok:
+		err = runner.RunWithEnv(sess.Context(), dir, env, args[0], args[1:]...)
+	default:
+		args = append(append([]string{"env"}, env...), args...)
+		err = sess.Exec.RunWithDir(sess.Context(), dir, args[0], args[1:]...)
+	}
+	if err != nil {
+		return fmt.Errorf("command '%s' failed: %w", t.Command.Name, err)
+	}
+	return nil
+}
+
+func (t *CommandTask) Commands(sess *session.Session) [][]string {
+	if cmd, err := t.expanded(sess); err == nil {
+		return [][]string{commandArgs(cmd.Command)}
+	}
+	return [][]string{commandArgs(t.Command)}
+}
+
+// expanded returns a copy of the task whose command has the ${env:VAR},
+// ${input:key}, ${config:path} and ${git:branch} references in its run, dir
+// and env replaced by their values
+func (t *CommandTask) expanded(sess *session.Session) (*CommandTask, error) {
+	var errs []error
+	expand := func(s string) string {
+		v, err := config.Expand(s, sess.Config, sess.Inputs)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return v
+	}
+
+	cmd := *t.Command
+	cmd.Run.Shell = expand(cmd.Run.Shell)
+	if len(cmd.Run.Args) > 0 {
+		cmd.Run.Args = make([]string, len(t.Command.Run.Args))
+		for i, a := range t.Command.Run.Args {
+			cmd.Run.Args[i] = expand(a)
+		}
+	}
+	cmd.Dir = expand(cmd.Dir)
+	if len(cmd.Env) > 0 {
+		cmd.Env = make(map[string]string, len(t.Command.Env))
+		for k, v := range t.Command.Env {
+			cmd.Env[k] = expand(v)
+		}
+	}
+
+	c := *t
+	c.Command = &cmd
+	return &c, errors.Join(errs...)
+}
+
+// Dir is the working directory of the command; a service command's dir is
+// relative to the service
+func (t *CommandTask) Dir() string {
+	if t.Service == nil || filepath.IsAbs(t.Command.Dir) {
+		return t.Command.Dir
+	}
+	if t.Command.Dir == "" {
+		return t.Service.Dir
+	}
+	return filepath.Join(t.Service.Dir, t.Command.Dir)
+}
+
+// Env returns the command's env entries followed by its inputs, exposed as
+// CLEAT_INPUT_<KEY> variables
+func (t *CommandTask) Env(sess *session.Session) []string {
+	var env []string
+	keys := make([]string, 0, len(t.Command.Env))
+	for k := range t.Command.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+t.Command.Env[k])
+	}
+	for _, in := range t.Command.Inputs {
+		if val, ok := sess.Inputs[in.Key]; ok {
+			env = append(env, InputEnvVar(in.Key)+"="+val)
+		}
+	}
+	return env
+}
+
+func (t *CommandTask) Requirements(sess *session.Session) []InputRequirement {
+	var reqs []InputRequirement
+	for _, in := range t.Command.Inputs {
+		req := InputRequirement{
+			Key:     in.Key,
+			Prompt:  in.Prompt,
+			Default: in.Default,
+			Choices: in.Choices,
+			Pattern: in.Pattern,
+		}
+		if req.Prompt == "" {
+			req.Prompt = in.Key
+		}
+		switch in.Type {
+		case "choice":
+			req.Kind = InputChoice
+		case "confirm":
+			req.Kind = InputConfirm
+		case "secret":
+			req.Kind = InputSecret
+		}
+		reqs = append(reqs, req)
+	}
+
+	// Inputs the command refers to without declaring them are asked for as text
+	declared := make(map[string]bool)
+	for _, in := range t.Command.Inputs {
+		declared[in.Key] = true
+	}
+	values := append([]string{t.Command.Run.Shell, t.Command.Dir}, t.Command.Run.Args...)
+	for _, v := range t.Command.Env {
+		values = append(values, v)
+	}
+	for _, v := range values {
+		for _, key := range config.InputRefs(v) {
+			if !declared[key] {
+				declared[key] = true
+				reqs = append(reqs, InputRequirement{Key: key, Prompt: key})
+			}
+		}
+	}
+	return reqs
+}
+
+// commandArgs returns the argv of cmd, wrapping a run string in the shell
+func commandArgs(cmd *config.CommandConfig) []string {
+	if len(cmd.Run.Args) > 0 {
+		return cmd.Run.Args
+	}
+	return shellCommand(cmd.Run.Shell)
+}
@@ -0,0 +1,180 @@
+package task
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/config/schema"
+	"github.com/madewithfuture/cleat/internal/session"
+)
+
+type envRecordingExecutor struct {
+	plainPrompter
+	dir  string
+	env  []string
+	args []string
+}
+
+func (e *envRecordingExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
+	return e.RunWithEnv(ctx, dir, nil, name, args...)
+}
+
+func (e *envRecordingExecutor) RunWithEnv(ctx context.Context, dir string, env []string, name string, args ...string) error {
+	e.dir = dir
+	e.env = env
+	e.args = append([]string{name}, args...)
+	return nil
+}
+
+func TestCommandTask(t *testing.T) {
+	svc := config.ServiceConfig{
+		Name: "web",
+		Dir:  "./web",
+		Commands: []config.CommandConfig{
+			{Name: "seed", Run: schema.CommandRun{Args: []string{"./seed", "--fast"}}, Dir: "scripts", DependsOn: []string{"migrate", "setup"}},
+			{Name: "migrate", Run: schema.CommandRun{Shell: "make migrate"}},
+		},
+	}
+	cfg := &config.Config{
+		Services: []config.ServiceConfig{svc},
+		Commands: []config.CommandConfig{
+			{
+				Name:        "setup",
+				Description: "Prepare the workspace",
+				Run:         schema.CommandRun{Shell: "echo $GREETING"},
+				Env:         map[string]string{"GREETING": "hi", "A": "1"},
+				Inputs: []config.CommandInput{
+					{Key: "token", Type: "secret"},
+					{Key: "target", Prompt: "Target", Type: "choice", Choices: []string{"a", "b"}},
+				},
+			},
+		},
+	}
+	websvc := &cfg.Services[0]
+
+	seed := NewCommandTask(cfg, websvc, &websvc.Commands[0])
+	if seed.Name() != "command:web:seed" {
+		t.Errorf("unexpected name %q", seed.Name())
+	}
+	if want := []string{"command:web:migrate", "command:setup"}; !reflect.DeepEqual(seed.Dependencies(), want) {
+		t.Errorf("expected dependencies %v, got %v", want, seed.Dependencies())
+	}
+	if seed.Description() != "Run command: ./seed --fast" {
+		t.Errorf("unexpected description %q", seed.Description())
+	}
+
+	t.Run("ArgvInServiceDir", func(t *testing.T) {
+		exec := &envRecordingExecutor{}
+		sess := session.NewSession(cfg, exec)
+		if err := seed.Run(sess); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if exec.dir != "web/scripts" {
+			t.Errorf("expected dir web/scripts, got %q", exec.dir)
+		}
+		if !reflect.DeepEqual(exec.args, []string{"./seed", "--fast"}) {
+			t.Errorf("unexpected args %v", exec.args)
+		}
+		if exec.env != nil {
+			t.Errorf("expected no env, got %v", exec.env)
+		}
+	})
+
+	t.Run("ShellWithEnvAndInputs", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("shell wrapping differs on windows")
+		}
+		setup := NewCommandTask(cfg, nil, &cfg.Commands[0])
+		exec := &envRecordingExecutor{}
+		sess := session.NewSession(cfg, exec)
+		sess.Inputs["token"] = "s3cret"
+		sess.Inputs["target"] = "b"
+		if err := setup.Run(sess); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if !reflect.DeepEqual(exec.args, []string{"sh", "-c", "echo $GREETING"}) {
+			t.Errorf("unexpected args %v", exec.args)
+		}
+		want := []string{"A=1", "GREETING=hi", "CLEAT_INPUT_TOKEN=s3cret", "CLEAT_INPUT_TARGET=b"}
+		if !reflect.DeepEqual(exec.env, want) {
+			t.Errorf("expected env %v, got %v", want, exec.env)
+		}
+	})
+
+	t.Run("EnvFallbackWithoutEnvRunner", func(t *testing.T) {
+		setup := NewCommandTask(cfg, nil, &cfg.Commands[0])
+		rec := &recordingRunner{plainPrompter: &plainPrompter{}}
+		sess := session.NewSession(cfg, rec)
+		if err := setup.Run(sess); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(rec.args) < 3 || rec.args[0] != "env" || rec.args[1] != "A=1" || rec.args[2] != "GREETING=hi" {
+			t.Errorf("expected command wrapped in env, got %v", rec.args)
+		}
+	})
+
+	t.Run("Requirements", func(t *testing.T) {
+		setup := NewCommandTask(cfg, nil, &cfg.Commands[0])
+		reqs := setup.Requirements(session.NewSession(cfg, nil))
+		if len(reqs) != 2 {
+			t.Fatalf("expected 2 requirements, got %d", len(reqs))
+		}
+		if reqs[0].Kind != InputSecret || reqs[0].Prompt != "token" {
+			t.Errorf("unexpected secret requirement %+v", reqs[0])
+		}
+		if reqs[1].Kind != InputChoice || len(reqs[1].Choices) != 2 {
+			t.Errorf("unexpected choice requirement %+v", reqs[1])
+		}
+	})
+
+	t.Run("Interpolation", func(t *testing.T) {
+		t.Setenv("CLEAT_TEST_REGION", "europe-west1")
+		cfg := &config.Config{GoogleCloudPlatform: &config.GCPConfig{ProjectName: "acme"}}
+		deploy := &config.CommandConfig{
+			Name: "deploy",
+			Run:  schema.CommandRun{Args: []string{"deploy", "${config:google_cloud_platform.project_name}", "--tag=${input:tag}"}},
+			Dir:  "${env:CLEAT_TEST_DIR:-infra}",
+			Env:  map[string]string{"REGION": "${env:CLEAT_TEST_REGION}"},
+		}
+		task := NewCommandTask(cfg, nil, deploy)
+
+		reqs := task.Requirements(session.NewSession(cfg, nil))
+		if len(reqs) != 1 || reqs[0].Key != "tag" || reqs[0].Kind != InputText {
+			t.Errorf("expected the referenced input as a requirement, got %+v", reqs)
+		}
+
+		exec := &envRecordingExecutor{}
+		sess := session.NewSession(cfg, exec)
+		if err := task.Run(sess); err == nil || !strings.Contains(err.Error(), "input 'tag' has no value") {
+			t.Errorf("expected an error for the missing input, got %v", err)
+		}
+
+		sess.Inputs["tag"] = "v2"
+		if err := task.Run(sess); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if !reflect.DeepEqual(exec.args, []string{"deploy", "acme", "--tag=v2"}) {
+			t.Errorf("unexpected args %v", exec.args)
+		}
+		if exec.dir != "infra" || !reflect.DeepEqual(exec.env, []string{"REGION=europe-west1"}) {
+			t.Errorf("unexpected dir %q and env %v", exec.dir, exec.env)
+		}
+		if deploy.Dir != "${env:CLEAT_TEST_DIR:-infra}" {
+			t.Errorf("expected the configured command to be left as it is, got dir %q", deploy.Dir)
+		}
+	})
+}
+
+type recordingRunner struct {
+	*plainPrompter
+	args []string
+}
+
+func (r *recordingRunner) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
+	r.args = append([]string{name}, args...)
+	return nil
+}
@@ -12,6 +12,8 @@ type DjangoRunServer struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DjangoRunServer) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDjangoRunServer(svc *config.ServiceConfig) *DjangoRunServer {
 	return &DjangoRunServer{
 		BaseTask: BaseTask{
@@ -45,7 +47,7 @@ func (t *DjangoRunServer) Run(sess *session.Session) error {
 	if sess.Config.Docker && t.Service.IsDocker() {
 		dir = "" // Run from root when using docker compose
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("django server failed for service %s: %w", t.Service.Name, err)
 	}
 	return nil
@@ -54,7 +56,7 @@ func (t *DjangoRunServer) Run(sess *session.Session) error {
 func (t *DjangoRunServer) Commands(sess *session.Session) [][]string {
 	pyConfig := getPythonConfig(t.Service)
 	if sess.Config.Docker && t.Service.IsDocker() {
-		cmd := []string{"docker", "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
+		cmd := []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
 		cmd = append(cmd, pythonCommand(pyConfig)...)
 		cmd = append(cmd, "manage.py", "runserver", "0.0.0.0:8000")
 		return [][]string{cmd}
@@ -71,6 +73,8 @@ type DjangoMigrate struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DjangoMigrate) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDjangoMigrate(svc *config.ServiceConfig) *DjangoMigrate {
 	return &DjangoMigrate{
 		BaseTask: BaseTask{
@@ -104,7 +108,7 @@ func (t *DjangoMigrate) Run(sess *session.Session) error {
 	if sess.Config.Docker && t.Service.IsDocker() {
 		dir = ""
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("django migrations failed for service %s: %w", t.Service.Name, err)
 	}
 	return nil
@@ -113,7 +117,7 @@ func (t *DjangoMigrate) Run(sess *session.Session) error {
 func (t *DjangoMigrate) Commands(sess *session.Session) [][]string {
 	pyConfig := getPythonConfig(t.Service)
 	if sess.Config.Docker && t.Service.IsDocker() {
-		cmd := []string{"docker", "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
+		cmd := []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
 		cmd = append(cmd, pythonCommand(pyConfig)...)
 		cmd = append(cmd, "manage.py", "migrate", "--noinput")
 		return [][]string{cmd}
@@ -130,6 +134,8 @@ type DjangoMakeMigrations struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DjangoMakeMigrations) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDjangoMakeMigrations(svc *config.ServiceConfig) *DjangoMakeMigrations {
 	return &DjangoMakeMigrations{
 		BaseTask: BaseTask{
@@ -163,7 +169,7 @@ func (t *DjangoMakeMigrations) Run(sess *session.Session) error {
 	if sess.Config.Docker && t.Service.IsDocker() {
 		dir = ""
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("django makemigrations failed for service %s: %w", t.Service.Name, err)
 	}
 	return nil
@@ -172,7 +178,7 @@ func (t *DjangoMakeMigrations) Run(sess *session.Session) error {
 func (t *DjangoMakeMigrations) Commands(sess *session.Session) [][]string {
 	pyConfig := getPythonConfig(t.Service)
 	if sess.Config.Docker && t.Service.IsDocker() {
-		cmd := []string{"docker", "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
+		cmd := []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
 		cmd = append(cmd, pythonCommand(pyConfig)...)
 		cmd = append(cmd, "manage.py", "makemigrations")
 		return [][]string{cmd}
@@ -189,6 +195,8 @@ type DjangoCollectStatic struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DjangoCollectStatic) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDjangoCollectStatic(svc *config.ServiceConfig) *DjangoCollectStatic {
 	return &DjangoCollectStatic{
 		BaseTask: BaseTask{
@@ -222,7 +230,7 @@ func (t *DjangoCollectStatic) Run(sess *session.Session) error {
 	if sess.Config.Docker && t.Service.IsDocker() {
 		dir = ""
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("django collectstatic failed for service %s: %w", t.Service.Name, err)
 	}
 	return nil
@@ -231,7 +239,7 @@ func (t *DjangoCollectStatic) Run(sess *session.Session) error {
 func (t *DjangoCollectStatic) Commands(sess *session.Session) [][]string {
 	pyConfig := getPythonConfig(t.Service)
 	if sess.Config.Docker && t.Service.IsDocker() {
-		cmd := []string{"docker", "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
+		cmd := []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
 		cmd = append(cmd, pythonCommand(pyConfig)...)
 		cmd = append(cmd, "manage.py", "collectstatic", "--noinput", "--clear")
 		return [][]string{cmd}
@@ -248,6 +256,8 @@ type DjangoCreateUserDev struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DjangoCreateUserDev) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDjangoCreateUserDev(svc *config.ServiceConfig) *DjangoCreateUserDev {
 	return &DjangoCreateUserDev{
 		BaseTask: BaseTask{
@@ -281,7 +291,7 @@ func (t *DjangoCreateUserDev) Run(sess *session.Session) error {
 	if sess.Config.Docker && t.Service.IsDocker() {
 		dir = ""
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("django create-user-dev failed for service %s: %w", t.Service.Name, err)
 	}
 	return nil
@@ -291,7 +301,7 @@ func (t *DjangoCreateUserDev) Commands(sess *session.Session) [][]string {
 	pyConfig := getPythonConfig(t.Service)
 	if sess.Config.Docker && t.Service.IsDocker() {
 		cmd := []string{
-			"docker", "--log-level", "error", "compose", "run",
+			containerRuntime(), "--log-level", "error", "compose", "run",
 			"-e", "DJANGO_SUPERUSER_USERNAME=dev",
 			"-e", "DJANGO_SUPERUSER_PASSWORD=dev",
 			"--rm",
@@ -313,6 +323,8 @@ type DjangoGenRandomSecretKey struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DjangoGenRandomSecretKey) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDjangoGenRandomSecretKey(svc *config.ServiceConfig) *DjangoGenRandomSecretKey {
 	return &DjangoGenRandomSecretKey{
 		BaseTask: BaseTask{
@@ -346,7 +358,7 @@ func (t *DjangoGenRandomSecretKey) Run(sess *session.Session) error {
 	if sess.Config.Docker && t.Service.IsDocker() {
 		dir = ""
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("django gen-random-secret-key failed for service %s: %w", t.Service.Name, err)
 	}
 	return nil
@@ -357,7 +369,7 @@ func (t *DjangoGenRandomSecretKey) Commands(sess *session.Session) [][]string {
 	pyConfig := getPythonConfig(t.Service)
 
 	if sess.Config.Docker && t.Service.IsDocker() {
-		cmd := []string{"docker", "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
+		cmd := []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
 		cmd = append(cmd, pythonCommand(pyConfig)...)
 		cmd = append(cmd, "-c", pyCmd)
 		return [][]string{cmd}
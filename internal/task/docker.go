@@ -5,6 +5,7 @@ import (
 
 	"github.com/madewithfuture/cleat/internal/config"
 	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/settings"
 )
 
 // DockerBuild builds Docker containers
@@ -13,6 +14,8 @@ type DockerBuild struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DockerBuild) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDockerBuild(svc *config.ServiceConfig) *DockerBuild {
 	name := "docker:build"
 	if svc != nil {
@@ -32,6 +35,9 @@ func (t *DockerBuild) ShouldRun(sess *session.Session) bool {
 	if t.Service != nil {
 		return t.Service.IsDocker()
 	}
+	if _, ok := composeServices(sess); !ok {
+		return false
+	}
 	return sess.Config.Docker
 }
 
@@ -52,19 +58,21 @@ func (t *DockerBuild) Run(sess *session.Session) error {
 	if t.Service != nil {
 		dir = t.Service.Dir
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("docker build failed: %w", err)
 	}
 	return nil
 }
 
 func (t *DockerBuild) Commands(sess *session.Session) [][]string {
-	cmdName := "docker"
+	cmdName := containerRuntime()
 	args := []string{"--log-level", "error", "compose"}
 	if t.Service == nil {
 		args = append(args, "--profile", "*")
 	}
 	args = append(args, "build")
+	names, _ := composeServices(sess)
+	args = append(args, names...)
 
 	// 1Password integration
 	searchDir := "."
@@ -72,7 +80,7 @@ func (t *DockerBuild) Commands(sess *session.Session) [][]string {
 		searchDir = t.Service.Dir
 	}
 	if execPath, absPath, _ := DetectEnvFile(searchDir); execPath != "" && FileUsesOp(absPath) {
-		args = append([]string{"run", "--env-file", execPath, "--", "docker"}, args...)
+		args = append([]string{"run", "--env-file", execPath, "--", containerRuntime()}, args...)
 		cmdName = "op"
 	}
 
@@ -85,6 +93,8 @@ type DockerUp struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DockerUp) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDockerUp(svc *config.ServiceConfig) *DockerUp {
 	name := "docker:up"
 	if svc != nil {
@@ -104,6 +114,9 @@ func (t *DockerUp) ShouldRun(sess *session.Session) bool {
 	if t.Service != nil {
 		return t.Service.IsDocker()
 	}
+	if _, ok := composeServices(sess); !ok {
+		return false
+	}
 	return sess.Config.Docker
 }
 
@@ -124,20 +137,23 @@ func (t *DockerUp) Run(sess *session.Session) error {
 	if t.Service != nil {
 		dir = t.Service.Dir
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("docker up failed: %w", err)
 	}
 	return nil
 }
 
 func (t *DockerUp) Commands(sess *session.Session) [][]string {
-	cmdName := "docker"
+	cmdName := containerRuntime()
 	args := []string{"--log-level", "error", "compose"}
 	args = append(args, "up", "--remove-orphans")
 
 	// Add service name if specific service is targeted
 	if t.Service != nil {
 		args = append(args, t.Service.Name)
+	} else {
+		names, _ := composeServices(sess)
+		args = append(args, names...)
 	}
 
 	// 1Password integration
@@ -146,7 +162,7 @@ func (t *DockerUp) Commands(sess *session.Session) [][]string {
 		searchDir = t.Service.Dir
 	}
 	if execPath, absPath, _ := DetectEnvFile(searchDir); execPath != "" && FileUsesOp(absPath) {
-		args = append([]string{"run", "--env-file", execPath, "--", "docker"}, args...)
+		args = append([]string{"run", "--env-file", execPath, "--", containerRuntime()}, args...)
 		cmdName = "op"
 	}
 
@@ -159,6 +175,8 @@ type DockerDown struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DockerDown) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDockerDown(svc *config.ServiceConfig) *DockerDown {
 	name := "docker:down"
 	if svc != nil {
@@ -178,6 +196,9 @@ func (t *DockerDown) ShouldRun(sess *session.Session) bool {
 	if t.Service != nil {
 		return t.Service.IsDocker()
 	}
+	if _, ok := composeServices(sess); !ok {
+		return false
+	}
 	return sess.Config.Docker
 }
 
@@ -198,16 +219,18 @@ func (t *DockerDown) Run(sess *session.Session) error {
 	if t.Service != nil {
 		dir = t.Service.Dir
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("docker down failed: %w", err)
 	}
 	return nil
 }
 
 func (t *DockerDown) Commands(sess *session.Session) [][]string {
-	cmdName := "docker"
+	cmdName := containerRuntime()
 	args := []string{"compose"}
 	args = append(args, "--profile", "*", "down", "--remove-orphans")
+	names, _ := composeServices(sess)
+	args = append(args, names...)
 
 	// 1Password integration
 	searchDir := "."
@@ -215,7 +238,7 @@ func (t *DockerDown) Commands(sess *session.Session) [][]string {
 		searchDir = t.Service.Dir
 	}
 	if execPath, absPath, _ := DetectEnvFile(searchDir); execPath != "" && FileUsesOp(absPath) {
-		args = append([]string{"run", "--env-file", execPath, "--", "docker"}, args...)
+		args = append([]string{"run", "--env-file", execPath, "--", containerRuntime()}, args...)
 		cmdName = "op"
 	}
 
@@ -228,6 +251,8 @@ type DockerRebuild struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DockerRebuild) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDockerRebuild(svc *config.ServiceConfig) *DockerRebuild {
 	name := "docker:rebuild"
 	if svc != nil {
@@ -247,6 +272,9 @@ func (t *DockerRebuild) ShouldRun(sess *session.Session) bool {
 	if t.Service != nil {
 		return t.Service.IsDocker()
 	}
+	if _, ok := composeServices(sess); !ok {
+		return false
+	}
 	return sess.Config.Docker
 }
 
@@ -270,13 +298,13 @@ func (t *DockerRebuild) Run(sess *session.Session) error {
 
 	// 1. Down with --rmi all --volumes
 	PrintSubStep("Cleaning up: stopping containers and removing images/volumes")
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("docker cleanup failed during rebuild: %w", err)
 	}
 
 	// 2. Build with --no-cache
 	PrintSubStep("Rebuilding: build without cache")
-	if err := sess.Exec.RunWithDir(dir, cmds[1][0], cmds[1][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[1][0], cmds[1][1:]...); err != nil {
 		return fmt.Errorf("docker rebuild failed: %w", err)
 	}
 	return nil
@@ -284,14 +312,17 @@ func (t *DockerRebuild) Run(sess *session.Session) error {
 
 func (t *DockerRebuild) Commands(sess *session.Session) [][]string {
 	// 1. Down
-	downCmd := "docker"
+	downCmd := containerRuntime()
 	downArgs := []string{"compose"}
 	downArgs = append(downArgs, "--profile", "*", "down", "--remove-orphans", "--rmi", "all", "--volumes")
+	names, _ := composeServices(sess)
+	downArgs = append(downArgs, names...)
 
 	// 2. Build
-	buildCmd := "docker"
+	buildCmd := containerRuntime()
 	buildArgs := []string{"--log-level", "error", "compose"}
 	buildArgs = append(buildArgs, "--profile", "*", "build", "--no-cache")
+	buildArgs = append(buildArgs, names...)
 
 	// 1Password integration
 	searchDir := "."
@@ -300,10 +331,10 @@ func (t *DockerRebuild) Commands(sess *session.Session) [][]string {
 	}
 
 	if execPath, absPath, _ := DetectEnvFile(searchDir); execPath != "" && FileUsesOp(absPath) {
-		downArgs = append([]string{"run", "--env-file", execPath, "--", "docker"}, downArgs...)
+		downArgs = append([]string{"run", "--env-file", execPath, "--", containerRuntime()}, downArgs...)
 		downCmd = "op"
 
-		buildArgs = append([]string{"run", "--env-file", execPath, "--", "docker"}, buildArgs...)
+		buildArgs = append([]string{"run", "--env-file", execPath, "--", containerRuntime()}, buildArgs...)
 		buildCmd = "op"
 	}
 
@@ -319,6 +350,8 @@ type DockerRemoveOrphans struct {
 	Service *config.ServiceConfig
 }
 
+func (t *DockerRemoveOrphans) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewDockerRemoveOrphans(svc *config.ServiceConfig) *DockerRemoveOrphans {
 	name := "docker:remove-orphans"
 	if svc != nil {
@@ -338,6 +371,9 @@ func (t *DockerRemoveOrphans) ShouldRun(sess *session.Session) bool {
 	if t.Service != nil {
 		return t.Service.IsDocker()
 	}
+	if _, ok := composeServices(sess); !ok {
+		return false
+	}
 	return sess.Config.Docker
 }
 
@@ -358,14 +394,14 @@ func (t *DockerRemoveOrphans) Run(sess *session.Session) error {
 	if t.Service != nil {
 		dir = t.Service.Dir
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("docker remove-orphans failed: %w", err)
 	}
 	return nil
 }
 
 func (t *DockerRemoveOrphans) Commands(sess *session.Session) [][]string {
-	cmdName := "docker"
+	cmdName := containerRuntime()
 	args := []string{"compose"}
 	args = append(args, "--profile", "*", "down", "--remove-orphans")
 
@@ -375,9 +411,30 @@ func (t *DockerRemoveOrphans) Commands(sess *session.Session) [][]string {
 		searchDir = t.Service.Dir
 	}
 	if execPath, absPath, _ := DetectEnvFile(searchDir); execPath != "" && FileUsesOp(absPath) {
-		args = append([]string{"run", "--env-file", execPath, "--", "docker"}, args...)
+		args = append([]string{"run", "--env-file", execPath, "--", containerRuntime()}, args...)
 		cmdName = "op"
 	}
 
 	return [][]string{append([]string{cmdName}, args...)}
 }
+
+// containerRuntime returns the program that runs compose commands: docker,
+// or podman when the user's settings choose it
+func containerRuntime() string {
+	return settings.Current().ContainerRuntime
+}
+
+// composeServices returns the compose services that project-wide docker
+// commands are limited to when --service or --exclude-service is used. ok is
+// false when the filter left no Docker service to run.
+func composeServices(sess *session.Session) (names []string, ok bool) {
+	if sess.Services == nil {
+		return nil, true
+	}
+	for _, svc := range sess.Config.Services {
+		if svc.IsDocker() {
+			names = append(names, svc.Name)
+		}
+	}
+	return names, len(names) > 0
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/madewithfuture/cleat/internal/config"
 	"github.com/madewithfuture/cleat/internal/executor"
 	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/settings"
 )
 
 // TestDockerCommandsUseCompose ensures all docker commands use "docker compose" not raw "docker"
@@ -121,6 +122,34 @@ func TestDockerCommandsUseCompose(t *testing.T) {
 	}
 }
 
+func TestContainerRuntimeSetting(t *testing.T) {
+	s := settings.Default()
+	s.ContainerRuntime = "podman"
+	settings.Set(s)
+	defer settings.Set(nil)
+
+	svc := config.ServiceConfig{Name: "web", Docker: ptrBool(true), Modules: []config.ModuleConfig{{Npm: &config.NpmConfig{Service: "web"}}}}
+	cfg := &config.Config{Docker: true, Services: []config.ServiceConfig{svc}}
+	sess := session.NewSession(cfg, &executor.ShellExecutor{})
+
+	tests := []struct {
+		name string
+		task Task
+		want string
+	}{
+		{"DockerBuild", NewDockerBuild(nil), "podman --log-level error compose --profile * build"},
+		{"DockerDown", NewDockerDown(nil), "podman compose --profile * down --remove-orphans"},
+		{"NpmRun", NewNpmRun(&cfg.Services[0], cfg.Services[0].Modules[0].Npm, "build"), "podman --log-level error compose run --rm web npm run build"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(tt.task.Commands(sess)[0], " "); got != tt.want {
+				t.Errorf("Commands() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestDockerCommandsNeverUseRawDocker ensures we never use raw docker commands like "docker ps", "docker images", etc.
 func TestDockerCommandsNeverUseRawDocker(t *testing.T) {
 	cfg := &config.Config{
@@ -266,3 +295,37 @@ func TestDockerComposeSubcommands(t *testing.T) {
 		})
 	}
 }
+
+// TestDockerCommandsLimitedToSelectedServices verifies --service narrows project-wide compose commands
+func TestDockerCommandsLimitedToSelectedServices(t *testing.T) {
+	cfg := &config.Config{
+		Docker: true,
+		Services: []config.ServiceConfig{
+			{Name: "web", Docker: ptrBool(true)},
+			{Name: "api", Docker: ptrBool(true)},
+			{Name: "cli", Docker: ptrBool(false)},
+		},
+	}
+	sess := session.NewSession(cfg, &executor.ShellExecutor{})
+	sess.Services = []string{"web", "api", "cli"}
+
+	tests := []struct {
+		task Task
+		want string
+	}{
+		{NewDockerBuild(nil), "docker --log-level error compose --profile * build web api"},
+		{NewDockerUp(nil), "docker --log-level error compose up --remove-orphans web api"},
+		{NewDockerDown(nil), "docker compose --profile * down --remove-orphans web api"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.task.Commands(sess)[0], " "); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+
+	sess.Config = &config.Config{Docker: true, Services: []config.ServiceConfig{{Name: "cli", Docker: ptrBool(false)}}}
+	sess.Services = []string{"cli"}
+	if NewDockerBuild(nil).ShouldRun(sess) {
+		t.Error("expected project-wide docker build to be skipped when no Docker service is selected")
+	}
+}
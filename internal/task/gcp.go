@@ -2,10 +2,23 @@ package task
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/madewithfuture/cleat/internal/session"
 )
 
+// appEngineVersionChars are the characters App Engine allows in version ids
+const appEngineVersionChars = "[a-z0-9-]"
+
+// validateEmail is a light sanity check for account and service account inputs
+func validateEmail(value string) error {
+	local, domain, ok := strings.Cut(value, "@")
+	if !ok || local == "" || !strings.Contains(domain, ".") {
+		return fmt.Errorf("'%s' is not an email address", value)
+	}
+	return nil
+}
+
 type GCPCreateConfig struct {
 	BaseTask
 }
@@ -27,7 +40,7 @@ func (t *GCPCreateConfig) Run(sess *session.Session) error {
 	PrintStep(fmt.Sprintf("Creating GCP project configuration %s", sess.Config.GoogleCloudPlatform.ProjectName))
 	cmds := t.Commands(sess)
 	for _, cmd := range cmds {
-		if err := sess.Exec.Run(cmd[0], cmd[1:]...); err != nil {
+		if err := sess.Exec.Run(sess.Context(), cmd[0], cmd[1:]...); err != nil {
 			return fmt.Errorf("gcp create-config failed: %w", err)
 		}
 	}
@@ -64,7 +77,7 @@ func (t *GCPInit) Run(sess *session.Session) error {
 	PrintStep("Initializing Google Cloud SDK")
 	cmds := t.Commands(sess)
 	for _, cmd := range cmds {
-		if err := sess.Exec.Run(cmd[0], cmd[1:]...); err != nil {
+		if err := sess.Exec.Run(sess.Context(), cmd[0], cmd[1:]...); err != nil {
 			return fmt.Errorf("gcp init failed: %w", err)
 		}
 	}
@@ -104,7 +117,7 @@ func (t *GCPActivate) Run(sess *session.Session) error {
 	PrintStep(fmt.Sprintf("Activating project %s", sess.Config.GoogleCloudPlatform.ProjectName))
 	cmds := t.Commands(sess)
 	for _, cmd := range cmds {
-		if err := sess.Exec.Run(cmd[0], cmd[1:]...); err != nil {
+		if err := sess.Exec.Run(sess.Context(), cmd[0], cmd[1:]...); err != nil {
 			return fmt.Errorf("gcp activate failed: %w", err)
 		}
 	}
@@ -146,8 +159,9 @@ func (t *GCPSetConfig) Requirements(sess *session.Session) []InputRequirement {
 	if sess.Config.GoogleCloudPlatform != nil && sess.Config.GoogleCloudPlatform.Account == "" {
 		if _, ok := sess.Inputs["gcp:account"]; !ok {
 			reqs = append(reqs, InputRequirement{
-				Key:    "gcp:account",
-				Prompt: "Enter GCP account email",
+				Key:      "gcp:account",
+				Prompt:   "Enter GCP account email",
+				Validate: validateEmail,
 			})
 		}
 	}
@@ -158,7 +172,7 @@ func (t *GCPSetConfig) Run(sess *session.Session) error {
 	PrintStep("Setting GCP project configuration")
 	cmds := t.Commands(sess)
 	for _, cmd := range cmds {
-		if err := sess.Exec.Run(cmd[0], cmd[1:]...); err != nil {
+		if err := sess.Exec.Run(sess.Context(), cmd[0], cmd[1:]...); err != nil {
 			return fmt.Errorf("gcp set-config failed: %w", err)
 		}
 	}
@@ -207,7 +221,7 @@ func (t *GCPADCLogin) Run(sess *session.Session) error {
 	PrintStep("Logging in to GCP")
 	cmds := t.Commands(sess)
 	for _, cmd := range cmds {
-		if err := sess.Exec.Run(cmd[0], cmd[1:]...); err != nil {
+		if err := sess.Exec.Run(sess.Context(), cmd[0], cmd[1:]...); err != nil {
 			return fmt.Errorf("gcp adc-login failed: %w", err)
 		}
 	}
@@ -249,8 +263,9 @@ func (t *GCPAdcImpersonateLogin) Requirements(sess *session.Session) []InputRequ
 	if sess.Config.GoogleCloudPlatform != nil && sess.Config.GoogleCloudPlatform.ImpersonateServiceAccount == "" {
 		if _, ok := sess.Inputs["gcp:impersonate-service-account"]; !ok {
 			reqs = append(reqs, InputRequirement{
-				Key:    "gcp:impersonate-service-account",
-				Prompt: "Enter service account to impersonate",
+				Key:      "gcp:impersonate-service-account",
+				Prompt:   "Enter service account to impersonate",
+				Validate: validateEmail,
 			})
 		}
 	}
@@ -265,7 +280,7 @@ func (t *GCPAdcImpersonateLogin) Run(sess *session.Session) error {
 	PrintStep(fmt.Sprintf("Logging in with impersonation: %s", sa))
 	cmds := t.Commands(sess)
 	for _, cmd := range cmds {
-		if err := sess.Exec.Run(cmd[0], cmd[1:]...); err != nil {
+		if err := sess.Exec.Run(sess.Context(), cmd[0], cmd[1:]...); err != nil {
 			return fmt.Errorf("gcp adc-impersonate-login failed: %w", err)
 		}
 	}
@@ -312,7 +327,7 @@ func (t *GCPConsole) ShouldRun(sess *session.Session) bool {
 func (t *GCPConsole) Run(sess *session.Session) error {
 	PrintStep("Opening Google Cloud Console")
 	cmds := t.Commands(sess)
-	if err := sess.Exec.Run(cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.Run(sess.Context(), cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("failed to open gcp console: %w", err)
 	}
 	return nil
@@ -348,8 +363,9 @@ func (t *GCPAppEngineDeploy) ShouldRun(sess *session.Session) bool {
 func (t *GCPAppEngineDeploy) Requirements(sess *session.Session) []InputRequirement {
 	return []InputRequirement{
 		{
-			Key:    "gcp:version",
-			Prompt: "Enter version name, or return to skip",
+			Key:     "gcp:version",
+			Prompt:  "Enter version name, or return to skip",
+			Pattern: appEngineVersionChars + "*",
 		},
 	}
 }
@@ -361,7 +377,7 @@ func (t *GCPAppEngineDeploy) Run(sess *session.Session) error {
 		PrintSubStep(fmt.Sprintf("Version: %s", version))
 	}
 	cmds := t.Commands(sess)
-	if err := sess.Exec.Run(cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.Run(sess.Context(), cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("gcp app-engine deploy failed: %w", err)
 	}
 	return nil
@@ -404,8 +420,9 @@ func (t *GCPAppEnginePromote) ShouldRun(sess *session.Session) bool {
 func (t *GCPAppEnginePromote) Requirements(sess *session.Session) []InputRequirement {
 	return []InputRequirement{
 		{
-			Key:    "gcp:promote_version",
-			Prompt: "Enter version to promote",
+			Key:     "gcp:promote_version",
+			Prompt:  "Enter version to promote",
+			Pattern: appEngineVersionChars + "+",
 		},
 	}
 }
@@ -417,7 +434,7 @@ func (t *GCPAppEnginePromote) Run(sess *session.Session) error {
 	}
 	PrintStep(fmt.Sprintf("Promoting App Engine version %s", version))
 	cmds := t.Commands(sess)
-	if err := sess.Exec.Run(cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.Run(sess.Context(), cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("gcp app-engine promote failed for version %s: %w", version, err)
 	}
 	return nil
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"testing"
 
 	"github.com/madewithfuture/cleat/internal/config"
@@ -13,12 +14,12 @@ type mockExecutor struct {
 	commands [][]string
 }
 
-func (m *mockExecutor) Run(name string, args ...string) error {
+func (m *mockExecutor) Run(ctx context.Context, name string, args ...string) error {
 	m.commands = append(m.commands, append([]string{name}, args...))
 	return nil
 }
 
-func (m *mockExecutor) RunWithDir(dir string, name string, args ...string) error {
+func (m *mockExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
 	m.commands = append(m.commands, append([]string{name}, args...))
 	return nil
 }
@@ -96,3 +97,29 @@ func TestGCPCreateConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestGCPRequirementValidation(t *testing.T) {
+	cfg := &config.Config{GoogleCloudPlatform: &config.GCPConfig{ProjectName: "test-project"}}
+	sess := session.NewSession(cfg, &mockExecutor{})
+
+	account := NewGCPSetConfig().Requirements(sess)[0]
+	if _, err := account.Parse("user@example.com"); err != nil {
+		t.Errorf("expected valid email to parse, got %v", err)
+	}
+	if _, err := account.Parse("not-an-email"); err == nil {
+		t.Error("expected invalid email to be rejected")
+	}
+
+	version := NewGCPAppEngineDeploy("app.yaml").Requirements(sess)[0]
+	if _, err := version.Parse(""); err != nil {
+		t.Errorf("expected empty version to be allowed, got %v", err)
+	}
+	if _, err := version.Parse("v1_Release"); err == nil {
+		t.Error("expected invalid version id to be rejected")
+	}
+
+	promote := NewGCPAppEnginePromote("").Requirements(sess)[0]
+	if _, err := promote.Parse(""); err == nil {
+		t.Error("expected empty promote version to be rejected")
+	}
+}
@@ -17,6 +17,8 @@ type GoAction struct {
 	Action  string
 }
 
+func (t *GoAction) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewGoAction(svc *config.ServiceConfig, g *config.GoConfig, action string) *GoAction {
 	return &GoAction{
 		BaseTask: BaseTask{
@@ -44,7 +46,7 @@ func (t *GoAction) Run(sess *session.Session) error {
 	if sess.Config.Docker && t.Service.IsDocker() && t.GoCfg.Service != "" {
 		dir = ""
 	}
-	if err := sess.Exec.RunWithDir(dir, cmd[0], cmd[1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmd[0], cmd[1:]...); err != nil {
 		return fmt.Errorf("go %s failed for service %s: %w", t.Action, t.Service.Name, err)
 	}
 	return nil
@@ -53,7 +55,7 @@ func (t *GoAction) Run(sess *session.Session) error {
 func (t *GoAction) commandArgs(sess *session.Session) []string {
 	args := t.argsForAction()
 	if sess.Config.Docker && t.Service.IsDocker() && t.GoCfg.Service != "" {
-		base := []string{"docker", "--log-level", "error", "compose", "run", "--rm", t.GoCfg.Service, "go"}
+		base := []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", t.GoCfg.Service, "go"}
 		return append(base, args...)
 	}
 	return append([]string{"go"}, args...)
@@ -79,6 +81,8 @@ func (t *GoAction) argsForAction() []string {
 		return []string{"vet", "./..."}
 	case "mod-tidy":
 		return []string{"mod", "tidy"}
+	case "mod-download":
+		return []string{"mod", "download"}
 	case "generate":
 		return []string{"generate", "./..."}
 	case "run":
@@ -94,6 +98,8 @@ type GoInstall struct {
 	GoCfg   *config.GoConfig
 }
 
+func (t *GoInstall) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewGoInstall(svc *config.ServiceConfig, g *config.GoConfig) *GoInstall {
 	return &GoInstall{
 		BaseTask: BaseTask{
@@ -148,13 +154,13 @@ func (t *GoInstall) Run(sess *session.Session) error {
 	// 1. Build locally
 	PrintSubStep(fmt.Sprintf("Building %s...", binName))
 	buildArgs := []string{"build", "-o", binName, "."}
-	if err := sess.Exec.RunWithDir(t.Service.Dir, "go", buildArgs...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), t.Service.Dir, "go", buildArgs...); err != nil {
 		return fmt.Errorf("go build failed: %w", err)
 	}
 
 	// 2. Ensure install path exists
 	PrintSubStep(fmt.Sprintf("Ensuring directory %s exists...", installPath))
-	if err := sess.Exec.Run("mkdir", "-p", installPath); err != nil {
+	if err := sess.Exec.Run(sess.Context(), "mkdir", "-p", installPath); err != nil {
 		return fmt.Errorf("failed to create install directory: %w", err)
 	}
 
@@ -162,7 +168,7 @@ func (t *GoInstall) Run(sess *session.Session) error {
 	srcPath := filepath.Join(t.Service.Dir, binName)
 	dstPath := filepath.Join(installPath, binName)
 	PrintSubStep(fmt.Sprintf("Copying %s to %s...", binName, dstPath))
-	if err := sess.Exec.Run("cp", srcPath, dstPath); err != nil {
+	if err := sess.Exec.Run(sess.Context(), "cp", srcPath, dstPath); err != nil {
 		return fmt.Errorf("failed to copy binary: %w", err)
 	}
 
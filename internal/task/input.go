@@ -0,0 +1,148 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/executor"
+)
+
+// InputKind selects how an input is asked for and validated
+type InputKind int
+
+const (
+	// InputText is a free-form string, optionally checked by Pattern or Validate
+	InputText InputKind = iota
+	// InputChoice must be one of Choices
+	InputChoice
+	// InputConfirm is a yes/no answer stored as "true" or "false"
+	InputConfirm
+	// InputSecret is masked while typed and never written to history. Tasks
+	// should keep reporting a secret requirement even once it is supplied so
+	// the key is still recognised as secret.
+	InputSecret
+)
+
+// InputRequirement represents a piece of information needed from the user
+type InputRequirement struct {
+	Key     string
+	Prompt  string
+	Default string
+	Kind    InputKind
+	// Choices lists the allowed values of an InputChoice
+	Choices []string
+	// Pattern is a regular expression the whole answer must match
+	Pattern string
+	// Validate is an extra check for text and secret answers
+	Validate func(value string) error
+}
+
+// IsSecret reports whether the value must be masked and kept out of history
+func (r InputRequirement) IsSecret() bool {
+	return r.Kind == InputSecret
+}
+
+// Parse validates an answer and returns its canonical form. Confirmations
+// accept y/yes/true/1 and n/no/false/0; choices accept a value or its
+// 1-based position in Choices.
+func (r InputRequirement) Parse(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch r.Kind {
+	case InputConfirm:
+		switch strings.ToLower(value) {
+		case "y", "yes", "true", "1":
+			return "true", nil
+		case "n", "no", "false", "0":
+			return "false", nil
+		}
+		return "", fmt.Errorf("%s: answer yes or no", r.Key)
+	case InputChoice:
+		for _, c := range r.Choices {
+			if value == c {
+				return c, nil
+			}
+		}
+		if n, err := strconv.Atoi(value); err == nil && n >= 1 && n <= len(r.Choices) {
+			return r.Choices[n-1], nil
+		}
+		return "", fmt.Errorf("%s: '%s' is not one of: %s", r.Key, value, strings.Join(r.Choices, ", "))
+	}
+
+	if r.Pattern != "" {
+		re, err := regexp.Compile("^(?:" + r.Pattern + ")$")
+		if err != nil {
+			return "", fmt.Errorf("%s: invalid pattern %q: %w", r.Key, r.Pattern, err)
+		}
+		if !re.MatchString(value) {
+			return "", fmt.Errorf("%s: value does not match %s", r.Key, r.Pattern)
+		}
+	}
+	if r.Validate != nil {
+		if err := r.Validate(value); err != nil {
+			return "", fmt.Errorf("%s: %w", r.Key, err)
+		}
+	}
+	return value, nil
+}
+
+// Spec converts the requirement into an executor prompt description
+func (r InputRequirement) Spec() executor.PromptSpec {
+	spec := executor.PromptSpec{
+		Message: r.Prompt,
+		Default: r.Default,
+		Secret:  r.IsSecret(),
+		Confirm: r.Kind == InputConfirm,
+		Parse:   r.Parse,
+	}
+	switch r.Kind {
+	case InputChoice:
+		spec.Choices = r.Choices
+	case InputConfirm:
+		if def, err := r.Parse(r.Default); err == nil {
+			spec.Default = def
+		}
+	}
+	return spec
+}
+
+// PromptFor asks exec for the requirement's value. Executors that implement
+// executor.TypedPrompter render the kind natively; others get a plain prompt
+// whose answer is validated once.
+func PromptFor(exec executor.Executor, req InputRequirement) (string, error) {
+	if tp, ok := exec.(executor.TypedPrompter); ok {
+		return tp.PromptTyped(req.Spec())
+	}
+
+	message := req.Prompt
+	switch req.Kind {
+	case InputChoice:
+		message = fmt.Sprintf("%s (%s)", message, strings.Join(req.Choices, "/"))
+	case InputConfirm:
+		message += " (y/n)"
+	}
+	val, err := exec.Prompt(message, req.Default)
+	if err != nil {
+		return "", err
+	}
+	return req.Parse(val)
+}
+
+// InputEnvPrefix prefixes environment variables that supply inputs non-interactively
+const InputEnvPrefix = "CLEAT_INPUT_"
+
+// InputEnvVar returns the environment variable that supplies the input key,
+// e.g. "gcp:account" is read from CLEAT_INPUT_GCP_ACCOUNT
+func InputEnvVar(key string) string {
+	var b strings.Builder
+	b.WriteString(InputEnvPrefix)
+	for _, r := range strings.ToUpper(key) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,147 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/executor"
+)
+
+func TestInputRequirement_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     InputRequirement
+		value   string
+		want    string
+		wantErr string
+	}{
+		{"TextPassesThrough", InputRequirement{Key: "k"}, " value ", "value", ""},
+		{"ConfirmYes", InputRequirement{Key: "k", Kind: InputConfirm}, "Y", "true", ""},
+		{"ConfirmNo", InputRequirement{Key: "k", Kind: InputConfirm}, "no", "false", ""},
+		{"ConfirmInvalid", InputRequirement{Key: "k", Kind: InputConfirm}, "maybe", "", "answer yes or no"},
+		{"ChoiceByValue", InputRequirement{Key: "k", Kind: InputChoice, Choices: []string{"dev", "prod"}}, "prod", "prod", ""},
+		{"ChoiceByIndex", InputRequirement{Key: "k", Kind: InputChoice, Choices: []string{"dev", "prod"}}, "1", "dev", ""},
+		{"ChoiceInvalid", InputRequirement{Key: "k", Kind: InputChoice, Choices: []string{"dev", "prod"}}, "staging", "", "not one of: dev, prod"},
+		{"ChoiceIndexOutOfRange", InputRequirement{Key: "k", Kind: InputChoice, Choices: []string{"dev"}}, "2", "", "not one of"},
+		{"PatternMatch", InputRequirement{Key: "k", Pattern: "[a-z]+"}, "abc", "abc", ""},
+		{"PatternIsAnchored", InputRequirement{Key: "k", Pattern: "[a-z]+"}, "abc1", "", "does not match"},
+		{"InvalidPattern", InputRequirement{Key: "k", Pattern: "("}, "x", "", "invalid pattern"},
+		{"ValidateError", InputRequirement{Key: "k", Validate: func(string) error { return errors.New("bad") }}, "x", "", "k: bad"},
+		{"SecretValidated", InputRequirement{Key: "k", Kind: InputSecret, Pattern: ".{3,}"}, "ab", "", "does not match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.req.Parse(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInputRequirement_Spec(t *testing.T) {
+	spec := InputRequirement{Key: "k", Prompt: "Proceed?", Kind: InputConfirm, Default: "yes"}.Spec()
+	if !spec.Confirm || spec.Default != "true" {
+		t.Errorf("expected confirm spec with default 'true', got %+v", spec)
+	}
+
+	spec = InputRequirement{Key: "k", Kind: InputSecret}.Spec()
+	if !spec.Secret {
+		t.Error("expected secret spec")
+	}
+
+	spec = InputRequirement{Key: "k", Kind: InputChoice, Choices: []string{"a", "b"}}.Spec()
+	if len(spec.Choices) != 2 || spec.Parse == nil {
+		t.Errorf("expected choices and parser in spec, got %+v", spec)
+	}
+}
+
+type plainPrompter struct {
+	answer  string
+	message string
+}
+
+func (p *plainPrompter) Run(ctx context.Context, name string, args ...string) error { return nil }
+func (p *plainPrompter) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
+	return nil
+}
+func (p *plainPrompter) Prompt(message string, defaultValue string) (string, error) {
+	p.message = message
+	return p.answer, nil
+}
+
+type typedPrompter struct {
+	plainPrompter
+	spec executor.PromptSpec
+}
+
+func (p *typedPrompter) PromptTyped(spec executor.PromptSpec) (string, error) {
+	p.spec = spec
+	return spec.Parse(p.answer)
+}
+
+func TestPromptFor(t *testing.T) {
+	req := InputRequirement{Key: "env", Prompt: "Environment", Kind: InputChoice, Choices: []string{"dev", "prod"}}
+
+	t.Run("PlainPrompt", func(t *testing.T) {
+		p := &plainPrompter{answer: "2"}
+		val, err := PromptFor(p, req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if val != "prod" {
+			t.Errorf("expected 'prod', got %q", val)
+		}
+		if p.message != "Environment (dev/prod)" {
+			t.Errorf("expected choices in prompt, got %q", p.message)
+		}
+	})
+
+	t.Run("PlainPromptInvalid", func(t *testing.T) {
+		if _, err := PromptFor(&plainPrompter{answer: "qa"}, req); err == nil {
+			t.Error("expected error for invalid choice")
+		}
+	})
+
+	t.Run("TypedPrompter", func(t *testing.T) {
+		p := &typedPrompter{plainPrompter: plainPrompter{answer: "dev"}}
+		val, err := PromptFor(p, req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if val != "dev" {
+			t.Errorf("expected 'dev', got %q", val)
+		}
+		if p.spec.Message != "Environment" || len(p.spec.Choices) != 2 {
+			t.Errorf("expected spec to be passed through, got %+v", p.spec)
+		}
+		if p.message != "" {
+			t.Error("expected plain Prompt not to be used")
+		}
+	})
+}
+
+func TestInputEnvVar(t *testing.T) {
+	tests := map[string]string{
+		"install_path":                    "CLEAT_INPUT_INSTALL_PATH",
+		"gcp:account":                     "CLEAT_INPUT_GCP_ACCOUNT",
+		"gcp:impersonate-service-account": "CLEAT_INPUT_GCP_IMPERSONATE_SERVICE_ACCOUNT",
+	}
+	for key, want := range tests {
+		if got := InputEnvVar(key); got != want {
+			t.Errorf("InputEnvVar(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/session"
+)
+
+// CodeTool runs a linter or formatter. A formatter fixes what it can, or with
+// Session.CheckOnly only reports problems and fails when it finds any. A
+// linter only reports problems, or with Session.Fix fixes what it can.
+type CodeTool struct {
+	BaseTask
+	// Kind is lint or fmt
+	Kind    string
+	Service *config.ServiceConfig
+	Dir     string
+	// DockerService is the compose service the tool runs in when Docker is used
+	DockerService string
+	FixArgs       []string
+	CheckArgs     []string
+}
+
+func (t *CodeTool) TargetService() *config.ServiceConfig { return t.Service }
+
+func newCodeTool(kind string, tool string, svc *config.ServiceConfig, fix []string, check []string) *CodeTool {
+	verb := "Lint"
+	if kind == "fmt" {
+		verb = "Format"
+	}
+	t := &CodeTool{
+		BaseTask: BaseTask{
+			TaskName:        fmt.Sprintf("%s:%s", kind, tool),
+			TaskDescription: fmt.Sprintf("%s with %s", verb, tool),
+		},
+		Kind:      kind,
+		Service:   svc,
+		FixArgs:   fix,
+		CheckArgs: check,
+	}
+	if svc != nil {
+		t.Dir = svc.Dir
+	}
+	return t
+}
+
+// ShouldRun is false when the tool has no command for the session's mode, such
+// as a format script without a matching check script under --check
+func (t *CodeTool) ShouldRun(sess *session.Session) bool {
+	return len(t.args(sess)) > 0
+}
+
+func (t *CodeTool) Run(sess *session.Session) error {
+	desc := t.Description()
+	if t.Service != nil {
+		desc += fmt.Sprintf(" for service %s", t.Service.Name)
+	}
+	if t.inDocker(sess) {
+		desc += fmt.Sprintf(" via Docker (%s service)", t.DockerService)
+	}
+	PrintStep(desc)
+
+	cmds := t.Commands(sess)
+	dir := t.Dir
+	if t.inDocker(sess) {
+		dir = ""
+	}
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
+		return fmt.Errorf("%s failed: %w", t.Name(), err)
+	}
+	return nil
+}
+
+func (t *CodeTool) Commands(sess *session.Session) [][]string {
+	args := t.args(sess)
+	if t.inDocker(sess) {
+		cmd := []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", t.DockerService}
+		return [][]string{append(cmd, args...)}
+	}
+	return [][]string{args}
+}
+
+func (t *CodeTool) args(sess *session.Session) []string {
+	if t.fixes(sess) {
+		return t.FixArgs
+	}
+	return t.CheckArgs
+}
+
+// fixes is whether the tool changes files in the session's mode
+func (t *CodeTool) fixes(sess *session.Session) bool {
+	if t.Kind == "lint" {
+		return sess.Fix
+	}
+	return !sess.CheckOnly
+}
+
+func (t *CodeTool) inDocker(sess *session.Session) bool {
+	return sess.Config.Docker && t.Service != nil && t.Service.IsDocker() && t.DockerService != ""
+}
+
+// NewLintTasks returns the linters configured for each module of svc
+func NewLintTasks(svc *config.ServiceConfig) []Task {
+	var tasks []Task
+	for j := range svc.Modules {
+		mod := &svc.Modules[j]
+		if mod.Go.IsEnabled() {
+			if hasAnyFile(svc.Dir, ".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json") {
+				tasks = append(tasks, goTool("lint", "golangci-lint", svc, mod.Go,
+					[]string{"golangci-lint", "run", "--fix", "./..."},
+					[]string{"golangci-lint", "run", "./..."}))
+			} else {
+				tasks = append(tasks, goTool("lint", "go-vet", svc, mod.Go,
+					[]string{"go", "vet", "./..."},
+					[]string{"go", "vet", "./..."}))
+			}
+		}
+		if mod.Python.IsEnabled() {
+			if usesRuff(svc.Dir) {
+				tasks = append(tasks, pythonTool("lint", "ruff", svc, mod.Python,
+					[]string{"ruff", "check", "--fix", "."},
+					[]string{"ruff", "check", "."}))
+			}
+			if usesFlake8(svc.Dir) {
+				tasks = append(tasks, pythonTool("lint", "flake8", svc, mod.Python,
+					[]string{"flake8", "."},
+					[]string{"flake8", "."}))
+			}
+		}
+		if mod.Npm.IsEnabled() {
+			if hasScript(mod.Npm, "lint") {
+				tasks = append(tasks, npmTool("lint", "npm-lint", svc, mod.Npm,
+					[]string{"npm", "run", "lint"},
+					[]string{"npm", "run", "lint"}))
+			} else if hasAnyFile(svc.Dir, eslintConfigs...) {
+				tasks = append(tasks, npmTool("lint", "eslint", svc, mod.Npm,
+					[]string{"npx", "eslint", "--fix", "."},
+					[]string{"npx", "eslint", "."}))
+			}
+		}
+		if mod.Ruby.IsEnabled() && usesRubocop(svc.Dir) {
+			tasks = append(tasks, rubyTool("lint", "rubocop", svc, mod.Ruby,
+				[]string{"bundle", "exec", "rubocop", "--autocorrect"},
+				[]string{"bundle", "exec", "rubocop"}))
+		}
+	}
+	return tasks
+}
+
+// NewFmtTasks returns the formatters configured for each module of svc
+func NewFmtTasks(svc *config.ServiceConfig) []Task {
+	var tasks []Task
+	for j := range svc.Modules {
+		mod := &svc.Modules[j]
+		if mod.Go.IsEnabled() {
+			// gofmt -l exits 0 even when it lists files, so check mode tests its output
+			tasks = append(tasks, goTool("fmt", "gofmt", svc, mod.Go,
+				[]string{"gofmt", "-l", "-w", "."},
+				shellCommand(`files=$(gofmt -l .) || exit 1; [ -z "$files" ] || { echo "Unformatted files:"; echo "$files"; exit 1; }`)))
+		}
+		if mod.Python.IsEnabled() {
+			if usesRuff(svc.Dir) {
+				tasks = append(tasks, pythonTool("fmt", "ruff", svc, mod.Python,
+					[]string{"ruff", "format", "."},
+					[]string{"ruff", "format", "--check", "."}))
+			} else if usesBlack(svc.Dir) {
+				tasks = append(tasks, pythonTool("fmt", "black", svc, mod.Python,
+					[]string{"black", "."},
+					[]string{"black", "--check", "."}))
+			}
+		}
+		if mod.Npm.IsEnabled() {
+			switch {
+			case hasScript(mod.Npm, "format") && hasScript(mod.Npm, "format:check"):
+				tasks = append(tasks, npmTool("fmt", "npm-format", svc, mod.Npm,
+					[]string{"npm", "run", "format"},
+					[]string{"npm", "run", "format:check"}))
+			case hasAnyFile(svc.Dir, prettierConfigs...):
+				tasks = append(tasks, npmTool("fmt", "prettier", svc, mod.Npm,
+					[]string{"npx", "prettier", "--write", "."},
+					[]string{"npx", "prettier", "--check", "."}))
+			case hasScript(mod.Npm, "format"):
+				tasks = append(tasks, npmTool("fmt", "npm-format", svc, mod.Npm,
+					[]string{"npm", "run", "format"},
+					nil))
+			}
+		}
+		if mod.Ruby.IsEnabled() && usesRubocop(svc.Dir) {
+			tasks = append(tasks, rubyTool("fmt", "rubocop", svc, mod.Ruby,
+				[]string{"bundle", "exec", "rubocop", "--fix-layout"},
+				[]string{"bundle", "exec", "rubocop", "--only", "Layout"}))
+		}
+	}
+	return tasks
+}
+
+// NewTerraformFmt formats the Terraform files of the project
+func NewTerraformFmt(cfg *config.Config) *CodeTool {
+	t := newCodeTool("fmt", "terraform", nil,
+		[]string{"terraform", "fmt", "-recursive"},
+		[]string{"terraform", "fmt", "-check", "-recursive"})
+	t.Dir = ".iac"
+	if cfg.Terraform != nil && cfg.Terraform.Dir != "" {
+		t.Dir = cfg.Terraform.Dir
+	}
+	if cfg.SourcePath != "" && !filepath.IsAbs(t.Dir) {
+		t.Dir = filepath.Join(filepath.Dir(cfg.SourcePath), t.Dir)
+	}
+	return t
+}
+
+func goTool(kind, tool string, svc *config.ServiceConfig, g *config.GoConfig, fix, check []string) *CodeTool {
+	t := newCodeTool(kind, tool, svc, fix, check)
+	t.DockerService = g.Service
+	return t
+}
+
+func pythonTool(kind, tool string, svc *config.ServiceConfig, p *config.PythonConfig, fix, check []string) *CodeTool {
+	// Run through the project's interpreter so the tool comes from its environment
+	prefix := append(pythonCommand(p), "-m")
+	t := newCodeTool(kind, tool, svc, append(prefix, fix...), append(append([]string{}, prefix...), check...))
+	t.DockerService = p.DjangoService
+	return t
+}
+
+func npmTool(kind, tool string, svc *config.ServiceConfig, npm *config.NpmConfig, fix, check []string) *CodeTool {
+	t := newCodeTool(kind, tool, svc, fix, check)
+	t.DockerService = npm.Service
+	return t
+}
+
+func rubyTool(kind, tool string, svc *config.ServiceConfig, r *config.RubyConfig, fix, check []string) *CodeTool {
+	t := newCodeTool(kind, tool, svc, fix, check)
+	t.DockerService = r.RailsService
+	return t
+}
+
+var eslintConfigs = []string{
+	"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts",
+	".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml",
+}
+
+var prettierConfigs = []string{
+	".prettierrc", ".prettierrc.json", ".prettierrc.yml", ".prettierrc.yaml", ".prettierrc.js", ".prettierrc.cjs", ".prettierrc.mjs",
+	"prettier.config.js", "prettier.config.cjs", "prettier.config.mjs",
+}
+
+func hasAnyFile(dir string, names ...string) bool {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fileContains reports whether any of the named files in dir contains one of the markers
+func fileContains(dir string, names []string, markers ...string) bool {
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		for _, m := range markers {
+			if strings.Contains(string(data), m) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasScript(npm *config.NpmConfig, script string) bool {
+	for _, s := range npm.Scripts {
+		if s == script {
+			return true
+		}
+	}
+	return false
+}
+
+func usesRuff(dir string) bool {
+	return hasAnyFile(dir, "ruff.toml", ".ruff.toml") || fileContains(dir, []string{"pyproject.toml"}, "[tool.ruff")
+}
+
+func usesBlack(dir string) bool {
+	return fileContains(dir, []string{"pyproject.toml"}, "[tool.black")
+}
+
+func usesFlake8(dir string) bool {
+	return hasAnyFile(dir, ".flake8") || fileContains(dir, []string{"setup.cfg", "tox.ini"}, "[flake8]")
+}
+
+func usesRubocop(dir string) bool {
+	return hasAnyFile(dir, ".rubocop.yml") || fileContains(dir, []string{"Gemfile"}, "rubocop")
+}
@@ -0,0 +1,175 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/session"
+)
+
+func TestLintAndFmtTasks(t *testing.T) {
+	tests := []struct {
+		name      string
+		module    config.ModuleConfig
+		files     map[string]string
+		docker    bool
+		fmt       bool
+		check     bool
+		fix       bool
+		wantNames []string
+		wantCmds  [][]string
+	}{
+		{
+			name:      "go vet without golangci config",
+			module:    config.ModuleConfig{Go: &config.GoConfig{}},
+			wantNames: []string{"lint:go-vet"},
+			wantCmds:  [][]string{{"go", "vet", "./..."}},
+		},
+		{
+			name:      "golangci-lint reports by default",
+			module:    config.ModuleConfig{Go: &config.GoConfig{}},
+			files:     map[string]string{".golangci.yml": "linters: {}\n"},
+			wantNames: []string{"lint:golangci-lint"},
+			wantCmds:  [][]string{{"golangci-lint", "run", "./..."}},
+		},
+		{
+			name:      "golangci-lint fix",
+			module:    config.ModuleConfig{Go: &config.GoConfig{}},
+			files:     map[string]string{".golangci.yml": "linters: {}\n"},
+			fix:       true,
+			wantNames: []string{"lint:golangci-lint"},
+			wantCmds:  [][]string{{"golangci-lint", "run", "--fix", "./..."}},
+		},
+		{
+			name:      "gofmt fix",
+			module:    config.ModuleConfig{Go: &config.GoConfig{}},
+			fmt:       true,
+			wantNames: []string{"fmt:gofmt"},
+			wantCmds:  [][]string{{"gofmt", "-l", "-w", "."}},
+		},
+		{
+			name:      "ruff and flake8 from config files",
+			module:    config.ModuleConfig{Python: &config.PythonConfig{PackageManager: "uv"}},
+			files:     map[string]string{"pyproject.toml": "[tool.ruff]\nline-length = 100\n", "setup.cfg": "[flake8]\nmax-line-length = 100\n"},
+			fix:       true,
+			wantNames: []string{"lint:ruff", "lint:flake8"},
+			wantCmds: [][]string{
+				{"uv", "run", "python", "-m", "ruff", "check", "--fix", "."},
+				{"uv", "run", "python", "-m", "flake8", "."},
+			},
+		},
+		{
+			name:      "black check",
+			module:    config.ModuleConfig{Python: &config.PythonConfig{PackageManager: "pip"}},
+			files:     map[string]string{"pyproject.toml": "[tool.black]\n"},
+			fmt:       true,
+			check:     true,
+			wantNames: []string{"fmt:black"},
+			wantCmds:  [][]string{{"python", "-m", "black", "--check", "."}},
+		},
+		{
+			name:      "npm lint script",
+			module:    config.ModuleConfig{Npm: &config.NpmConfig{Scripts: []string{"build", "lint"}}},
+			files:     map[string]string{".eslintrc.json": "{}"},
+			wantNames: []string{"lint:npm-lint"},
+			wantCmds:  [][]string{{"npm", "run", "lint"}},
+		},
+		{
+			name:      "eslint config without script",
+			module:    config.ModuleConfig{Npm: &config.NpmConfig{Scripts: []string{"build"}}},
+			files:     map[string]string{"eslint.config.js": "export default []\n"},
+			wantNames: []string{"lint:eslint"},
+			wantCmds:  [][]string{{"npx", "eslint", "."}},
+		},
+		{
+			name:      "prettier check in docker",
+			module:    config.ModuleConfig{Npm: &config.NpmConfig{Service: "web"}},
+			files:     map[string]string{".prettierrc": "{}"},
+			docker:    true,
+			fmt:       true,
+			check:     true,
+			wantNames: []string{"fmt:prettier"},
+			wantCmds:  [][]string{{"docker", "--log-level", "error", "compose", "run", "--rm", "web", "npx", "prettier", "--check", "."}},
+		},
+		{
+			name:      "format script has no check mode",
+			module:    config.ModuleConfig{Npm: &config.NpmConfig{Scripts: []string{"format"}}},
+			fmt:       true,
+			check:     true,
+			wantNames: []string{"fmt:npm-format"},
+		},
+		{
+			name:      "rubocop",
+			module:    config.ModuleConfig{Ruby: &config.RubyConfig{}},
+			files:     map[string]string{"Gemfile": "gem 'rubocop', require: false\n"},
+			wantNames: []string{"lint:rubocop"},
+			wantCmds:  [][]string{{"bundle", "exec", "rubocop"}},
+		},
+		{
+			name:   "no tools configured",
+			module: config.ModuleConfig{Ruby: &config.RubyConfig{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+			}
+			svc := &config.ServiceConfig{
+				Name:    "app",
+				Dir:     dir,
+				Docker:  ptrBool(tt.docker),
+				Modules: []config.ModuleConfig{tt.module},
+			}
+			sess := session.NewSession(&config.Config{Docker: tt.docker}, nil)
+			sess.CheckOnly = tt.check
+			sess.Fix = tt.fix
+
+			tasks := NewLintTasks(svc)
+			if tt.fmt {
+				tasks = NewFmtTasks(svc)
+			}
+
+			var names []string
+			var cmds [][]string
+			for _, tsk := range tasks {
+				names = append(names, tsk.Name())
+				if tsk.ShouldRun(sess) {
+					cmds = append(cmds, tsk.Commands(sess)...)
+				}
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("expected tasks %v, got %v", tt.wantNames, names)
+			}
+			if !reflect.DeepEqual(cmds, tt.wantCmds) {
+				t.Errorf("expected commands %v, got %v", tt.wantCmds, cmds)
+			}
+		})
+	}
+}
+
+func TestTerraformFmt(t *testing.T) {
+	cfg := &config.Config{
+		SourcePath: filepath.Join("/project", "cleat.yaml"),
+		Terraform:  &config.TerraformConfig{Dir: "infra"},
+	}
+	sess := session.NewSession(cfg, nil)
+
+	tsk := NewTerraformFmt(cfg)
+	if tsk.Dir != filepath.Join("/project", "infra") {
+		t.Errorf("expected dir /project/infra, got %s", tsk.Dir)
+	}
+	if got := tsk.Commands(sess)[0]; !reflect.DeepEqual(got, []string{"terraform", "fmt", "-recursive"}) {
+		t.Errorf("unexpected fix command %v", got)
+	}
+
+	sess.CheckOnly = true
+	if got := tsk.Commands(sess)[0]; !reflect.DeepEqual(got, []string{"terraform", "fmt", "-check", "-recursive"}) {
+		t.Errorf("unexpected check command %v", got)
+	}
+}
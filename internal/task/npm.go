@@ -14,6 +14,8 @@ type NpmRun struct {
 	Script  string
 }
 
+func (t *NpmRun) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewNpmRun(svc *config.ServiceConfig, npm *config.NpmConfig, script string) *NpmRun {
 	return &NpmRun{
 		BaseTask: BaseTask{
@@ -41,7 +43,7 @@ func (t *NpmRun) Run(sess *session.Session) error {
 	if sess.Config.Docker && t.Service.IsDocker() && t.Npm.Service != "" {
 		dir = ""
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("npm run %s failed for service %s: %w", t.Script, t.Service.Name, err)
 	}
 	return nil
@@ -49,7 +51,7 @@ func (t *NpmRun) Run(sess *session.Session) error {
 
 func (t *NpmRun) Commands(sess *session.Session) [][]string {
 	if sess.Config.Docker && t.Service.IsDocker() && t.Npm.Service != "" {
-		return [][]string{{"docker", "--log-level", "error", "compose", "run", "--rm", t.Npm.Service, "npm", "run", t.Script}}
+		return [][]string{{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", t.Npm.Service, "npm", "run", t.Script}}
 	}
 	return [][]string{{"npm", "run", t.Script}}
 }
@@ -60,6 +62,8 @@ type NpmInstall struct {
 	Npm     *config.NpmConfig
 }
 
+func (t *NpmInstall) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewNpmInstall(svc *config.ServiceConfig, npm *config.NpmConfig) *NpmInstall {
 	return &NpmInstall{
 		BaseTask: BaseTask{
@@ -86,7 +90,7 @@ func (t *NpmInstall) Run(sess *session.Session) error {
 	if sess.Config.Docker && t.Service.IsDocker() && t.Npm.Service != "" {
 		dir = ""
 	}
-	if err := sess.Exec.RunWithDir(dir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("npm install failed for service %s: %w", t.Service.Name, err)
 	}
 	return nil
@@ -94,7 +98,7 @@ func (t *NpmInstall) Run(sess *session.Session) error {
 
 func (t *NpmInstall) Commands(sess *session.Session) [][]string {
 	if sess.Config.Docker && t.Service.IsDocker() && t.Npm.Service != "" {
-		return [][]string{{"docker", "--log-level", "error", "compose", "run", "--rm", t.Npm.Service, "npm", "install"}}
+		return [][]string{{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", t.Npm.Service, "npm", "install"}}
 	}
 	return [][]string{{"npm", "install"}}
 }
@@ -0,0 +1,161 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/session"
+)
+
+// PythonTest runs a Python service's test suite with pytest, or with the
+// Django test runner when the service is a Django project without pytest
+type PythonTest struct {
+	BaseTask
+	Service *config.ServiceConfig
+}
+
+func (t *PythonTest) TargetService() *config.ServiceConfig { return t.Service }
+
+func NewPythonTest(svc *config.ServiceConfig) *PythonTest {
+	return &PythonTest{
+		BaseTask: BaseTask{
+			TaskName:        "python:test",
+			TaskDescription: "Run Python tests",
+		},
+		Service: svc,
+	}
+}
+
+func (t *PythonTest) ShouldRun(sess *session.Session) bool {
+	return getPythonConfig(t.Service).IsEnabled()
+}
+
+func (t *PythonTest) Run(sess *session.Session) error {
+	pyConfig := getPythonConfig(t.Service)
+	if t.inDocker(sess) {
+		PrintStep(fmt.Sprintf("Running Python tests for service %s via Docker (%s service)", t.Service.Name, pyConfig.DjangoService))
+	} else {
+		PrintStep(fmt.Sprintf("Running Python tests for service %s", t.Service.Name))
+	}
+	cmds := t.Commands(sess)
+	dir := t.Service.Dir
+	if t.inDocker(sess) {
+		dir = ""
+	}
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
+		return fmt.Errorf("python tests failed for service %s: %w", t.Service.Name, err)
+	}
+	return nil
+}
+
+func (t *PythonTest) Commands(sess *session.Session) [][]string {
+	pyConfig := getPythonConfig(t.Service)
+	var args []string
+	if pyConfig.Django && !usesPytest(t.Service.Dir) {
+		args = append(pythonCommand(pyConfig), findManagePy(t.Service.Dir), "test")
+	} else {
+		args = append(pythonCommand(pyConfig), "-m", "pytest")
+	}
+
+	if t.inDocker(sess) {
+		cmd := []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
+		return [][]string{append(cmd, args...)}
+	}
+	return [][]string{args}
+}
+
+func (t *PythonTest) inDocker(sess *session.Session) bool {
+	return pythonInDocker(sess, t.Service)
+}
+
+// PythonInstall installs a Python service's dependencies with its package manager
+type PythonInstall struct {
+	BaseTask
+	Service *config.ServiceConfig
+}
+
+func (t *PythonInstall) TargetService() *config.ServiceConfig { return t.Service }
+
+func NewPythonInstall(svc *config.ServiceConfig) *PythonInstall {
+	return &PythonInstall{
+		BaseTask: BaseTask{
+			TaskName:        "python:install",
+			TaskDescription: "Install Python dependencies",
+		},
+		Service: svc,
+	}
+}
+
+func (t *PythonInstall) ShouldRun(sess *session.Session) bool {
+	return getPythonConfig(t.Service).IsEnabled()
+}
+
+func (t *PythonInstall) Run(sess *session.Session) error {
+	pyConfig := getPythonConfig(t.Service)
+	inDocker := pythonInDocker(sess, t.Service)
+	if inDocker {
+		PrintStep(fmt.Sprintf("Installing Python dependencies for service %s via Docker (%s service)", t.Service.Name, pyConfig.DjangoService))
+	} else {
+		PrintStep(fmt.Sprintf("Installing Python dependencies for service %s", t.Service.Name))
+	}
+	cmds := t.Commands(sess)
+	dir := t.Service.Dir
+	if inDocker {
+		dir = ""
+	}
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmds[0][0], cmds[0][1:]...); err != nil {
+		return fmt.Errorf("python install failed for service %s: %w", t.Service.Name, err)
+	}
+	return nil
+}
+
+func (t *PythonInstall) Commands(sess *session.Session) [][]string {
+	pyConfig := getPythonConfig(t.Service)
+	var args []string
+	switch pyConfig.PackageManager {
+	case "pip":
+		if _, err := os.Stat(filepath.Join(t.Service.Dir, "requirements.txt")); err == nil {
+			args = []string{"pip", "install", "-r", "requirements.txt"}
+		} else {
+			args = []string{"pip", "install", "."}
+		}
+	case "poetry":
+		args = []string{"poetry", "install"}
+	default:
+		args = []string{"uv", "sync"}
+	}
+
+	if pythonInDocker(sess, t.Service) {
+		cmd := []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", pyConfig.DjangoService}
+		return [][]string{append(cmd, args...)}
+	}
+	return [][]string{args}
+}
+
+// pythonInDocker reports whether Python commands for svc run in its compose service
+func pythonInDocker(sess *session.Session, svc *config.ServiceConfig) bool {
+	pyConfig := getPythonConfig(svc)
+	return sess.Config.Docker && svc.IsDocker() && pyConfig != nil && pyConfig.DjangoService != ""
+}
+
+// usesPytest reports whether the project in dir is configured for pytest
+func usesPytest(dir string) bool {
+	for _, name := range []string{"pytest.ini", "conftest.py"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	for _, name := range []string{"pyproject.toml", "setup.cfg", "tox.ini"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if strings.Contains(string(data), "[tool.pytest") || strings.Contains(string(data), "[pytest]") || strings.Contains(string(data), "[tool:pytest]") {
+			return true
+		}
+	}
+	return false
+}
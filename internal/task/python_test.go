@@ -0,0 +1,114 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/session"
+)
+
+func TestPythonTest(t *testing.T) {
+	tests := []struct {
+		name    string
+		python  *config.PythonConfig
+		docker  bool
+		files   map[string]string
+		wantCmd []string
+	}{
+		{
+			name:    "django runner",
+			python:  &config.PythonConfig{Django: true},
+			wantCmd: []string{"uv", "run", "python", "manage.py", "test"},
+		},
+		{
+			name:    "django with pytest config",
+			python:  &config.PythonConfig{Django: true},
+			files:   map[string]string{"pyproject.toml": "[tool.pytest.ini_options]\n"},
+			wantCmd: []string{"uv", "run", "python", "-m", "pytest"},
+		},
+		{
+			name:    "plain python with pip",
+			python:  &config.PythonConfig{PackageManager: "pip"},
+			wantCmd: []string{"python", "-m", "pytest"},
+		},
+		{
+			name:    "django in docker",
+			python:  &config.PythonConfig{Django: true, DjangoService: "backend"},
+			docker:  true,
+			wantCmd: []string{"docker", "--log-level", "error", "compose", "run", "--rm", "backend", "uv", "run", "python", "manage.py", "test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+			}
+			svc := &config.ServiceConfig{
+				Name:    "api",
+				Dir:     dir,
+				Docker:  ptrBool(tt.docker),
+				Modules: []config.ModuleConfig{{Python: tt.python}},
+			}
+			tsk := NewPythonTest(svc)
+			sess := session.NewSession(&config.Config{Docker: tt.docker}, nil)
+			if !tsk.ShouldRun(sess) {
+				t.Fatal("expected task to run")
+			}
+			if got := tsk.Commands(sess)[0]; !reflect.DeepEqual(got, tt.wantCmd) {
+				t.Errorf("got %v, want %v", got, tt.wantCmd)
+			}
+		})
+	}
+
+	t.Run("runs locally", func(t *testing.T) {
+		svc := &config.ServiceConfig{Name: "api", Dir: "api", Modules: []config.ModuleConfig{{Python: &config.PythonConfig{}}}}
+		mock := &mockExecutor{}
+		sess := session.NewSession(&config.Config{}, mock)
+		if err := NewPythonTest(svc).Run(sess); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(mock.commands) != 1 || !reflect.DeepEqual(mock.commands[0], []string{"uv", "run", "python", "-m", "pytest"}) {
+			t.Errorf("unexpected commands %v", mock.commands)
+		}
+	})
+}
+
+func TestPythonInstall(t *testing.T) {
+	tests := []struct {
+		name    string
+		python  *config.PythonConfig
+		docker  bool
+		files   map[string]string
+		wantCmd []string
+	}{
+		{"uv by default", &config.PythonConfig{}, false, nil, []string{"uv", "sync"}},
+		{"poetry", &config.PythonConfig{PackageManager: "poetry"}, false, nil, []string{"poetry", "install"}},
+		{"pip requirements", &config.PythonConfig{PackageManager: "pip"}, false, map[string]string{"requirements.txt": "django\n"}, []string{"pip", "install", "-r", "requirements.txt"}},
+		{"pip project", &config.PythonConfig{PackageManager: "pip"}, false, nil, []string{"pip", "install", "."}},
+		{"uv in docker", &config.PythonConfig{DjangoService: "backend"}, true, nil, []string{"docker", "--log-level", "error", "compose", "run", "--rm", "backend", "uv", "sync"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+			}
+			svc := &config.ServiceConfig{
+				Name:    "api",
+				Dir:     dir,
+				Docker:  ptrBool(tt.docker),
+				Modules: []config.ModuleConfig{{Python: tt.python}},
+			}
+			sess := session.NewSession(&config.Config{Docker: tt.docker}, nil)
+			if got := NewPythonInstall(svc).Commands(sess)[0]; !reflect.DeepEqual(got, tt.wantCmd) {
+				t.Errorf("got %v, want %v", got, tt.wantCmd)
+			}
+		})
+	}
+}
@@ -16,6 +16,8 @@ type RubyAction struct {
 	Action  string
 }
 
+func (t *RubyAction) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewRubyAction(svc *config.ServiceConfig, r *config.RubyConfig, action string) *RubyAction {
 	return &RubyAction{
 		BaseTask: BaseTask{
@@ -45,7 +47,7 @@ func (t *RubyAction) Run(sess *session.Session) error {
 		dir = ""
 	}
 
-	if err := sess.Exec.RunWithDir(dir, cmd[0], cmd[1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmd[0], cmd[1:]...); err != nil {
 		return fmt.Errorf("ruby %s failed for service %s: %w", t.Action, t.Service.Name, err)
 	}
 	return nil
@@ -53,9 +55,11 @@ func (t *RubyAction) Run(sess *session.Session) error {
 
 func (t *RubyAction) commandArgs(sess *session.Session) []string {
 	args := t.argsForAction()
+	// Test runners come from the Gemfile even outside Rails
+	bundled := t.RubyCfg.Rails || t.Action == "test"
 	if sess.Config.Docker && t.Service.IsDocker() && t.RubyCfg.RailsService != "" {
-		base := []string{"docker", "--log-level", "error", "compose", "run", "--rm", t.RubyCfg.RailsService}
-		if t.RubyCfg.Rails {
+		base := []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", t.RubyCfg.RailsService}
+		if bundled {
 			return append(base, append([]string{"bundle", "exec"}, args...)...)
 		}
 		return append(base, args...)
@@ -63,7 +67,7 @@ func (t *RubyAction) commandArgs(sess *session.Session) []string {
 
 	// Local execution
 	envCmd := detectRubyEnvCommand(t.Service.Dir, sess.Config.SourcePath)
-	if t.RubyCfg.Rails {
+	if bundled {
 		return append(envCmd, append([]string{"bundle", "exec"}, args...)...)
 	}
 	return append(envCmd, args...)
@@ -79,6 +83,14 @@ func (t *RubyAction) argsForAction() []string {
 		return []string{"rails", "server", "-b", "0.0.0.0"}
 	case "assets:precompile":
 		return []string{"rails", "assets:precompile"}
+	case "test":
+		if _, err := os.Stat(filepath.Join(t.Service.Dir, "spec")); err == nil {
+			return []string{"rspec"}
+		}
+		if t.RubyCfg.Rails {
+			return []string{"rails", "test"}
+		}
+		return []string{"rake", "test"}
 	default:
 		return []string{t.Action}
 	}
@@ -94,6 +106,8 @@ type RubyInstall struct {
 	RubyCfg *config.RubyConfig
 }
 
+func (t *RubyInstall) TargetService() *config.ServiceConfig { return t.Service }
+
 func NewRubyInstall(svc *config.ServiceConfig, r *config.RubyConfig) *RubyInstall {
 	return &RubyInstall{
 		BaseTask: BaseTask{
@@ -116,7 +130,7 @@ func (t *RubyInstall) Run(sess *session.Session) error {
 	if sess.Config.Docker && t.Service.IsDocker() && t.RubyCfg.RailsService != "" {
 		dir = ""
 	}
-	if err := sess.Exec.RunWithDir(dir, cmd[0], cmd[1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), dir, cmd[0], cmd[1:]...); err != nil {
 		return fmt.Errorf("bundle install failed for service %s: %w", t.Service.Name, err)
 	}
 	return nil
@@ -125,7 +139,7 @@ func (t *RubyInstall) Run(sess *session.Session) error {
 func (t *RubyInstall) commandArgs(sess *session.Session) []string {
 	args := []string{"bundle", "install"}
 	if sess.Config.Docker && t.Service.IsDocker() && t.RubyCfg.RailsService != "" {
-		return []string{"docker", "--log-level", "error", "compose", "run", "--rm", t.RubyCfg.RailsService, "bundle", "install"}
+		return []string{containerRuntime(), "--log-level", "error", "compose", "run", "--rm", t.RubyCfg.RailsService, "bundle", "install"}
 	}
 	return args
 }
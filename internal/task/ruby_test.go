@@ -1,6 +1,9 @@
 package task
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/madewithfuture/cleat/internal/config"
@@ -42,6 +45,18 @@ func TestRubyCommands(t *testing.T) {
 			task:          NewRubyAction(svc, rubyCfg, "migrate"),
 			wantCmd:       []string{"docker", "--log-level", "error", "compose", "run", "--rm", "rails-svc", "bundle", "exec", "rails", "db:migrate"},
 		},
+		{
+			name:          "test local",
+			dockerEnabled: false,
+			task:          NewRubyAction(svc, rubyCfg, "test"),
+			wantCmd:       []string{"bundle", "exec", "rails", "test"},
+		},
+		{
+			name:          "test docker",
+			dockerEnabled: true,
+			task:          NewRubyAction(svc, rubyCfg, "test"),
+			wantCmd:       []string{"docker", "--log-level", "error", "compose", "run", "--rm", "rails-svc", "bundle", "exec", "rails", "test"},
+		},
 		{
 			name:          "bundle install local",
 			dockerEnabled: false,
@@ -76,3 +91,19 @@ func TestRubyCommands(t *testing.T) {
 		})
 	}
 }
+
+func TestRubyTestRunner(t *testing.T) {
+	dir := t.TempDir()
+	sess := session.NewSession(&config.Config{}, nil)
+
+	plain := &config.RubyConfig{}
+	svc := &config.ServiceConfig{Name: "gem", Dir: dir, Modules: []config.ModuleConfig{{Ruby: plain}}}
+	if got := NewRubyAction(svc, plain, "test").Commands(sess)[0]; !reflect.DeepEqual(got, []string{"bundle", "exec", "rake", "test"}) {
+		t.Errorf("expected rake test without Rails, got %v", got)
+	}
+
+	os.Mkdir(filepath.Join(dir, "spec"), 0755)
+	if got := NewRubyAction(svc, plain, "test").Commands(sess)[0]; !reflect.DeepEqual(got, []string{"bundle", "exec", "rspec"}) {
+		t.Errorf("expected rspec when spec/ exists, got %v", got)
+	}
+}
@@ -28,26 +28,22 @@ func (t *ShellTask) ShouldRun(sess *session.Session) bool {
 }
 
 func (t *ShellTask) Run(sess *session.Session) error {
-	shell := "sh"
-	shellArg := "-c"
-	if runtime.GOOS == "windows" {
-		shell = "cmd"
-		shellArg = "/c"
-	}
-
 	// We use the executor's Run method, but we pass the shell as the command
-	if err := sess.Exec.Run(shell, shellArg, t.FullCommand); err != nil {
+	args := shellCommand(t.FullCommand)
+	if err := sess.Exec.Run(sess.Context(), args[0], args[1:]...); err != nil {
 		return fmt.Errorf("shell command failed: %w", err)
 	}
 	return nil
 }
 
 func (t *ShellTask) Commands(sess *session.Session) [][]string {
-	shell := "sh"
-	shellArg := "-c"
+	return [][]string{shellCommand(t.FullCommand)}
+}
+
+// shellCommand wraps a command line in the system shell
+func shellCommand(line string) []string {
 	if runtime.GOOS == "windows" {
-		shell = "cmd"
-		shellArg = "/c"
+		return []string{"cmd", "/c", line}
 	}
-	return [][]string{{shell, shellArg, t.FullCommand}}
+	return []string{"sh", "-c", line}
 }
@@ -2,12 +2,14 @@ package task
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/madewithfuture/cleat/internal/config"
 	"github.com/madewithfuture/cleat/internal/logger"
 	"github.com/madewithfuture/cleat/internal/session"
 	"github.com/madewithfuture/cleat/internal/ui/theme"
@@ -16,6 +18,9 @@ import (
 var (
 	// LookPath is a mockable version of exec.LookPath
 	LookPath = exec.LookPath
+
+	// Output receives step messages; dry runs point it at io.Discard
+	Output io.Writer = os.Stdout
 )
 
 // Task represents an atomic unit of work
@@ -42,11 +47,11 @@ type Task interface {
 	Requirements(sess *session.Session) []InputRequirement
 }
 
-// InputRequirement represents a piece of information needed from the user
-type InputRequirement struct {
-	Key     string
-	Prompt  string
-	Default string
+// ServiceScoped is implemented by tasks that act on a single service. Strategies
+// use it to run a service's tasks after those of the services it depends on.
+type ServiceScoped interface {
+	// TargetService returns the service, or nil for a project-wide run
+	TargetService() *config.ServiceConfig
 }
 
 // BaseTask provides common defaults for tasks
@@ -149,10 +154,10 @@ func DetectEnvFile(searchDir string) (execPath string, absPath string, displayPa
 
 // PrintStep prints a formatted step header with a preceding newline
 func PrintStep(msg string) {
-	fmt.Printf("\n%s\n", lipgloss.NewStyle().Foreground(theme.Purple).Bold(true).Render("==> "+msg))
+	fmt.Fprintf(Output, "\n%s\n", lipgloss.NewStyle().Foreground(theme.Purple).Bold(true).Render("==> "+msg))
 }
 
 // PrintSubStep prints a formatted sub-step message
 func PrintSubStep(msg string) {
-	fmt.Printf("%s\n", lipgloss.NewStyle().Foreground(theme.Cyan).Render("--> "+msg))
+	fmt.Fprintf(Output, "%s\n", lipgloss.NewStyle().Foreground(theme.Cyan).Render("--> "+msg))
 }
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,14 +24,14 @@ type MockExecutor struct {
 	Args      []string
 }
 
-func (e *MockExecutor) Run(name string, args ...string) error {
+func (e *MockExecutor) Run(ctx context.Context, name string, args ...string) error {
 	e.RunCalled = true
 	e.Name = name
 	e.Args = args
 	return nil
 }
 
-func (e *MockExecutor) RunWithDir(dir string, name string, args ...string) error {
+func (e *MockExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
 	e.RunCalled = true
 	e.Dir = dir
 	e.Name = name
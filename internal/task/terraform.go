@@ -64,7 +64,7 @@ func (t *Terraform) Run(sess *session.Session) error {
 
 	cmds := t.Commands(sess)
 	// We run from baseDir (cleat root) and use -chdir in the command
-	if err := sess.Exec.RunWithDir(baseDir, cmds[0][0], cmds[0][1:]...); err != nil {
+	if err := sess.Exec.RunWithDir(sess.Context(), baseDir, cmds[0][0], cmds[0][1:]...); err != nil {
 		return fmt.Errorf("terraform %s failed: %w", t.Action, err)
 	}
 	return nil
@@ -4,13 +4,16 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/madewithfuture/cleat/internal/config"
 	"github.com/madewithfuture/cleat/internal/history"
 	"github.com/madewithfuture/cleat/internal/logger"
 	"github.com/madewithfuture/cleat/internal/session"
+	"github.com/madewithfuture/cleat/internal/settings"
 	"github.com/madewithfuture/cleat/internal/strategy"
 	"github.com/madewithfuture/cleat/internal/task"
 )
@@ -65,19 +68,7 @@ func (m model) handleConfirmClearHistory(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "y", "enter":
-			if err := history.Clear(); err != nil {
-				m.fatalError = fmt.Errorf("failed to clear history: %w", err)
-				logger.Error("history clear failed", err, nil)
-			}
-			var err error
-			m.history, err = history.Load()
-			if err != nil {
-				logger.Warn("failed to load history after clear", map[string]interface{}{"error": err.Error()})
-			}
-			m.historyCursor = 0
-			m.historyOffset = 0
-			m.state = stateBrowsing
-			m.updateTaskPreview()
+			m.clearHistory()
 			return m, nil
 		case "n", "esc":
 			m.state = stateBrowsing
@@ -95,34 +86,7 @@ func (m model) handleConfirmDeleteWorkflow(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "y", "enter":
-			// Get workflow name from current item
-			var name string
-			if len(m.visibleItems) > 0 {
-				item := m.visibleItems[m.cursor]
-				if strings.HasPrefix(item.item.Command, "workflow:") {
-					name = strings.TrimPrefix(item.item.Command, "workflow:")
-				}
-			}
-
-			if name != "" {
-				if err := history.DeleteWorkflow(name); err != nil {
-					m.fatalError = fmt.Errorf("failed to delete workflow: %w", err)
-				} else {
-					// Reload workflows
-					var err error
-					m.workflows, err = history.LoadWorkflows(m.cfg)
-					if err != nil {
-						logger.Warn("failed to reload workflows after deletion", map[string]interface{}{"error": err.Error()})
-					}
-					m.tree = buildCommandTree(m.cfg, m.workflows)
-					m.updateVisibleItems()
-					m.cursor = 0
-					m.scrollOffset = 0
-				}
-			}
-
-			m.state = stateBrowsing
-			m.updateTaskPreview()
+			m.deleteWorkflow()
 			return m, nil
 		case "n", "esc":
 			m.state = stateBrowsing
@@ -135,34 +99,170 @@ func (m model) handleConfirmDeleteWorkflow(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// clearHistory clears the history and goes back to browsing
+func (m *model) clearHistory() {
+	if err := history.Clear(); err != nil {
+		m.fatalError = fmt.Errorf("failed to clear history: %w", err)
+		logger.Error("history clear failed", err, nil)
+	}
+	var err error
+	m.history, err = history.Load()
+	if err != nil {
+		logger.Warn("failed to load history after clear", map[string]interface{}{"error": err.Error()})
+	}
+	m.historyCursor = 0
+	m.historyOffset = 0
+	m.state = stateBrowsing
+	m.updateTaskPreview()
+}
+
+// deleteWorkflow deletes the workflow under the cursor and goes back to
+// browsing
+func (m *model) deleteWorkflow() {
+	// Get workflow name from current item
+	var name string
+	if len(m.visibleItems) > 0 {
+		item := m.visibleItems[m.cursor]
+		if strings.HasPrefix(item.item.Command, "workflow:") {
+			name = strings.TrimPrefix(item.item.Command, "workflow:")
+		}
+	}
+
+	if name != "" {
+		if err := history.DeleteWorkflow(name); err != nil {
+			m.fatalError = fmt.Errorf("failed to delete workflow: %w", err)
+		} else {
+			// Reload workflows
+			var err error
+			m.workflows, err = history.LoadWorkflows(m.cfg)
+			if err != nil {
+				logger.Warn("failed to reload workflows after deletion", map[string]interface{}{"error": err.Error()})
+			}
+			m.tree = buildCommandTree(m.cfg, m.workflows)
+			m.updateVisibleItems()
+			m.cursor = 0
+			m.scrollOffset = 0
+		}
+	}
+
+	m.state = stateBrowsing
+	m.updateTaskPreview()
+}
+
 func (m model) handleInputCollection(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var req task.InputRequirement
+	if m.requirementIdx < len(m.requirements) {
+		req = m.requirements[m.requirementIdx]
+	}
+	choices := requirementChoices(req)
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyEnter:
-			m.collectedInputs[m.requirements[m.requirementIdx].Key] = m.textInput.Value()
+			if m.requirementIdx >= len(m.requirements) {
+				return m, nil
+			}
+			answer := m.textInput.Value()
+			if len(choices) > 0 {
+				answer = choices[m.choiceIdx]
+			}
+			val, err := req.Parse(answer)
+			if err != nil {
+				m.inputErr = err
+				return m, nil
+			}
+			m.collectedInputs[req.Key] = val
+			if req.IsSecret() {
+				m.secretInputs[req.Key] = true
+			}
 			m.requirementIdx++
 			if m.requirementIdx >= len(m.requirements) {
+				m.textInput.EchoMode = textinput.EchoNormal
 				m.quitting = true
 				return m, tea.Quit
 			}
-			m.textInput.Prompt = m.requirements[m.requirementIdx].Prompt + ": "
-			m.textInput.SetValue(m.requirements[m.requirementIdx].Default)
-			m.textInput.CursorEnd()
+			m.beginRequirement()
 			return m, nil
 		case tea.KeyEsc:
+			m.textInput.EchoMode = textinput.EchoNormal
+			m.inputErr = nil
 			m.state = stateBrowsing
 			return m, nil
 		case tea.KeyCtrlC:
 			m.quitting = true
 			return m, tea.Quit
 		}
+
+		if len(choices) > 0 {
+			switch msg.String() {
+			case "up", "k":
+				if m.choiceIdx > 0 {
+					m.choiceIdx--
+				}
+			case "down", "j":
+				if m.choiceIdx < len(choices)-1 {
+					m.choiceIdx++
+				}
+			case "y":
+				if req.Kind == task.InputConfirm {
+					m.choiceIdx = 0
+				}
+			case "n":
+				if req.Kind == task.InputConfirm {
+					m.choiceIdx = 1
+				}
+			}
+			return m, nil
+		}
 	}
 	var cmd tea.Cmd
 	m.textInput, cmd = m.textInput.Update(msg)
 	return m, cmd
 }
 
+// confirmChoices are the options shown for InputConfirm requirements
+var confirmChoices = []string{"yes", "no"}
+
+// requirementChoices returns the selectable options for choice and confirm
+// requirements, or nil when the answer is typed
+func requirementChoices(req task.InputRequirement) []string {
+	switch req.Kind {
+	case task.InputChoice:
+		return req.Choices
+	case task.InputConfirm:
+		return confirmChoices
+	}
+	return nil
+}
+
+// beginRequirement prepares the modal for the current requirement
+func (m *model) beginRequirement() {
+	req := m.requirements[m.requirementIdx]
+	m.inputErr = nil
+	m.choiceIdx = 0
+	m.textInput.Prompt = req.Prompt + ": "
+	m.textInput.EchoMode = textinput.EchoNormal
+	m.textInput.SetValue(req.Default)
+
+	switch req.Kind {
+	case task.InputSecret:
+		m.textInput.EchoMode = textinput.EchoPassword
+	case task.InputConfirm:
+		if def, err := req.Parse(req.Default); err == nil && def == "false" {
+			m.choiceIdx = 1
+		}
+	case task.InputChoice:
+		for i, c := range req.Choices {
+			if c == req.Default {
+				m.choiceIdx = i
+				break
+			}
+		}
+	}
+	m.textInput.CursorEnd()
+}
+
 func (m model) handleWorkflowNameInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -208,7 +308,7 @@ func (m model) handleWorkflowLocationSelection(msg tea.Msg) (tea.Model, tea.Cmd)
 			if len(workflowSteps) > 0 {
 				workflow := config.Workflow{
 					Name:     name,
-					Commands: workflowSteps,
+					Commands: config.PlainSteps(workflowSteps...),
 				}
 				var err error
 				if m.workflowLocationIdx == 0 {
@@ -318,8 +418,9 @@ func (m model) handleShowingConfig(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		keys := settings.Current().Keybindings
 		switch msg.String() {
-		case "c", "q":
+		case keys.Config, keys.Quit:
 			m.state = stateBrowsing
 			m.focus = m.previousFocus
 			m.updateTaskPreview()
@@ -367,6 +468,7 @@ func (m model) handleEditorFinished(msg editorFinishedMsg) (tea.Model, tea.Cmd)
 			logger.Warn("failed to load workflows after editor", map[string]interface{}{"error": workflowsErr.Error()})
 		}
 		m.tree = buildCommandTree(cfg, m.workflows)
+		m.origins = loadOrigins(cfg)
 		m.updateVisibleItems()
 		m.updateTaskPreview()
 		m.configScrollOffset = 0
@@ -541,29 +643,36 @@ func (m model) handleEnterKey() (tea.Model, tea.Cmd) {
 
 			s := strategy.GetStrategyForCommand(m.selectedCommand, sess)
 			if s != nil {
+				var reqs []task.InputRequirement
+				seen := make(map[string]bool)
+				add := func(r task.InputRequirement) {
+					if !seen[r.Key] {
+						reqs = append(reqs, r)
+						seen[r.Key] = true
+					}
+				}
+				// Parameters come first; tasks that need them may not resolve until they are set
+				params := strategy.Parameters(s, sess)
+				for _, r := range params {
+					add(r)
+				}
+
 				plan, err := s.ResolveTasks(sess)
-				if err != nil {
+				if err != nil && len(params) == 0 {
 					m.fatalError = fmt.Errorf("failed to resolve tasks for %s: %w", m.selectedCommand, err)
 					logger.Error("task resolution failed", err, map[string]interface{}{"command": m.selectedCommand})
 					return m, nil
 				}
-				var reqs []task.InputRequirement
-				seen := make(map[string]bool)
 				for _, t := range plan {
 					for _, r := range t.Requirements(sess) {
-						if !seen[r.Key] {
-							reqs = append(reqs, r)
-							seen[r.Key] = true
-						}
+						add(r)
 					}
 				}
 				if len(reqs) > 0 {
 					m.state = stateInputCollection
 					m.requirements = reqs
 					m.requirementIdx = 0
-					m.textInput.Prompt = reqs[0].Prompt + ": "
-					m.textInput.SetValue(reqs[0].Default)
-					m.textInput.CursorEnd()
+					m.beginRequirement()
 					return m, nil
 				}
 			} else {
@@ -578,6 +687,7 @@ func (m model) handleEnterKey() (tea.Model, tea.Cmd) {
 		entry := m.history[m.historyCursor]
 		m.selectedCommand = entry.Command
 		m.collectedInputs = make(map[string]string)
+		m.secretInputs = make(map[string]bool)
 		for k, v := range entry.Inputs {
 			m.collectedInputs[k] = v
 		}
@@ -609,11 +719,12 @@ func (m model) handleRuneKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	keys := settings.Current().Keybindings
 	switch string(msg.Runes) {
-	case "q":
+	case keys.Quit:
 		m.quitting = true
 		return m, tea.Quit
-	case "/":
+	case keys.Filter:
 		m.filtering = true
 		m.filterText = ""
 		m.updateVisibleItems()
@@ -621,34 +732,50 @@ func (m model) handleRuneKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.scrollOffset = 0
 		m.updateTaskPreview()
 		return m, nil
-	case "?":
+	case keys.Help:
 		m.showHelp = true
-	case "x":
+	case keys.ClearHistory:
 		if m.focus == focusHistory && len(m.history) > 0 {
+			if !settings.Current().Confirm.ClearHistory {
+				m.clearHistory()
+				return m, nil
+			}
 			m.state = stateConfirmClearHistory
 			return m, nil
 		}
-	case "w":
+	case keys.Resume:
+		// Resume the failed workflow run the entry belongs to
+		if m.focus == focusHistory && len(m.history) > 0 {
+			entry := m.history[m.historyCursor]
+			if entry.WorkflowRunID != "" && !entry.Success {
+				m.selectedCommand = "workflow resume:" + entry.WorkflowRunID
+				m.collectedInputs = make(map[string]string)
+				m.secretInputs = make(map[string]bool)
+				m.quitting = true
+				return m, tea.Quit
+			}
+		}
+	case keys.NewWorkflow:
 		if m.focus == focusHistory && len(m.history) > 0 {
 			m.state = stateCreatingWorkflow
 			m.selectedWorkflowIndices = []int{}
 			return m, nil
 		}
-	case "e":
+	case keys.ExpandAll:
 		if m.focus == focusCommands {
 			m.expandAll()
 			m.updateVisibleItems()
 			m.updateTaskPreview()
 			return m, nil
 		}
-	case "c":
+	case keys.Config:
 		m.previousFocus = m.focus
 		m.state = stateShowingConfig
 		m.focus = focusConfig
 		m.configScrollOffset = 0
 		m.updateTaskPreview()
 		return m, nil
-	case "C":
+	case keys.CollapseAll:
 		if m.focus == focusCommands {
 			m.collapseAll()
 			m.updateVisibleItems()
@@ -657,17 +784,21 @@ func (m model) handleRuneKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.updateTaskPreview()
 			return m, nil
 		}
-	case "d":
+	case keys.DeleteWorkflow:
 		if m.focus == focusCommands {
 			if len(m.visibleItems) > 0 {
 				item := m.visibleItems[m.cursor]
 				if strings.HasPrefix(item.item.Command, "workflow:") {
+					if !settings.Current().Confirm.DeleteWorkflow {
+						m.deleteWorkflow()
+						return m, nil
+					}
 					m.state = stateConfirmDeleteWorkflow
 					return m, nil
 				}
 			}
 		}
-	case "t":
+	case keys.Tasks:
 		if m.focus == focusCommands || m.focus == focusHistory {
 			m.previousFocus = m.focus
 			m.focus = focusTasks
@@ -698,23 +829,28 @@ func (m model) handleRuneKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// openEditor creates config if needed and opens it in $EDITOR
+// openEditor creates config if needed and opens it in the editor of the
+// user's settings, or $EDITOR
 func (m model) openEditor() tea.Cmd {
-	// Create default config if it doesn't exist
+	// Create the config from what detection finds, as cleat init does, if it doesn't exist
 	if !m.cfgFound {
-		if err := os.WriteFile(m.cfg.SourcePath, []byte(defaultConfigTemplate), 0644); err != nil {
+		detected, err := config.Detect(filepath.Dir(m.cfg.SourcePath))
+		var data []byte
+		if err == nil {
+			data, err = config.Render(detected, detected, false)
+		}
+		if err == nil {
+			err = os.WriteFile(m.cfg.SourcePath, data, 0644)
+		}
+		if err != nil {
 			// Log the error but continue - the editor will show file creation error
 			fmt.Fprintf(os.Stderr, "Warning: failed to create default config: %v\n", err)
 			logger.Error("failed to create default config", err, map[string]interface{}{"path": m.cfg.SourcePath})
 		}
 	}
 
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "vi" // Fallback
-	}
-
-	c := exec.Command(editor, m.cfg.SourcePath)
+	editor := settings.Current().EditorCommand()
+	c := exec.Command(editor[0], append(editor[1:], m.cfg.SourcePath)...)
 	return tea.ExecProcess(c, func(err error) tea.Msg {
 		return editorFinishedMsg{err}
 	})
@@ -58,6 +58,7 @@ type editorFinishedMsg struct{ err error }
 // model holds all the TUI state
 type model struct {
 	cfg                     *config.Config
+	origins                 map[string]config.Origin
 	exec                    executor.Executor
 	cfgFound                bool
 	quitting                bool
@@ -71,6 +72,7 @@ type model struct {
 	focus                   focus
 	selectedCommand         string
 	collectedInputs         map[string]string
+	secretInputs            map[string]bool
 	taskPreview             []string
 	taskScrollOffset        int
 	history                 []history.HistoryEntry
@@ -85,6 +87,8 @@ type model struct {
 	state                   uiState
 	requirements            []task.InputRequirement
 	requirementIdx          int
+	choiceIdx               int
+	inputErr                error
 	textInput               textinput.Model
 	pendingG                bool
 	workflowLocationIdx     int
@@ -105,6 +109,7 @@ func InitialModel(cfg *config.Config, cfgFound bool, version string, exec execut
 		focus:                   focusCommands,
 		state:                   stateBrowsing,
 		collectedInputs:         make(map[string]string),
+		secretInputs:            make(map[string]bool),
 		selectedWorkflowIndices: []int{},
 		textInput:               ti,
 	}
@@ -120,6 +125,7 @@ func InitialModel(cfg *config.Config, cfgFound bool, version string, exec execut
 	}
 
 	m.tree = buildCommandTree(cfg, m.workflows)
+	m.origins = loadOrigins(cfg)
 
 	// Auto-expand if there's only one item (ignoring recent and workflows)
 	var realItems []*CommandItem
@@ -195,7 +201,8 @@ func (m *model) updateTaskPreview() {
 		}
 
 		if workflow != nil {
-			for _, workflowCmd := range workflow.Commands {
+			for _, step := range workflow.Commands {
+				workflowCmd := strategy.ExpandParams(step.Run, workflow.Params, inputs)
 				sessForCmd := session.NewSession(m.cfg, m.exec)
 				tasks, err := strategy.ResolveCommandTasks(workflowCmd, sessForCmd)
 				if err == nil {
@@ -2,11 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/madewithfuture/cleat/internal/config"
+	"github.com/madewithfuture/cleat/internal/settings"
 )
 
 // View renders the current UI state
@@ -156,12 +159,13 @@ func (m model) renderMainUI() string {
 	combined.WriteString(taskBox)
 
 	// Help line
-	helpText := lipgloss.NewStyle().Foreground(comment).Render("↑/↓: navigate • enter: select/toggle • tab: switch pane • c: config • ?: help • q: quit")
+	keys := settings.Current().Keybindings
+	helpText := lipgloss.NewStyle().Foreground(comment).Render(fmt.Sprintf("↑/↓: navigate • enter: select/toggle • tab: switch pane • %s: config • %s: help • %s: quit", keys.Config, keys.Help, keys.Quit))
 	if m.state == stateCreatingWorkflow {
 		helpText = lipgloss.NewStyle().Foreground(comment).Render("↑/↓: navigate • space/enter: select • c: confirm • esc: cancel")
 	}
 	if m.state == stateShowingConfig {
-		helpText = lipgloss.NewStyle().Foreground(comment).Render("↑/↓: scroll • enter: edit • c/esc/q: close")
+		helpText = lipgloss.NewStyle().Foreground(comment).Render(fmt.Sprintf("↑/↓: scroll • enter: edit • %s/esc/%s: close", keys.Config, keys.Quit))
 	}
 	if !m.cfgFound {
 		warning := lipgloss.NewStyle().Foreground(themeOrange).Render("(no cleat.yaml)")
@@ -350,7 +354,10 @@ func (m model) buildHistoryContent(comment, cyan, green, red, orange lipgloss.Co
 		// Icon for success/failure
 		icon := "✓"
 		iconColor := green
-		if !entry.Success {
+		if entry.Cancelled {
+			icon = "⊘"
+			iconColor = orange
+		} else if !entry.Success {
 			icon = "✘"
 			iconColor = red
 		}
@@ -453,20 +460,27 @@ func (m model) buildConfigLines() []string {
 		configLines = append(configLines, " "+lipgloss.NewStyle().Foreground(themeOrange).Italic(true).Render("No cleat.yaml found"))
 		configLines = append(configLines, "")
 	}
-	configLines = append(configLines, fmt.Sprintf(" version: %d", m.cfg.Version))
-	configLines = append(configLines, fmt.Sprintf(" docker: %v", m.cfg.Docker))
+	if m.cfg.LocalPath != "" {
+		configLines = append(configLines, " "+lipgloss.NewStyle().Foreground(themeCyan).Italic(true).Render("With overrides from "+filepath.Base(m.cfg.LocalPath)))
+		configLines = append(configLines, "")
+	}
+	configLines = append(configLines, m.configLine(fmt.Sprintf(" version: %d", m.cfg.Version), "version"))
+	configLines = append(configLines, m.configLine(fmt.Sprintf(" docker: %v", m.cfg.Docker), "docker"))
 
 	if len(m.cfg.Envs) > 0 {
 		configLines = append(configLines, " envs:")
-		for _, env := range m.cfg.Envs {
-			configLines = append(configLines, fmt.Sprintf("   - %s", env))
+		for i, env := range m.cfg.Envs {
+			configLines = append(configLines, m.configLine(fmt.Sprintf("   - %s", env), fmt.Sprintf("envs[%d]", i)))
 		}
 	}
 
 	if m.cfg.GoogleCloudPlatform != nil {
 		configLines = append(configLines, " google_cloud_platform:")
 		if m.cfg.GoogleCloudPlatform.ProjectName != "" {
-			configLines = append(configLines, fmt.Sprintf("   project_name: %s", m.cfg.GoogleCloudPlatform.ProjectName))
+			configLines = append(configLines, m.configLine(fmt.Sprintf("   project_name: %s", m.cfg.GoogleCloudPlatform.ProjectName), "google_cloud_platform.project_name"))
+		}
+		if m.cfg.GoogleCloudPlatform.Account != "" {
+			configLines = append(configLines, m.configLine(fmt.Sprintf("   account: %s", m.cfg.GoogleCloudPlatform.Account), "google_cloud_platform.account"))
 		}
 	}
 
@@ -474,45 +488,47 @@ func (m model) buildConfigLines() []string {
 		configLines = append(configLines, " terraform:")
 		if len(m.cfg.Terraform.Envs) > 0 {
 			configLines = append(configLines, "   envs:")
-			for _, env := range m.cfg.Terraform.Envs {
-				configLines = append(configLines, fmt.Sprintf("     - %s", env))
+			for i, env := range m.cfg.Terraform.Envs {
+				configLines = append(configLines, m.configLine(fmt.Sprintf("     - %s", env), fmt.Sprintf("terraform.envs[%d]", i)))
 			}
 		}
 	}
 
 	for i := range m.cfg.Services {
 		svc := &m.cfg.Services[i]
-		configLines = append(configLines, fmt.Sprintf(" service: %s", svc.Name))
+		p := fmt.Sprintf("services[%d].", i)
+		configLines = append(configLines, m.configLine(fmt.Sprintf(" service: %s", svc.Name), p+"name"))
 		if svc.Dir != "" {
-			configLines = append(configLines, fmt.Sprintf("   dir: %s", svc.Dir))
+			configLines = append(configLines, m.configLine(fmt.Sprintf("   dir: %s", svc.Dir), p+"dir"))
 		}
 		if svc.IsDocker() {
-			configLines = append(configLines, fmt.Sprintf("   docker: %v", svc.IsDocker()))
+			configLines = append(configLines, m.configLine(fmt.Sprintf("   docker: %v", svc.IsDocker()), p+"docker"))
 		}
 		if svc.Dockerfile != "" {
-			configLines = append(configLines, fmt.Sprintf("   dockerfile: %s", svc.Dockerfile))
+			configLines = append(configLines, m.configLine(fmt.Sprintf("   dockerfile: %s", svc.Dockerfile), p+"dockerfile"))
 		}
 		if svc.Image != "" {
-			configLines = append(configLines, fmt.Sprintf("   image: %s", svc.Image))
+			configLines = append(configLines, m.configLine(fmt.Sprintf("   image: %s", svc.Image), p+"image"))
 		}
 		if svc.Command != "" {
-			configLines = append(configLines, fmt.Sprintf("   command: %s", svc.Command))
+			configLines = append(configLines, m.configLine(fmt.Sprintf("   command: %s", svc.Command), p+"command"))
 		}
 		for j := range svc.Modules {
 			mod := &svc.Modules[j]
+			mp := fmt.Sprintf("%smodules[%d].", p, j)
 			if mod.Python != nil && mod.Python.Django {
 				configLines = append(configLines, "   python:")
-				configLines = append(configLines, fmt.Sprintf("     django: %v", mod.Python.Django))
+				configLines = append(configLines, m.configLine(fmt.Sprintf("     django: %v", mod.Python.Django), mp+"python.django"))
 				if mod.Python.DjangoService != "" {
-					configLines = append(configLines, fmt.Sprintf("     django_service: %s", mod.Python.DjangoService))
+					configLines = append(configLines, m.configLine(fmt.Sprintf("     django_service: %s", mod.Python.DjangoService), mp+"python.django_service"))
 				}
 				if mod.Python.PackageManager != "" {
-					configLines = append(configLines, fmt.Sprintf("     package_manager: %s", mod.Python.PackageManager))
+					configLines = append(configLines, m.configLine(fmt.Sprintf("     package_manager: %s", mod.Python.PackageManager), mp+"python.package_manager"))
 				}
 			}
 			if mod.Npm != nil && len(mod.Npm.Scripts) > 0 {
 				configLines = append(configLines, "   npm:")
-				configLines = append(configLines, fmt.Sprintf("     service: %s", mod.Npm.Service))
+				configLines = append(configLines, m.configLine(fmt.Sprintf("     service: %s", mod.Npm.Service), mp+"npm.service"))
 			}
 		}
 	}
@@ -520,6 +536,24 @@ func (m model) buildConfigLines() []string {
 	return configLines
 }
 
+// configLine adds to a config preview line where the value at path came from
+func (m model) configLine(line, path string) string {
+	o, ok := m.origins[path]
+	if !ok {
+		return line
+	}
+	var origin string
+	switch o.Source {
+	case config.SourceDeclared:
+		origin = fmt.Sprintf("%s:%d", o.File, o.Line)
+	case config.SourceDetected:
+		origin = o.Detector + ": " + o.File
+	default:
+		origin = "default"
+	}
+	return line + "  " + lipgloss.NewStyle().Foreground(themeComment).Render(origin)
+}
+
 // renderInputModal renders the input collection modal
 func (m model) renderInputModal() string {
 	purple := themePurple
@@ -529,17 +563,43 @@ func (m model) renderInputModal() string {
 
 	stepInfo := fmt.Sprintf("Step %d of %d", m.requirementIdx+1, len(m.requirements))
 
+	help := "  Enter: continue • Esc: cancel"
+	var body []string
+	var choices []string
+	if m.requirementIdx < len(m.requirements) {
+		choices = requirementChoices(m.requirements[m.requirementIdx])
+	}
+	if len(choices) > 0 {
+		req := m.requirements[m.requirementIdx]
+		body = append(body, req.Prompt+":")
+		for i, c := range choices {
+			if i == m.choiceIdx {
+				body = append(body, lipgloss.NewStyle().Foreground(themeCyan).Render("> "+c))
+			} else {
+				body = append(body, "  "+c)
+			}
+		}
+		help = "  ↑/↓: choose • Enter: continue • Esc: cancel"
+	} else {
+		body = append(body, m.textInput.View())
+	}
+	if m.inputErr != nil {
+		body = append(body, "", lipgloss.NewStyle().Foreground(themeRed).Render(m.inputErr.Error()))
+	}
+
 	content := []string{
 		"",
 		title,
 		"",
 		stepInfo,
 		"",
-		m.textInput.View(),
+	}
+	content = append(content, body...)
+	content = append(content,
 		"",
-		lipgloss.NewStyle().Foreground(themeComment).Render("  Enter: continue • Esc: cancel"),
+		lipgloss.NewStyle().Foreground(themeComment).Render(help),
 		"",
-	}
+	)
 
 	boxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -704,6 +764,7 @@ func (m model) renderHelpOverlay() string {
 	fg := themeFG
 
 	title := lipgloss.NewStyle().Bold(true).Foreground(purple).Render("Keyboard Shortcuts")
+	keys := settings.Current().Keybindings
 
 	helpItems := []string{
 		"",
@@ -711,19 +772,21 @@ func (m model) renderHelpOverlay() string {
 		"",
 		"  ↑/k        Move up",
 		"  ↓/j        Move down",
-		"  e          Expand all",
-		"  C          Collapse all",
-		"  /          Filter commands",
-		"  c          Show configuration",
+		helpItem(keys.ExpandAll, "Expand all"),
+		helpItem(keys.CollapseAll, "Collapse all"),
+		helpItem(keys.Filter, "Filter commands"),
+		helpItem(keys.Config, "Show configuration"),
 		"  Enter      Select/Toggle / Edit config (in config modal)",
 		"  1-9        Jump to history item",
-		"  t          Jump to task panel",
-		"  x          Clear history (history pane)",
-		"  w          Create workflow from history (history pane)",
+		helpItem(keys.Tasks, "Jump to task panel"),
+		helpItem(keys.ClearHistory, "Clear history (history pane)"),
+		helpItem(keys.NewWorkflow, "Create workflow from history (history pane)"),
+		helpItem(keys.Resume, "Resume failed workflow run (history pane)"),
+		helpItem(keys.DeleteWorkflow, "Delete workflow (commands pane)"),
 		"  Tab        Switch pane",
 		"  Shift+Tab  Switch pane (reverse)",
-		"  q/Esc      Quit",
-		"  ?          Show this help",
+		helpItem(keys.Quit+"/Esc", "Quit"),
+		helpItem(keys.Help, "Show this help"),
 		"",
 		lipgloss.NewStyle().Foreground(comment).Render("  Press any key to close"),
 		"",
@@ -941,3 +1004,8 @@ func wrapLines(args []string, width int, firstPrefix, restPrefix string, style l
 
 	return lines
 }
+
+// helpItem renders a line of the help overlay
+func helpItem(key, description string) string {
+	return fmt.Sprintf("  %-10s %s", key, description)
+}
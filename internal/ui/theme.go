@@ -14,3 +14,16 @@ var (
 	themeFG      = theme.FG
 	themeWhite   = theme.White
 )
+
+// loadTheme copies the theme colors again, since the user's settings are
+// applied to them after this package is initialized
+func loadTheme() {
+	themePurple = theme.Purple
+	themeCyan = theme.Cyan
+	themeComment = theme.Comment
+	themeGreen = theme.Green
+	themeRed = theme.Red
+	themeOrange = theme.Orange
+	themeFG = theme.FG
+	themeWhite = theme.White
+}
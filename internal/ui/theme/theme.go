@@ -1,6 +1,9 @@
 package theme
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/madewithfuture/cleat/internal/settings"
+)
 
 var (
 	// Theme colors using terminal ANSI colors (0-15)
@@ -14,3 +17,18 @@ var (
 	FG      = lipgloss.Color("7")  // White
 	White   = lipgloss.Color("15") // Bright White
 )
+
+// Apply replaces the colors that the user's theme settings set
+func Apply(t settings.Theme) {
+	for _, c := range []struct {
+		color *lipgloss.Color
+		value string
+	}{
+		{&Purple, t.Purple}, {&Cyan, t.Cyan}, {&Comment, t.Comment}, {&Green, t.Green},
+		{&Red, t.Red}, {&Orange, t.Orange}, {&FG, t.FG}, {&White, t.White},
+	} {
+		if c.value != "" {
+			*c.color = lipgloss.Color(c.value)
+		}
+	}
+}
@@ -6,29 +6,9 @@ import (
 
 	"github.com/madewithfuture/cleat/internal/config"
 	"github.com/madewithfuture/cleat/internal/history"
+	"github.com/madewithfuture/cleat/internal/task"
 )
 
-const defaultConfigTemplate = `# Cleat configuration
-# See https://github.com/madewithfuture/cleat for documentation
-
-version: 1
-docker: true
-services:
-  - name: api
-    dir: .
-    modules:
-      - python:
-          django: true
-          django_service: api
-  - name: web
-    dir: ./web
-    modules:
-      - npm:
-          service: web
-          scripts:
-            - build
-`
-
 // buildCommandTree creates the commands tree from config and workflows
 func buildCommandTree(cfg *config.Config, workflows []config.Workflow) []CommandItem {
 	var tree []CommandItem
@@ -49,6 +29,18 @@ func buildCommandTree(cfg *config.Config, workflows []config.Workflow) []Command
 
 	isFlattened := len(cfg.Services) == 1 && (cfg.Services[0].Name == "default" || cfg.Services[0].Name == "")
 
+	// A lone default service is flattened, so its commands join the top-level ones
+	commandChildren := commandItems(nil, cfg.Commands)
+	if isFlattened {
+		commandChildren = append(commandChildren, commandItems(&cfg.Services[0], cfg.Services[0].Commands)...)
+	}
+	if len(commandChildren) > 0 {
+		tree = append(tree, CommandItem{
+			Label:    "commands",
+			Children: commandChildren,
+		})
+	}
+
 	hasDocker := cfg.Docker
 	if !hasDocker {
 		for i := range cfg.Services {
@@ -290,6 +282,13 @@ func buildCommandTree(cfg *config.Config, workflows []config.Workflow) []Command
 			})
 		}
 
+		if len(svc.Commands) > 0 && !isFlattened {
+			svcItem.Children = append(svcItem.Children, CommandItem{
+				Label:    "commands",
+				Children: commandItems(svc, svc.Commands),
+			})
+		}
+
 		if svc.AppYaml != "" {
 			svcItem.Children = append(svcItem.Children, CommandItem{Label: "deploy", Command: fmt.Sprintf("gcp app-engine deploy:%s", svc.Name)})
 			svcItem.Children = append(svcItem.Children, CommandItem{Label: "promote", Command: fmt.Sprintf("gcp app-engine promote:%s", svc.Name)})
@@ -337,6 +336,18 @@ func buildCommandTree(cfg *config.Config, workflows []config.Workflow) []Command
 	return tree
 }
 
+// commandItems lists user-defined commands of svc, or top-level ones when svc is nil
+func commandItems(svc *config.ServiceConfig, cmds []config.CommandConfig) []CommandItem {
+	var items []CommandItem
+	for i := range cmds {
+		items = append(items, CommandItem{
+			Label:   cmds[i].Name,
+			Command: task.CommandName(svc, &cmds[i]),
+		})
+	}
+	return items
+}
+
 func collectCommands(items []CommandItem, commands map[string]bool) {
 	for i := range items {
 		if items[i].Command != "" {
@@ -128,3 +128,62 @@ func TestBuildCommandTree_Ruby(t *testing.T) {
 		t.Error("Should have 'migrate' command in ruby node")
 	}
 }
+
+func TestBuildCommandTree_UserCommands(t *testing.T) {
+	findChild := func(items []CommandItem, label string) *CommandItem {
+		for i := range items {
+			if items[i].Label == label {
+				return &items[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("TopLevelAndService", func(t *testing.T) {
+		cfg := &config.Config{
+			Commands: []config.CommandConfig{{Name: "lint"}},
+			Services: []config.ServiceConfig{
+				{Name: "web", Commands: []config.CommandConfig{{Name: "seed"}}},
+				{Name: "api"},
+			},
+		}
+		tree := buildCommandTree(cfg, nil)
+
+		top := findChild(tree, "commands")
+		if top == nil || len(top.Children) != 1 || top.Children[0].Command != "command:lint" {
+			t.Fatalf("expected top-level commands node with lint, got %+v", top)
+		}
+		web := findChild(tree, "web")
+		if web == nil {
+			t.Fatal("expected web service node")
+		}
+		svcCmds := findChild(web.Children, "commands")
+		if svcCmds == nil || svcCmds.Children[0].Command != "command:web:seed" {
+			t.Errorf("expected web commands node with seed, got %+v", svcCmds)
+		}
+	})
+
+	t.Run("FlattenedDefaultService", func(t *testing.T) {
+		cfg := &config.Config{
+			Commands: []config.CommandConfig{{Name: "lint"}},
+			Services: []config.ServiceConfig{
+				{Name: "default", Commands: []config.CommandConfig{{Name: "seed"}}},
+			},
+		}
+		tree := buildCommandTree(cfg, nil)
+
+		count := 0
+		for _, item := range tree {
+			if item.Label == "commands" {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Fatalf("expected a single commands node, found %d", count)
+		}
+		top := findChild(tree, "commands")
+		if len(top.Children) != 2 || top.Children[1].Command != "command:default:seed" {
+			t.Errorf("expected service commands merged into top-level node, got %+v", top.Children)
+		}
+	})
+}
@@ -17,8 +17,10 @@ var runnerFactory = func(m tea.Model) programRunner {
 	return tea.NewProgram(m, tea.WithAltScreen())
 }
 
-// Start launches the TUI and returns the selected command and collected inputs
-func Start(version string, configPath string) (string, map[string]string, error) {
+// Start launches the TUI and returns the selected command, the collected
+// inputs and which of them were answered as secrets
+func Start(version string, configPath string) (string, map[string]string, map[string]bool, error) {
+	loadTheme()
 	cfg, err := config.LoadConfigWithDefault(configPath)
 	cfgFound := true
 	var initialErr error
@@ -46,12 +48,30 @@ func Start(version string, configPath string) (string, map[string]string, error)
 	p := runnerFactory(m)
 	finalModel, err := p.Run()
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 
 	if fm, ok := finalModel.(model); ok {
-		return fm.selectedCommand, fm.collectedInputs, nil
+		return fm.selectedCommand, fm.collectedInputs, fm.secretInputs, nil
 	}
 
-	return "", nil, nil
+	return "", nil, nil, nil
+}
+
+// loadOrigins returns where each field of the config came from, for the
+// config pane, keyed by path such as services[0].dir
+func loadOrigins(cfg *config.Config) map[string]config.Origin {
+	if cfg == nil || cfg.SourcePath == "" {
+		return nil
+	}
+	_, fields, err := config.LoadWithOrigins(cfg.SourcePath, true)
+	if err != nil {
+		logger.Debug("failed to load config origins", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	origins := make(map[string]config.Origin, len(fields))
+	for _, f := range fields {
+		origins[f.Path] = f.Origin
+	}
+	return origins
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/madewithfuture/cleat/internal/config"
 	"github.com/madewithfuture/cleat/internal/executor"
 	"github.com/madewithfuture/cleat/internal/history"
+	"github.com/madewithfuture/cleat/internal/settings"
 	"github.com/madewithfuture/cleat/internal/task"
 	"github.com/muesli/termenv"
 )
@@ -226,6 +227,45 @@ func TestConfirmClearHistory(t *testing.T) {
 	}
 }
 
+func TestSettingsKeys(t *testing.T) {
+	s := settings.Default()
+	s.Keybindings.Config = "o"
+	s.Keybindings.ClearHistory = "X"
+	s.Confirm.ClearHistory = false
+	settings.Set(s)
+	defer settings.Set(nil)
+
+	m := InitialModel(&config.Config{}, true, "0.1.0", &executor.ShellExecutor{})
+	m.width = 100
+	m.height = 40
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if updatedModel.(model).state == stateShowingConfig {
+		t.Error("expected 'c' to do nothing once config is bound to 'o'")
+	}
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = updatedModel.(model)
+	if m.state != stateShowingConfig {
+		t.Fatal("expected 'o' to show the config")
+	}
+	if view := m.View(); !strings.Contains(view, "o/esc/q: close") {
+		t.Errorf("expected the help line to show the bound key, got:\n%s", view)
+	}
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = updatedModel.(model)
+	if m.state != stateBrowsing {
+		t.Fatal("expected 'o' to close the config")
+	}
+
+	m.focus = focusHistory
+	m.history = []history.HistoryEntry{{Command: "build", Timestamp: time.Now()}}
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("X")})
+	m = updatedModel.(model)
+	if m.state != stateBrowsing || len(m.history) != 0 {
+		t.Errorf("expected history cleared without asking, got state %v and %d entries", m.state, len(m.history))
+	}
+}
+
 func TestWorkflowCreationFlow(t *testing.T) {
 	m := InitialModel(&config.Config{}, true, "0.1.0", &executor.ShellExecutor{})
 	m.history = []history.HistoryEntry{{Command: "build", Timestamp: time.Now()}}
@@ -452,6 +492,31 @@ func TestNavigationKeys(t *testing.T) {
 	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
 }
 
+func TestResumeWorkflowKey(t *testing.T) {
+	m := InitialModel(&config.Config{}, true, "0.1.0", &executor.ShellExecutor{})
+	m.focus = focusHistory
+	m.history = []history.HistoryEntry{
+		{Command: "workflow:deploy", WorkflowRunID: "run-1", Success: true},
+		{Command: "echo push", WorkflowRunID: "run-2", Step: 2},
+		{Command: "build"},
+	}
+
+	for _, cursor := range []int{0, 2} {
+		m.historyCursor = cursor
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+		if updated.(model).quitting {
+			t.Errorf("expected entry %d not to be resumable", cursor)
+		}
+	}
+
+	m.historyCursor = 1
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = updated.(model)
+	if !m.quitting || m.selectedCommand != "workflow resume:run-2" {
+		t.Errorf("expected failed step entry to resume its run, got %q", m.selectedCommand)
+	}
+}
+
 func TestEditorFinished(t *testing.T) {
 	tmpDir, _ := os.MkdirTemp("", "cleat-ui-editor-*")
 	defer os.RemoveAll(tmpDir)
@@ -598,6 +663,20 @@ func TestBuildConfigLines(t *testing.T) {
 	}
 	m := InitialModel(cfg, true, "0.1.0", &executor.ShellExecutor{})
 	m.buildConfigLines()
+
+	m.origins = map[string]config.Origin{
+		"version":                              {Source: config.SourceDefault},
+		"services[0].name":                     {Source: config.SourceDeclared, File: "cleat.yaml", Line: 3},
+		"services[0].modules[0].python.django": {Source: config.SourceDetected, File: "manage.py", Detector: "django"},
+	}
+	lines := strings.Join(m.buildConfigLines(), "\n")
+	m.cfg.LocalPath = "/project/cleat.local.yaml"
+	lines = strings.Join(m.buildConfigLines(), "\n")
+	for _, want := range []string{"With overrides from cleat.local.yaml", "default", "cleat.yaml:3", "django: manage.py"} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("expected %q in config lines:\n%s", want, lines)
+		}
+	}
 }
 
 func TestOpenEditor(t *testing.T) {
@@ -681,6 +760,84 @@ func TestHandleInputCollection_Advanced(t *testing.T) {
 	}
 }
 
+func TestHandleInputCollection_Typed(t *testing.T) {
+	m := InitialModel(&config.Config{}, true, "0.1.0", &executor.ShellExecutor{})
+	m.width = 100
+	m.height = 40
+	m.state = stateInputCollection
+	m.requirements = []task.InputRequirement{
+		{Key: "env", Prompt: "Environment", Kind: task.InputChoice, Choices: []string{"dev", "prod"}},
+		{Key: "deploy", Prompt: "Deploy?", Kind: task.InputConfirm, Default: "no"},
+		{Key: "version", Prompt: "Version", Pattern: "[a-z0-9-]+"},
+	}
+	m.requirementIdx = 0
+	m.collectedInputs = make(map[string]string)
+	m.beginRequirement()
+
+	if !strings.Contains(m.View(), "> dev") {
+		t.Error("expected first choice to be highlighted")
+	}
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = updatedModel.(model)
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+	if m.collectedInputs["env"] != "prod" {
+		t.Errorf("expected env=prod, got %q", m.collectedInputs["env"])
+	}
+
+	if m.choiceIdx != 1 {
+		t.Errorf("expected confirm to start on its default 'no', got index %d", m.choiceIdx)
+	}
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = updatedModel.(model)
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+	if m.collectedInputs["deploy"] != "true" {
+		t.Errorf("expected deploy=true, got %q", m.collectedInputs["deploy"])
+	}
+
+	m.textInput.SetValue("Bad Version")
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+	if m.inputErr == nil || m.requirementIdx != 2 {
+		t.Fatal("expected invalid answer to keep the prompt open with an error")
+	}
+	if !strings.Contains(m.View(), "does not match") {
+		t.Error("expected validation error to be rendered")
+	}
+
+	m.textInput.SetValue("v2")
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+	if m.collectedInputs["version"] != "v2" || !m.quitting {
+		t.Errorf("expected version=v2 and quitting, got %q (quitting=%v)", m.collectedInputs["version"], m.quitting)
+	}
+}
+
+func TestHandleInputCollection_MarksSecrets(t *testing.T) {
+	m := InitialModel(&config.Config{}, true, "0.1.0", &executor.ShellExecutor{})
+	m.state = stateInputCollection
+	m.requirements = []task.InputRequirement{
+		{Key: "user", Prompt: "User"},
+		{Key: "token", Prompt: "Token", Kind: task.InputSecret},
+	}
+	m.beginRequirement()
+
+	for _, answer := range []string{"alice", "s3cret"} {
+		m.textInput.SetValue(answer)
+		updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = updatedModel.(model)
+	}
+
+	if m.collectedInputs["token"] != "s3cret" {
+		t.Errorf("expected token to be collected, got %q", m.collectedInputs["token"])
+	}
+	if !m.secretInputs["token"] || m.secretInputs["user"] {
+		t.Errorf("expected only token to be marked secret, got %v", m.secretInputs)
+	}
+}
+
 func TestHandleShowingConfig_Advanced(t *testing.T) {
 	m := InitialModel(&config.Config{}, true, "0.1.0", &executor.ShellExecutor{})
 	m.state = stateShowingConfig
@@ -780,7 +937,7 @@ func TestStart(t *testing.T) {
 			mod.selectedCommand = "ls"
 			return &mockRunner{m: mod}
 		}
-		cmd, _, err := Start("0.1.0", configPath)
+		cmd, _, _, err := Start("0.1.0", configPath)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -1002,3 +1159,29 @@ func TestNavigationHandlers(t *testing.T) {
 	m.handleDownKey()
 	// handleDownKey for config depends on buildConfigLines length
 }
+
+func TestHandleEnterKey_WorkflowParams(t *testing.T) {
+	m := InitialModel(&config.Config{}, true, "0.1.0", &executor.ShellExecutor{})
+	m.workflows = []config.Workflow{
+		{
+			Name:     "deploy",
+			Params:   []config.WorkflowParam{{Name: "env", Choices: []string{"staging", "prod"}}},
+			Commands: config.PlainSteps("terraform apply:${env}"),
+		},
+	}
+	m.focus = focusCommands
+	m.visibleItems = []visibleItem{{item: &CommandItem{Label: "deploy", Command: "workflow:deploy"}}}
+	m.cursor = 0
+
+	updated, _ := m.handleEnterKey()
+	m = updated.(model)
+	if m.fatalError != nil {
+		t.Fatalf("unexpected error: %v", m.fatalError)
+	}
+	if m.state != stateInputCollection || len(m.requirements) == 0 || m.requirements[0].Key != "env" {
+		t.Fatalf("expected the env parameter to be prompted for first, got %+v", m.requirements)
+	}
+	if m.requirements[0].Kind != task.InputChoice {
+		t.Errorf("expected env to be a choice, got %v", m.requirements[0].Kind)
+	}
+}
@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -78,12 +79,12 @@ type ExecutedCommand struct {
 	Dir  string
 }
 
-func (m *MockExecutor) Run(name string, args ...string) error {
+func (m *MockExecutor) Run(ctx context.Context, name string, args ...string) error {
 	m.Commands = append(m.Commands, ExecutedCommand{Name: name, Args: args})
 	return m.Error
 }
 
-func (m *MockExecutor) RunWithDir(dir string, name string, args ...string) error {
+func (m *MockExecutor) RunWithDir(ctx context.Context, dir string, name string, args ...string) error {
 	m.Commands = append(m.Commands, ExecutedCommand{Name: name, Args: args, Dir: dir})
 	return m.Error
 }